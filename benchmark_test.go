@@ -616,3 +616,86 @@ func BenchmarkGetUserRolesAllocs(b *testing.B) {
 		_, _ = service.GetUserRoles(ctx, userID)
 	}
 }
+
+// ============================================================================
+// In-Memory Permission Checking Benchmarks
+// ============================================================================
+
+// newBenchmarkChecker builds a checker for a user holding a role in each of
+// n projects, plus an organization-wide role, against a registry with many
+// patterns per role.
+func newBenchmarkChecker(b *testing.B, n int) *Checker {
+	registry := NewRegistry()
+	project := registry.DefineScope("project")
+	for i := 0; i < 10; i++ {
+		role := project.Role(fmt.Sprintf("role%d", i))
+		for j := 0; j < 20; j++ {
+			role.Permissions(fmt.Sprintf("resource%d.action%d", j, i), fmt.Sprintf("resource%d_%d.*", i, j))
+		}
+		role.Permissions("*.read")
+	}
+	if err := registry.Freeze(); err != nil {
+		b.Fatalf("Failed to freeze registry: %v", err)
+	}
+
+	assignments := make([]RoleAssignment, 0, n+1)
+	for i := 0; i < n; i++ {
+		assignments = append(assignments, RoleAssignment{
+			UserID: "user1", Role: fmt.Sprintf("role%d", i%10), ScopeType: "project", ScopeID: fmt.Sprintf("proj%d", i),
+		})
+	}
+	assignments = append(assignments, RoleAssignment{UserID: "user1", Role: "role9", ScopeType: "project", ScopeID: "*"})
+	return NewChecker("user1", NewUserRoles("user1", assignments), registry, nil)
+}
+
+// BenchmarkCheckerHasPermission compares compiled checks with matching the
+// role permissions rebuilt by GetPermissions, for a user with 500 assignments
+func BenchmarkCheckerHasPermission(b *testing.B) {
+	checker := newBenchmarkChecker(b, 500)
+
+	b.Run("GetPermissionsMatchAny", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			perms := checker.GetPermissions("project", "proj250")
+			_ = MatchAnyPermission(perms, "resource19.action0")
+		}
+	})
+
+	b.Run("HasPermission", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = checker.HasPermission("resource19.action0", "project", "proj250")
+		}
+	})
+
+	b.Run("HasPermissionDenied", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = checker.HasPermission("settings.write", "project", "proj250")
+		}
+	})
+}
+
+// BenchmarkPermissionMatch compares matching pattern by pattern with a compiled pattern set
+func BenchmarkPermissionMatch(b *testing.B) {
+	patterns := make([]string, 0, 101)
+	for i := 0; i < 50; i++ {
+		patterns = append(patterns, fmt.Sprintf("resource%d.action", i), fmt.Sprintf("resource%d.sub.*", i))
+	}
+	patterns = append(patterns, "*.read")
+	compiled := CompilePermissions(patterns)
+
+	b.Run("MatchAny", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = MatchAnyPermission(patterns, "resource49.sub.write")
+		}
+	})
+
+	b.Run("Compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = compiled.Match("resource49.sub.write")
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package rolekit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// PERMISSION CATALOG
+// ============================================================================
+
+// PermissionInfo describes a catalogued permission.
+type PermissionInfo struct {
+	Name        string
+	Description string
+}
+
+// DefinePermission adds a permission to the registry's catalog. Once the
+// catalog has entries, Validate reports role patterns that match none of them,
+// and StrictPermissions can deny checks of permissions outside the catalog.
+// Catalogued names must be concrete permissions, without wildcards.
+//
+// Example:
+//
+//	registry.
+//	    DefinePermission("files.upload", "Upload files").
+//	    DefinePermission("files.read", "Download and preview files")
+func (r *Registry) DefinePermission(name, description string) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := DefaultMatcher.Validate(name); err != nil {
+		r.definitionErrors = append(r.definitionErrors, fmt.Errorf("catalog: %q: %w", name, err))
+		return r
+	}
+	if strings.Contains(name, "*") {
+		r.definitionErrors = append(r.definitionErrors,
+			fmt.Errorf("catalog: %q: %w", name, NewError(ErrInvalidPermission, "catalogued permissions cannot contain wildcards")))
+		return r
+	}
+	r.permissions[name] = description
+	return r
+}
+
+// StrictPermissions denies every check of a permission missing from the
+// catalog, even for roles holding "*", so that typos in handlers surface
+// instead of silently denying or granting. onUnknown, if not nil, is called
+// with each denied permission, e.g. to log it. CheckPermission reports these
+// checks as ErrUnknownPermission.
+//
+// Example:
+//
+//	registry.StrictPermissions(func(permission string) {
+//	    log.Printf("rolekit: check of uncatalogued permission %q", permission)
+//	})
+func (r *Registry) StrictPermissions(onUnknown func(permission string)) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.strict = true
+	r.onUnknown = onUnknown
+	return r
+}
+
+// IsCatalogued reports whether a permission is in the catalog.
+func (r *Registry) IsCatalogued(permission string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.permissions[permission]
+	return ok
+}
+
+// GetPermissionInfo returns a catalogued permission and whether it exists.
+func (r *Registry) GetPermissionInfo(permission string) (PermissionInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	description, ok := r.permissions[permission]
+	return PermissionInfo{Name: permission, Description: description}, ok
+}
+
+// GetCatalog returns every catalogued permission, sorted by name.
+func (r *Registry) GetCatalog() []PermissionInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]PermissionInfo, 0, len(r.permissions))
+	for _, name := range sortedStrings(mapKeys(r.permissions)) {
+		result = append(result, PermissionInfo{Name: name, Description: r.permissions[name]})
+	}
+	return result
+}
+
+// ExpandPermissions returns the catalogued permissions matched by any of the
+// patterns, sorted by name.
+//
+// Example:
+//
+//	registry.ExpandPermissions("files.*")
+//	// ["files.read", "files.upload"]
+func (r *Registry) ExpandPermissions(patterns ...string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	expanded := DefaultMatcher.ExpandPermissions(patterns, mapKeys(r.permissions))
+	return sortedStrings(expanded)
+}
+
+// ExpandPermissions returns the catalogued permissions the user holds in a
+// scope through their roles, sorted by name. Conditional permissions are left
+// out; check them with HasPermissionWith.
+//
+// Example:
+//
+//	perms := checker.ExpandPermissions("project", projectID)
+//	// perms might be ["files.read", "files.upload"]
+func (c *Checker) ExpandPermissions(scopeType, scopeID string) []string {
+	patterns := c.unconditionalPermissions(scopeType, scopeID)
+	if len(patterns) == 0 {
+		return nil
+	}
+	return c.registry.ExpandPermissions(patterns...)
+}
+
+// CheckPermission checks a permission like HasPermission, but reports checks
+// of uncatalogued permissions in strict mode as ErrUnknownPermission instead
+// of only denying them.
+//
+// Example:
+//
+//	ok, err := checker.CheckPermission("files.upload", "project", projectID)
+//	if rolekit.IsUnknownPermission(err) {
+//	    // Typo in the permission name
+//	}
+func (c *Checker) CheckPermission(permission, scopeType, scopeID string) (bool, error) {
+	if c.registry.rejectsPermission(permission) {
+		return false, NewError(ErrUnknownPermission, "permission "+permission+" is not in the catalog").
+			WithScope(scopeType, scopeID).
+			WithUser(c.userID)
+	}
+	return c.HasPermission(permission, scopeType, scopeID), nil
+}
+
+// rejectsPermission reports whether strict mode denies checks of a permission.
+func (r *Registry) rejectsPermission(permission string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.strict {
+		return false
+	}
+	_, ok := r.permissions[permission]
+	return !ok
+}
+
+// checkCatalogued applies strict mode to a permission check, reporting denied
+// permissions to the onUnknown callback.
+func (r *Registry) checkCatalogued(permission string) bool {
+	if !r.rejectsPermission(permission) {
+		return true
+	}
+	r.mu.RLock()
+	onUnknown := r.onUnknown
+	r.mu.RUnlock()
+	if onUnknown != nil {
+		onUnknown(permission)
+	}
+	return false
+}
+
+// danglingPatterns returns an error for each role pattern that matches no
+// catalogued permission. It returns nothing while the catalog is empty.
+// Callers must hold r.mu.
+func (r *Registry) danglingPatterns() []error {
+	if len(r.permissions) == 0 {
+		return nil
+	}
+
+	catalog := mapKeys(r.permissions)
+	var errs []error
+	for _, scopeName := range sortedStrings(mapKeys(r.scopes)) {
+		scope := r.scopes[scopeName]
+		for _, roleName := range sortedStrings(mapKeys(scope.roles)) {
+			for _, pattern := range scope.roles[roleName].permissions {
+				if len(DefaultMatcher.ExpandPermissions([]string{pattern}, catalog)) == 0 {
+					errs = append(errs, fmt.Errorf("%s/%s: %w: %q matches no catalogued permission",
+						scopeName, roleName, ErrUnknownPermission, pattern))
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,98 @@
+package rolekit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCatalogTestRegistry defines a small catalog and roles using it
+func newCatalogTestRegistry() *Registry {
+	r := NewRegistry()
+	r.DefinePermission("files.upload", "Upload files").
+		DefinePermission("files.read", "Download and preview files").
+		DefinePermission("files.versions.restore", "Restore an earlier version").
+		DefinePermission("members.read", "List members")
+	r.DefineScope("project").
+		Role("owner").Permissions("*").
+		Role("editor").Permissions("files.*", "members.read").
+		Role("viewer").Permissions("*.read")
+	return r
+}
+
+// TestRegistryPermissionCatalog tests defining and reading the catalog
+func TestRegistryPermissionCatalog(t *testing.T) {
+	r := newCatalogTestRegistry()
+	require.NoError(t, r.Validate())
+
+	assert.True(t, r.IsCatalogued("files.upload"))
+	assert.False(t, r.IsCatalogued("file.upload"))
+
+	info, ok := r.GetPermissionInfo("files.upload")
+	assert.True(t, ok)
+	assert.Equal(t, "Upload files", info.Description)
+
+	catalog := r.GetCatalog()
+	require.Len(t, catalog, 4)
+	assert.Equal(t, "files.read", catalog[0].Name)
+
+	assert.Equal(t, []string{"files.read", "files.upload"}, r.ExpandPermissions("files.*"))
+	assert.Equal(t, []string{"files.read", "files.upload", "files.versions.restore"}, r.ExpandPermissions("files.**"))
+	assert.Equal(t, []string{"files.read", "members.read"}, r.ExpandPermissions("*.read"))
+	assert.Empty(t, r.ExpandPermissions("settings.*"))
+}
+
+// TestRegistryPermissionCatalogValidation tests reporting invalid and dangling definitions
+func TestRegistryPermissionCatalogValidation(t *testing.T) {
+	r := newCatalogTestRegistry()
+	r.DefinePermission("files.*", "Wildcards are not permissions").
+		DefinePermission("files", "Missing action")
+	r.GetScope("project").Role("typo").Permissions("file.upload", "files.read")
+
+	err := r.Validate()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidPermission)
+	assert.True(t, IsUnknownPermission(err))
+	assert.Contains(t, err.Error(), `project/typo`)
+	assert.Contains(t, err.Error(), `"file.upload"`)
+	assert.NotContains(t, err.Error(), `"files.read" matches`)
+	assert.False(t, r.IsCatalogued("files.*"))
+
+	// Without a catalog, role patterns are not checked
+	empty := NewRegistry()
+	empty.DefineScope("project").Role("typo").Permissions("file.upload")
+	assert.NoError(t, empty.Validate())
+}
+
+// TestCheckerStrictPermissions tests denying and reporting uncatalogued permission checks
+func TestCheckerStrictPermissions(t *testing.T) {
+	r := newCatalogTestRegistry()
+	roles := NewUserRoles("user1", []RoleAssignment{
+		{UserID: "user1", Role: "owner", ScopeType: "project", ScopeID: "proj1"},
+		{UserID: "user1", Role: "editor", ScopeType: "project", ScopeID: "proj2"},
+	})
+	checker := NewChecker("user1", roles, r, nil)
+
+	// Lenient by default
+	assert.True(t, checker.HasPermission("file.upload", "project", "proj1"))
+
+	var unknown []string
+	r.StrictPermissions(func(permission string) { unknown = append(unknown, permission) })
+
+	assert.True(t, checker.HasPermission("files.upload", "project", "proj1"))
+	assert.False(t, checker.HasPermission("file.upload", "project", "proj1"))
+	assert.Equal(t, []string{"file.upload"}, unknown)
+
+	ok, err := checker.CheckPermission("file.upload", "project", "proj1")
+	assert.False(t, ok)
+	assert.True(t, IsUnknownPermission(err))
+
+	ok, err = checker.CheckPermission("files.upload", "project", "proj2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, []string{"files.read", "files.upload", "members.read"}, checker.ExpandPermissions("project", "proj2"))
+	assert.Len(t, checker.ExpandPermissions("project", "proj1"), 4)
+	assert.Nil(t, checker.ExpandPermissions("project", "proj3"))
+}
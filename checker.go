@@ -1,5 +1,10 @@
 package rolekit
 
+import (
+	"context"
+	"time"
+)
+
 // Checker provides permission checking capabilities for a specific user.
 // It is typically created by the Service and stored in context for use in handlers.
 type Checker struct {
@@ -7,6 +12,13 @@ type Checker struct {
 	roles    *UserRoles
 	registry *Registry
 	service  *Service
+	request  Attributes // Request attributes for conditions, see Service.GetChecker
+
+	// Delegations to this user, reduced to the permissions the delegator still holds
+	delegations []Delegation
+
+	// Set when another user is viewing as this user (see Service.Impersonate)
+	impersonation *Impersonation
 }
 
 // NewChecker creates a new Checker for a user.
@@ -19,6 +31,19 @@ func NewChecker(userID string, roles *UserRoles, registry *Registry, service *Se
 	}
 }
 
+// newChecker creates a Checker that evaluates conditions with the request
+// attributes of ctx (see WithAuditContext). When ctx carries an impersonation
+// session for the user, the checker is bounded by it like the one returned by
+// Impersonate.
+func (s *Service) newChecker(ctx context.Context, userID string, roles *UserRoles) *Checker {
+	checker := NewChecker(userID, roles, s.registry, s)
+	checker.request = requestAttributes(ctx)
+	if imp := GetImpersonation(ctx); imp != nil && imp.SubjectID == userID {
+		checker.impersonation = imp
+	}
+	return checker
+}
+
 // UserID returns the user ID this checker is for.
 func (c *Checker) UserID() string {
 	return c.userID
@@ -69,6 +94,8 @@ func (c *Checker) HasAllRoles(roles []string, scopeType, scopeID string) bool {
 
 // HasPermission checks if the user has a specific permission in a scope.
 // This resolves the user's roles to their permissions and checks for a match.
+// Conditional permissions (see RoleDefinition.When) are evaluated against the
+// attributes of the checker's context only.
 //
 // Example:
 //
@@ -76,17 +103,104 @@ func (c *Checker) HasAllRoles(roles []string, scopeType, scopeID string) bool {
 //	    // User can upload files to this project
 //	}
 func (c *Checker) HasPermission(permission, scopeType, scopeID string) bool {
-	// Get all roles for this scope
-	roles := c.roles.GetRoles(scopeType, scopeID)
-	if len(roles) == 0 {
+	return c.HasPermissionWith(permission, scopeType, scopeID, nil)
+}
+
+// HasPermissionWith checks a permission like HasPermission, evaluating the
+// conditions attached to the user's roles against attrs. The time, and the
+// request IP, user agent and ID of the context the checker was created with
+// (see Service.GetChecker and AttrRequestIP), are added unless attrs sets them.
+//
+// Example:
+//
+//	if checker.HasPermissionWith("invoices.approve", "organization", orgID,
+//	    rolekit.Attributes{"amount": invoice.Amount}) {
+//	    // Approved through a role whose condition holds for this invoice
+//	}
+func (c *Checker) HasPermissionWith(permission, scopeType, scopeID string, attrs Attributes) bool {
+	if !c.registry.checkCatalogued(permission) {
+		return false
+	}
+	if c.impersonation != nil && !c.impersonation.permits(permission, scopeType, scopeID) {
 		return false
 	}
 
-	// Get all permissions from all roles (UNION)
-	permissions := c.GetPermissions(scopeType, scopeID)
+	// Permissions from all roles (UNION), matched against each role's compiled
+	// patterns; attributes are only resolved for conditional roles
+	var resolved Attributes
+	exact, wildcard := c.roles.rolesIn(scopeType, scopeID)
+	for _, roles := range [2][]string{exact, wildcard} {
+		for _, role := range roles {
+			roleDef := c.registry.GetRole(role, scopeType)
+			if roleDef == nil {
+				continue
+			}
+			if roleDef.HasConditions() && resolved == nil {
+				resolved = conditionAttributes(c.userID, c.request, attrs)
+			}
+			if roleDef.grants(permission, resolved) {
+				return true
+			}
+		}
+	}
+
+	return c.hasDelegatedPermission(permission, scopeType, scopeID)
+}
+
+// hasDelegatedPermission checks the delegations the user currently benefits from.
+func (c *Checker) hasDelegatedPermission(permission, scopeType, scopeID string) bool {
+	now := time.Now()
+	for _, d := range c.delegations {
+		if d.ScopeType != scopeType || (d.ScopeID != scopeID && d.ScopeID != "*") {
+			continue
+		}
+		if d.IsActive(now) && MatchAnyPermission(d.Permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanOnResource checks a permission on a single resource. It is granted by the
+// user's resource grant (see Service.GrantResource) or by their roles and
+// delegations in the scope containing the resource (see
+// Service.SetResourceScope). The grant and the resource's scope are read with
+// ctx; checkers without a database always return false.
+//
+// Example:
+//
+//	ok, err := checker.CanOnResource(ctx, "documents.read", "document", docID)
+//	if err != nil {
+//	    return err
+//	}
+//	if ok {
+//	    // Shared with the user, or readable through their project role
+//	}
+func (c *Checker) CanOnResource(ctx context.Context, permission, resourceType, resourceID string) (bool, error) {
+	if !c.registry.checkCatalogued(permission) {
+		return false, nil
+	}
+	if !c.canQuery(ctx) {
+		return false, nil
+	}
+
+	scope, granted, err := c.service.resourceAccess(ctx, c.userID, resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+	if scope == nil {
+		// Impersonation is bounded by a scope; without one only regular checkers use grants
+		return c.impersonation == nil && MatchAnyPermission(granted, permission), nil
+	}
+	if c.impersonation != nil && !c.impersonation.permits(permission, scope.Type, scope.ID) {
+		return false, nil
+	}
+	return MatchAnyPermission(granted, permission) || c.HasPermission(permission, scope.Type, scope.ID), nil
+}
 
-	// Check if any permission matches
-	return MatchAnyPermission(permissions, permission)
+// canQuery reports whether the checker can read from the database with ctx.
+func (c *Checker) canQuery(ctx context.Context) bool {
+	return c.service != nil && (c.service.db != nil || txFromContext(ctx) != nil)
 }
 
 // HasAnyPermission checks if the user has any of the specified permissions.
@@ -132,7 +246,9 @@ func (c *Checker) GetRoles(scopeType, scopeID string) []string {
 }
 
 // GetPermissions returns all permissions the user has in a scope.
-// This is the UNION of permissions from all roles.
+// This is the UNION of permissions from all roles. It ignores role
+// conditions (see RoleDefinition.When): conditional patterns are included
+// whether or not their conditions hold.
 //
 // Example:
 //
@@ -160,8 +276,23 @@ func (c *Checker) GetPermissions(scopeType, scopeID string) []string {
 	return result
 }
 
+// unconditionalPermissions returns the permission patterns the user's roles
+// grant in a scope on every check, leaving out conditional ones.
+func (c *Checker) unconditionalPermissions(scopeType, scopeID string) []string {
+	var patterns []string
+	for _, role := range c.roles.GetRoles(scopeType, scopeID) {
+		if roleDef := c.registry.GetRole(role, scopeType); roleDef != nil {
+			patterns = append(patterns, roleDef.unconditionalPermissions()...)
+		}
+	}
+	return patterns
+}
+
 // CanAssignRole checks if the user can assign a role to another user in a scope.
-// This checks the "CanAssign" configuration of the user's roles.
+// This checks the "CanAssign" configuration of the user's roles in the scope
+// and the CanAssignIn rules of roles held in its ancestors. The ancestors are
+// read outside any transaction and a failed lookup denies; use
+// CanAssignRoleContext inside a transaction or to see lookup errors.
 //
 // Example:
 //
@@ -169,31 +300,101 @@ func (c *Checker) GetPermissions(scopeType, scopeID string) []string {
 //	    // User can assign the "member" role in this organization
 //	}
 func (c *Checker) CanAssignRole(targetRole, scopeType, scopeID string) bool {
-	// Get user's roles in this scope
-	roles := c.roles.GetRoles(scopeType, scopeID)
-	if len(roles) == 0 {
+	canAssign, err := c.CanAssignRoleContext(context.Background(), targetRole, scopeType, scopeID)
+	return err == nil && canAssign
+}
+
+// CanAssignRoleContext checks if the user can assign a role in a scope like
+// CanAssignRole, looking up the scope's ancestors with ctx.
+//
+// Example:
+//
+//	ok, err := checker.CanAssignRoleContext(ctx, "developer", "project", projectID)
+//	if err != nil {
+//	    return err
+//	}
+func (c *Checker) CanAssignRoleContext(ctx context.Context, targetRole, scopeType, scopeID string) (bool, error) {
+	ancestors, err := c.scopeAncestors(ctx, scopeType, scopeID)
+	if err != nil {
+		return false, err
+	}
+	return c.canAssignRole(targetRole, scopeType, scopeID, ancestors), nil
+}
+
+func (c *Checker) canAssignRole(targetRole, scopeType, scopeID string, ancestors []Scope) bool {
+	// Assigning is a write: impersonated checkers need explicit write access
+	if !c.impersonationAllowsWrites(scopeType, scopeID) {
 		return false
 	}
 
-	// Check if any of the user's roles can assign the target role
-	for _, userRole := range roles {
+	// Check if any of the user's roles in this scope can assign the target role
+	for _, userRole := range c.roles.GetRoles(scopeType, scopeID) {
 		if c.registry.CanRoleAssign(userRole, targetRole, scopeType) {
 			return true
 		}
 	}
+
+	// Check roles held in ancestor scopes (CanAssignIn)
+	for _, ancestor := range ancestors {
+		for _, userRole := range c.roles.GetRoles(ancestor.Type, ancestor.ID) {
+			if c.registry.CanRoleAssignIn(userRole, ancestor.Type, targetRole, scopeType) {
+				return true
+			}
+		}
+	}
 	return false
 }
 
-// GetAssignableRoles returns all roles the user can assign in a scope.
+// impersonationAllowsWrites reports whether write operations may be granted in
+// a scope. Regular checkers always allow them.
+func (c *Checker) impersonationAllowsWrites(scopeType, scopeID string) bool {
+	imp := c.impersonation
+	return imp == nil || imp.AllowWrites && imp.covers(scopeType, scopeID)
+}
+
+// scopeAncestors resolves the ancestors of a scope instance through the service
+// when some role can assign into that scope type from above. It returns nil
+// when no hierarchy lookup is needed or possible.
+func (c *Checker) scopeAncestors(ctx context.Context, scopeType, scopeID string) ([]Scope, error) {
+	if !c.canQuery(ctx) {
+		return nil, nil
+	}
+	if c.registry == nil || !c.registry.HasCrossScopeAssigners(scopeType) {
+		return nil, nil
+	}
+	return c.service.getAncestorScopes(ctx, scopeType, scopeID)
+}
+
+// GetAssignableRoles returns all roles the user can assign in a scope, through
+// the roles they hold in it and the CanAssignIn rules of roles held in its
+// ancestors. The ancestors are read outside any transaction and a failed
+// lookup leaves their roles out; use GetAssignableRolesContext inside a
+// transaction or to see lookup errors.
 //
 // Example:
 //
 //	roles := checker.GetAssignableRoles("organization", orgID)
 //	// roles might be ["member", "viewer"]
 func (c *Checker) GetAssignableRoles(scopeType, scopeID string) []string {
-	// Get user's roles in this scope
-	userRoles := c.roles.GetRoles(scopeType, scopeID)
-	if len(userRoles) == 0 {
+	roles, err := c.GetAssignableRolesContext(context.Background(), scopeType, scopeID)
+	if err != nil {
+		return c.assignableRoles(scopeType, scopeID, nil) // Fail closed: no authority from ancestors
+	}
+	return roles
+}
+
+// GetAssignableRolesContext returns all roles the user can assign in a scope
+// like GetAssignableRoles, looking up the scope's ancestors with ctx.
+func (c *Checker) GetAssignableRolesContext(ctx context.Context, scopeType, scopeID string) ([]string, error) {
+	ancestors, err := c.scopeAncestors(ctx, scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	return c.assignableRoles(scopeType, scopeID, ancestors), nil
+}
+
+func (c *Checker) assignableRoles(scopeType, scopeID string, ancestors []Scope) []string {
+	if !c.impersonationAllowsWrites(scopeType, scopeID) {
 		return nil
 	}
 
@@ -205,13 +406,8 @@ func (c *Checker) GetAssignableRoles(scopeType, scopeID string) []string {
 
 	// Collect all assignable roles
 	assignable := make(map[string]bool)
-	for _, userRole := range userRoles {
-		roleDef := scope.GetRole(userRole)
-		if roleDef == nil {
-			continue
-		}
-
-		for _, canAssign := range roleDef.GetCanAssign() {
+	addAssignable := func(roles []string) {
+		for _, canAssign := range roles {
 			if canAssign == "*" {
 				// Can assign any role in this scope
 				for _, r := range scope.GetRoles() {
@@ -223,6 +419,26 @@ func (c *Checker) GetAssignableRoles(scopeType, scopeID string) []string {
 		}
 	}
 
+	// Roles held in this scope
+	for _, userRole := range c.roles.GetRoles(scopeType, scopeID) {
+		if roleDef := scope.GetRole(userRole); roleDef != nil {
+			addAssignable(roleDef.GetCanAssign())
+		}
+	}
+
+	// Roles held in ancestor scopes (CanAssignIn)
+	for _, ancestor := range ancestors {
+		for _, userRole := range c.roles.GetRoles(ancestor.Type, ancestor.ID) {
+			if roleDef := c.registry.GetRole(userRole, ancestor.Type); roleDef != nil {
+				addAssignable(roleDef.GetCanAssignIn(scopeType))
+			}
+		}
+	}
+
+	if len(assignable) == 0 {
+		return nil
+	}
+
 	result := make([]string, 0, len(assignable))
 	for r := range assignable {
 		result = append(result, r)
@@ -239,7 +455,7 @@ func (c *Checker) GetAssignableRoles(scopeType, scopeID string) []string {
 //	    // User is editor in at least one project
 //	}
 func (c *Checker) HasRoleInAnyScope(role, scopeType string) bool {
-	for _, assignment := range c.roles.Assignments {
+	for _, assignment := range c.roles.active() {
 		if assignment.ScopeType == scopeType && assignment.Role == role {
 			return true
 		}
@@ -255,7 +471,7 @@ func (c *Checker) HasRoleInAnyScope(role, scopeType string) bool {
 //	// projectIDs might be ["proj_123", "proj_456"]
 func (c *Checker) GetScopesWithRole(role, scopeType string) []string {
 	var scopeIDs []string
-	for _, assignment := range c.roles.Assignments {
+	for _, assignment := range c.roles.active() {
 		if assignment.ScopeType == scopeType && assignment.Role == role {
 			scopeIDs = append(scopeIDs, assignment.ScopeID)
 		}
@@ -271,7 +487,7 @@ func (c *Checker) GetScopesWithRole(role, scopeType string) []string {
 //	// projectIDs might be ["proj_123", "proj_456", "proj_789"]
 func (c *Checker) GetScopesWithAnyRole(scopeType string) []string {
 	scopeIDSet := make(map[string]bool)
-	for _, assignment := range c.roles.Assignments {
+	for _, assignment := range c.roles.active() {
 		if assignment.ScopeType == scopeType {
 			scopeIDSet[assignment.ScopeID] = true
 		}
@@ -286,5 +502,5 @@ func (c *Checker) GetScopesWithAnyRole(scopeType string) []string {
 
 // IsEmpty returns true if the user has no role assignments.
 func (c *Checker) IsEmpty() bool {
-	return len(c.roles.Assignments) == 0
+	return len(c.roles.active()) == 0
 }
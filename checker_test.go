@@ -1,9 +1,11 @@
 package rolekit
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCheckerNewChecker tests the checker constructor
@@ -130,6 +132,32 @@ func TestCheckerHasPermission(t *testing.T) {
 	assert.False(t, checker.HasPermission("project.read", "project", "proj1"))
 }
 
+// TestCheckerHasPermissionCompiled tests compiled checks and their invalidation
+func TestCheckerHasPermissionCompiled(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("project").
+		Role("editor").Permissions("files.*", "*.read").
+		Role("viewer").Permissions("files.read")
+	require.NoError(t, registry.Freeze())
+
+	roles := NewUserRoles("user1", []RoleAssignment{
+		{UserID: "user1", Role: "viewer", ScopeType: "project", ScopeID: "proj1"},
+		{UserID: "user1", Role: "editor", ScopeType: "project", ScopeID: "*"},
+	})
+	checker := NewChecker("user1", roles, registry, nil)
+
+	assert.True(t, checker.HasPermission("files.write", "project", "proj2"))
+	assert.True(t, checker.HasPermission("members.read", "project", "proj1"))
+	assert.False(t, checker.HasPermission("members.write", "project", "proj1"))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		checker.HasPermission("members.write", "project", "proj1")
+	}))
+
+	// Changing a role recompiles it
+	registry.GetRole("editor", "project").Permissions("members.write")
+	assert.True(t, checker.HasPermission("members.write", "project", "proj1"))
+}
+
 // TestCheckerHasAnyPermission tests checking for any of multiple permissions
 func TestCheckerHasAnyPermission(t *testing.T) {
 	registry := NewRegistry()
@@ -300,6 +328,34 @@ func TestCheckerGetAssignableRoles(t *testing.T) {
 	assert.ElementsMatch(t, []string{"owner", "editor", "viewer"}, assignable2)
 }
 
+// TestCheckerCrossScopeWithoutDatabase tests that cross-scope rules fail closed without hierarchy data
+func TestCheckerCrossScopeWithoutDatabase(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("admin").CanAssign("member").CanAssignIn("project", "editor").
+		Role("member")
+	registry.DefineScope("project").ParentScope("organization").
+		Role("editor")
+
+	assignments := []RoleAssignment{
+		{UserID: "user123", Role: "admin", ScopeType: "organization", ScopeID: "org1"},
+	}
+	checker := NewChecker("user123", NewUserRoles("user123", assignments), registry, &Service{registry: registry})
+
+	assert.True(t, checker.CanAssignRole("member", "organization", "org1"))
+	assert.False(t, checker.CanAssignRole("editor", "project", "proj1"))
+	assert.Nil(t, checker.GetAssignableRoles("project", "proj1"))
+
+	// The context variants skip the hierarchy lookup without a database
+	ctx := context.Background()
+	canAssign, err := checker.CanAssignRoleContext(ctx, "editor", "project", "proj1")
+	assert.NoError(t, err)
+	assert.False(t, canAssign)
+	assignable, err := checker.GetAssignableRolesContext(ctx, "project", "proj1")
+	assert.NoError(t, err)
+	assert.Nil(t, assignable)
+}
+
 // TestCheckerHasRoleInAnyScope tests checking if user has role in any scope
 func TestCheckerHasRoleInAnyScope(t *testing.T) {
 	registry := NewRegistry()
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fernandezvara/rolekit/internal/definition"
+)
+
+// Kinds of constants the generator can emit.
+const (
+	kindScopes      = "scopes"
+	kindRoles       = "roles"
+	kindPermissions = "permissions"
+)
+
+// generator writes the Go source for a definition.
+type generator struct {
+	buf   strings.Builder
+	names map[string]string // Identifier -> what it was generated from
+}
+
+// generate returns formatted Go source declaring typed constants and helpers
+// for the requested kinds of names in the definition.
+func generate(def *definition.Definition, pkg, source string, kinds map[string]bool) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	g := &generator{names: make(map[string]string)}
+	g.printf("// Code generated by rolekit-gen from %s. DO NOT EDIT.\n\n", source)
+	g.printf("package %s\n\n", pkg)
+	if kinds[kindScopes] || kinds[kindPermissions] {
+		g.printf("import \"github.com/fernandezvara/rolekit\"\n\n")
+	}
+
+	if kinds[kindScopes] {
+		if err := g.scopes(def); err != nil {
+			return nil, err
+		}
+	}
+	if kinds[kindRoles] {
+		if err := g.roles(def); err != nil {
+			return nil, err
+		}
+	}
+	if kinds[kindPermissions] {
+		if err := g.permissions(def); err != nil {
+			return nil, err
+		}
+	}
+
+	src, err := format.Source([]byte(g.buf.String()))
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) scopes(def *definition.Definition) error {
+	names := def.ScopeNames()
+
+	g.printf("// ScopeType is a scope type defined in the registry.\n")
+	g.printf("type ScopeType string\n\n")
+	g.printf("// Scope types.\nconst (\n")
+	for _, name := range names {
+		ident, err := g.declare("Scope"+identifier(name), "scope "+name)
+		if err != nil {
+			return err
+		}
+		g.printf("\t%s ScopeType = %s\n", ident, strconv.Quote(name))
+	}
+	g.printf(")\n\n")
+
+	g.printf("// String returns the scope type name.\n")
+	g.printf("func (s ScopeType) String() string { return string(s) }\n\n")
+	g.printf("// ID returns the scope instance of this type with the given ID.\n")
+	g.printf("func (s ScopeType) ID(id string) rolekit.Scope { return rolekit.NewScope(string(s), id) }\n\n")
+
+	for _, name := range names {
+		ident, err := g.declare(identifier(name), "scope "+name)
+		if err != nil {
+			return err
+		}
+		g.printf("// %s returns the %s scope with the given ID.\n", ident, name)
+		g.printf("func %s(id string) rolekit.Scope { return rolekit.NewScope(%s, id) }\n\n", ident, strconv.Quote(name))
+	}
+	return nil
+}
+
+func (g *generator) roles(def *definition.Definition) error {
+	g.printf("// Role is a role defined in the registry.\n")
+	g.printf("type Role string\n\n")
+	g.printf("// String returns the role name.\n")
+	g.printf("func (r Role) String() string { return string(r) }\n\n")
+
+	for _, scopeName := range def.ScopeNames() {
+		roles := def.Scopes[scopeName].RoleNames()
+		if len(roles) == 0 {
+			continue
+		}
+		g.printf("// Roles of the %s scope.\nconst (\n", scopeName)
+		for _, roleName := range roles {
+			ident, err := g.declare(identifier(scopeName)+identifier(roleName), "role "+scopeName+"/"+roleName)
+			if err != nil {
+				return err
+			}
+			g.printf("\t%s Role = %s\n", ident, strconv.Quote(roleName))
+		}
+		g.printf(")\n\n")
+	}
+	return nil
+}
+
+func (g *generator) permissions(def *definition.Definition) error {
+	names := def.PermissionNames()
+
+	g.printf("// Permission is a permission checked by the application.\n")
+	g.printf("type Permission string\n\n")
+	g.printf("// Permissions.\nconst (\n")
+	idents := make([]string, 0, len(names))
+	for _, name := range names {
+		ident, err := g.declare(identifier(name), "permission "+name)
+		if err != nil {
+			return err
+		}
+		idents = append(idents, ident)
+		g.printf("\t%s Permission = %s", ident, strconv.Quote(name))
+		if description := strings.TrimSpace(def.Permissions[name]); description != "" {
+			g.printf(" // %s", strings.Join(strings.Fields(description), " "))
+		}
+		g.printf("\n")
+	}
+	g.printf(")\n\n")
+
+	g.printf("// AllPermissions lists every permission, sorted by name.\n")
+	g.printf("var AllPermissions = []Permission{%s}\n\n", strings.Join(idents, ", "))
+	g.printf("// String returns the permission name.\n")
+	g.printf("func (p Permission) String() string { return string(p) }\n\n")
+	g.printf("// In reports whether the checker's user holds the permission in a scope.\n")
+	g.printf("func (p Permission) In(checker *rolekit.Checker, scope rolekit.Scope) bool {\n")
+	g.printf("\treturn checker.HasPermission(string(p), scope.Type, scope.ID)\n}\n")
+	return nil
+}
+
+// declare reserves an identifier, failing when two names map to the same one.
+func (g *generator) declare(ident, from string) (string, error) {
+	if !token.IsIdentifier(ident) || !unicode.IsUpper(rune(ident[0])) {
+		return "", fmt.Errorf("%s: cannot derive a Go identifier (got %q)", from, ident)
+	}
+	if other, ok := g.names[ident]; ok {
+		return "", fmt.Errorf("%s and %s both generate %s; generate them into separate packages with -kinds", other, from, ident)
+	}
+	g.names[ident] = from
+	return ident, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// identifier converts a name such as "files.versions.restore" or
+// "project_manager" to an exported Go identifier: FilesVersionsRestore,
+// ProjectManager.
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fernandezvara/rolekit/internal/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testdataRoles is the YAML definition shared with the definition package.
+var testdataRoles = filepath.Join("..", "..", "internal", "definition", "testdata", "roles.yaml")
+
+var allKinds = map[string]bool{kindScopes: true, kindRoles: true, kindPermissions: true}
+
+func TestGenerate(t *testing.T) {
+	def, err := definition.LoadYAML(testdataRoles)
+	require.NoError(t, err)
+
+	src, err := generate(def, "authz", "roles.yaml", allKinds)
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.Contains(t, code, "// Code generated by rolekit-gen from roles.yaml. DO NOT EDIT.")
+	assert.Contains(t, code, "package authz")
+	assert.Contains(t, code, `import "github.com/fernandezvara/rolekit"`)
+
+	// Scopes
+	assert.Contains(t, code, `ScopeProject      ScopeType = "project"`)
+	assert.Contains(t, code, `func Project(id string) rolekit.Scope { return rolekit.NewScope("project", id) }`)
+
+	// Roles, prefixed with their scope
+	assert.Contains(t, code, `OrganizationProjectManager Role = "project_manager"`)
+	assert.Contains(t, code, `ProjectViewer Role = "viewer"`)
+
+	// Permissions, with descriptions
+	assert.Contains(t, code, `FilesUpload          Permission = "files.upload"           // Upload files`)
+	assert.Contains(t, code, `FilesVersionsRestore Permission = "files.versions.restore" // Restore an earlier version`)
+	assert.Contains(t, code, "var AllPermissions = []Permission{FilesRead, FilesUpload, FilesVersionsRestore}")
+	assert.Contains(t, code, "func (p Permission) In(checker *rolekit.Checker, scope rolekit.Scope) bool")
+}
+
+func TestGenerateKinds(t *testing.T) {
+	def, err := definition.LoadYAML(testdataRoles)
+	require.NoError(t, err)
+
+	src, err := generate(def, "roles", "roles.yaml", map[string]bool{kindRoles: true})
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.Contains(t, code, "type Role string")
+	assert.NotContains(t, code, "ScopeType")
+	assert.NotContains(t, code, "Permission")
+	assert.NotContains(t, code, "import")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Run("invalid package name", func(t *testing.T) {
+		_, err := generate(&definition.Definition{}, "my-roles", "roles.yaml", allKinds)
+		assert.ErrorContains(t, err, "invalid package name")
+	})
+
+	t.Run("identifier collision", func(t *testing.T) {
+		def := &definition.Definition{
+			Scopes: map[string]definition.ScopeDefinition{
+				"project": {Roles: map[string]definition.RoleDefinition{"viewer": {}}},
+			},
+			Permissions: map[string]string{"project.viewer": ""},
+		}
+		_, err := generate(def, "roles", "roles.yaml", allKinds)
+		assert.ErrorContains(t, err, "both generate ProjectViewer")
+
+		// Generating the kinds separately avoids the collision
+		_, err = generate(def, "roles", "roles.yaml", map[string]bool{kindPermissions: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("no identifier", func(t *testing.T) {
+		def := &definition.Definition{Permissions: map[string]string{"1.read": ""}}
+		_, err := generate(def, "roles", "roles.yaml", allKinds)
+		assert.ErrorContains(t, err, "cannot derive a Go identifier")
+	})
+}
+
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "FilesVersionsRestore", identifier("files.versions.restore"))
+	assert.Equal(t, "ProjectManager", identifier("project_manager"))
+	assert.Equal(t, "BillingView", identifier("billing-view"))
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := parseKinds("roles, permissions")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{kindRoles: true, kindPermissions: true}, kinds)
+
+	_, err = parseKinds("roles,users")
+	assert.ErrorContains(t, err, `unknown kind "users"`)
+
+	_, err = parseKinds("")
+	assert.Error(t, err)
+}
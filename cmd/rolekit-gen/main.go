@@ -0,0 +1,102 @@
+// Command rolekit-gen generates typed Go constants for the scope types, roles
+// and permissions of a rolekit registry, so that misspelled names become
+// compile errors instead of silently denied checks.
+//
+// The registry is read from a YAML definition:
+//
+//	rolekit-gen -config roles.yaml -package authz -out authz/names_gen.go
+//
+// or from a Go package exposing a function that returns it (run from inside
+// the module that contains the package):
+//
+//	rolekit-gen -pkg example.com/app/authz -func Registry -package perms -kinds permissions -out perms/perms_gen.go
+//
+// It emits a ScopeType per scope (ScopeProject) with a constructor
+// (Project(id) rolekit.Scope), a Role per role prefixed with its scope
+// (ProjectEditor), and a Permission per catalogued permission (FilesUpload),
+// falling back to the role permissions without wildcards when the registry
+// has no catalog. Use it with go:generate:
+//
+//	//go:generate go run github.com/fernandezvara/rolekit/cmd/rolekit-gen -config roles.yaml -package authz -out names_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fernandezvara/rolekit/internal/definition"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "rolekit-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("rolekit-gen", flag.ContinueOnError)
+	config := flags.String("config", "", "YAML registry definition to read")
+	pkgPath := flags.String("pkg", "", "import path of a Go package defining the registry")
+	fn := flags.String("func", "Registry", "function of -pkg returning the *rolekit.Registry")
+	pkg := flags.String("package", "roles", "package name of the generated file")
+	out := flags.String("out", "", "output file (default: standard output)")
+	kindList := flags.String("kinds", "scopes,roles,permissions", "comma-separated kinds of names to generate")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	kinds, err := parseKinds(*kindList)
+	if err != nil {
+		return err
+	}
+
+	var def *definition.Definition
+	var source string
+	switch {
+	case *config != "" && *pkgPath != "":
+		return fmt.Errorf("use either -config or -pkg, not both")
+	case *config != "":
+		def, err = definition.LoadYAML(*config)
+		source = *config
+	case *pkgPath != "":
+		def, err = definition.LoadPackage(*pkgPath, *fn)
+		source = *pkgPath + "." + *fn
+	default:
+		return fmt.Errorf("one of -config or -pkg is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(def, *pkg, source, kinds)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
+
+// parseKinds parses the -kinds flag.
+func parseKinds(list string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, kind := range strings.Split(list, ",") {
+		kind = strings.TrimSpace(kind)
+		switch kind {
+		case kindScopes, kindRoles, kindPermissions:
+			kinds[kind] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown kind %q (want scopes, roles or permissions)", kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("-kinds selects nothing to generate")
+	}
+	return kinds, nil
+}
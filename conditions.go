@@ -0,0 +1,705 @@
+package rolekit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// PERMISSION CONDITIONS
+// ============================================================================
+
+// Attributes are the values a permission condition is evaluated against,
+// e.g. {"amount": 2500, "request.ip": "10.1.2.3"}. Numbers may be any Go
+// integer or float type.
+type Attributes map[string]any
+
+// Attributes pre-populated from the checker's context by HasPermissionWith.
+// Attributes passed by the caller take precedence.
+const (
+	AttrUserID      = "user.id"            // User the checker is for
+	AttrRequestIP   = "request.ip"         // Client IP (see WithIPAddress)
+	AttrUserAgent   = "request.user_agent" // Client user agent (see WithUserAgent)
+	AttrRequestID   = "request.id"         // Request ID (see WithRequestID)
+	AttrTimeHour    = "time.hour"          // Hour of the day in UTC, 0-23
+	AttrTimeWeekday = "time.weekday"       // Day of the week in UTC, 0 (Sunday) to 6
+	AttrTimeUnix    = "time.unix"          // Seconds since the Unix epoch
+)
+
+// maxConditionBytes bounds the size of condition expressions.
+const maxConditionBytes = 1024
+
+// Condition is a compiled permission condition. The expression language only
+// has attribute references, literals, comparisons and boolean operators, so
+// evaluation always terminates and has no side effects:
+//
+//	amount < 10000
+//	request.ip in "10.0.0.0/8" && time.hour >= 8 && time.hour < 18
+//	region in ["eu-west-1", "eu-central-1"] || !external
+//
+// Literals are numbers, 'single' or "double" quoted strings, true and false.
+// "in" tests membership in a list literal, or an IP address against a CIDR
+// string. A condition referring to a missing attribute, or comparing values of
+// different types, evaluates to false. Such an unknown value stays unknown
+// through ! and through && and || unless the other operand decides the
+// result, so negating it never grants.
+type Condition struct {
+	expression string
+	root       conditionNode
+}
+
+// CompileCondition parses and type-checks a condition expression.
+//
+// Example:
+//
+//	cond, err := rolekit.CompileCondition("amount < 10000")
+func CompileCondition(expression string) (*Condition, error) {
+	if len(expression) > maxConditionBytes {
+		return nil, fmt.Errorf("%w: condition longer than %d bytes", ErrInvalidCondition, maxConditionBytes)
+	}
+	tokens, err := lexCondition(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	if !root.boolean() {
+		return nil, fmt.Errorf("%w: %q does not evaluate to a boolean", ErrInvalidCondition, expression)
+	}
+	return &Condition{expression: expression, root: root}, nil
+}
+
+// String returns the source expression of the condition.
+func (c *Condition) String() string {
+	return c.expression
+}
+
+// Evaluate reports whether the condition holds for the attributes.
+func (c *Condition) Evaluate(attrs Attributes) bool {
+	v, ok := c.root.eval(attrs)
+	b, isBool := v.(bool)
+	return ok && isBool && b
+}
+
+// When attaches a condition to one of the role's permissions: the permission
+// is only granted through this role when the condition holds for the
+// attributes of the check (see Checker.HasPermissionWith). Several conditions
+// on the same permission must all hold. Invalid expressions, or permissions
+// the role does not grant, are reported by Registry.Validate and never grant
+// anything.
+//
+// Example:
+//
+//	registry.DefineScope("organization").
+//	    Role("accountant").Permissions("invoices.read", "invoices.approve").
+//	    When("invoices.approve", "amount < 10000").
+//	    Role("finance_lead").Permissions("invoices.*").
+//	    When("invoices.*", `request.ip in "10.0.0.0/8"`)
+func (r *RoleDefinition) When(permission, expression string) *RoleDefinition {
+	registry := r.scope.registry
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	cond, err := CompileCondition(expression)
+	if err == nil && !containsString(r.permissions, permission) {
+		err = fmt.Errorf("%w: role does not grant %q", ErrInvalidCondition, permission)
+	}
+	if err != nil {
+		registry.definitionErrors = append(registry.definitionErrors,
+			fmt.Errorf("%s/%s: condition on %q: %w", r.scopeName, r.name, permission, err))
+		cond = nil // Fail closed
+	}
+
+	if r.conditions == nil {
+		r.conditions = make(map[string][]*Condition)
+	}
+	r.conditions[permission] = append(r.conditions[permission], cond)
+	r.compiled.Store(nil)
+	return r
+}
+
+// GetConditions returns the conditions attached to one of the role's permissions.
+func (r *RoleDefinition) GetConditions(permission string) []*Condition {
+	return r.conditions[permission]
+}
+
+// HasConditions reports whether any of the role's permissions is conditional.
+func (r *RoleDefinition) HasConditions() bool {
+	return len(r.conditions) > 0
+}
+
+// unconditionalPermissions returns the role's permission patterns that have
+// no conditions, i.e. the ones it grants on every check.
+func (r *RoleDefinition) unconditionalPermissions() []string {
+	if !r.HasConditions() {
+		return r.permissions
+	}
+	var patterns []string
+	for _, pattern := range r.permissions {
+		if _, ok := r.conditions[pattern]; !ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// grants reports whether the role grants a permission for the attributes:
+// some pattern of the role must match it and all of that pattern's conditions
+// must hold.
+func (r *RoleDefinition) grants(permission string, attrs Attributes) bool {
+	compiled := r.compiledPermissions()
+	if compiled.unconditional.Match(permission) {
+		return true
+	}
+	for _, pattern := range compiled.conditional {
+		if MatchPermission(pattern, permission) && conditionsHold(r.conditions[pattern], attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+func conditionsHold(conditions []*Condition, attrs Attributes) bool {
+	for _, cond := range conditions {
+		if cond == nil || !cond.Evaluate(attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionAttributes returns the user, time and request attributes,
+// overridden by the caller's.
+func conditionAttributes(userID string, request, attrs Attributes) Attributes {
+	now := time.Now().UTC()
+	result := Attributes{
+		AttrUserID:      userID,
+		AttrTimeHour:    now.Hour(),
+		AttrTimeWeekday: int(now.Weekday()),
+		AttrTimeUnix:    now.Unix(),
+	}
+	for k, v := range request {
+		result[k] = v
+	}
+	for k, v := range attrs {
+		result[k] = v
+	}
+	return result
+}
+
+// requestAttributes returns the request IP, user agent and ID of the audit
+// context of ctx.
+func requestAttributes(ctx context.Context) Attributes {
+	audit := GetAuditContext(ctx)
+	request := Attributes{}
+	if audit.IPAddress != "" {
+		request[AttrRequestIP] = audit.IPAddress
+	}
+	if audit.UserAgent != "" {
+		request[AttrUserAgent] = audit.UserAgent
+	}
+	if audit.RequestID != "" {
+		request[AttrRequestID] = audit.RequestID
+	}
+	return request
+}
+
+// ============================================================================
+// EXPRESSION LANGUAGE
+// ============================================================================
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type conditionToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexCondition splits an expression into tokens.
+func lexCondition(src string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated string at offset %d", ErrInvalidCondition, i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			start := i
+			i++
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: tokenNumber, text: src[start:i], pos: start})
+
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '.' || src[i] >= 'a' && src[i] <= 'z' ||
+				src[i] >= 'A' && src[i] <= 'Z' || src[i] >= '0' && src[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: tokenIdent, text: src[start:i], pos: start})
+
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrInvalidCondition, string(c), i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, conditionToken{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// conditionParser is a recursive-descent parser for:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | compare
+//	compare = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" ) operand ]
+//	operand = literal | attribute | list | "(" or ")"
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) peek() conditionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() conditionToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *conditionParser) accept(text string) bool {
+	if t := p.peek(); (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidCondition, fmt.Sprintf(format, args...), p.peek().pos)
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if !left.boolean() || !right.boolean() {
+			return nil, p.errorf("operands of || must be boolean")
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if !left.boolean() || !right.boolean() {
+			return nil, p.errorf("operands of && must be boolean")
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if !operand.boolean() {
+			return nil, p.errorf("operand of ! must be boolean")
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *conditionParser) parseCompare() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokenOperator && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">="):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := left.(*listNode); ok {
+			return nil, p.errorf("lists can only follow in")
+		}
+		if _, ok := right.(*listNode); ok {
+			return nil, p.errorf("lists can only follow in")
+		}
+		return &compareNode{op: t.text, left: left, right: right}, nil
+
+	case t.kind == tokenIdent && t.text == "in":
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		switch r := right.(type) {
+		case *listNode:
+			return &inListNode{value: left, list: r}, nil
+		case *literalNode:
+			if s, ok := r.value.(string); ok {
+				_, network, err := net.ParseCIDR(s)
+				if err != nil {
+					return nil, p.errorf("invalid CIDR %q", s)
+				}
+				return &inNetworkNode{value: left, network: network}, nil
+			}
+		}
+		return nil, p.errorf("in must be followed by a list or a CIDR string")
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q at offset %d", ErrInvalidCondition, t.text, t.pos)
+		}
+		return &literalNode{value: n}, nil
+
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "in":
+			return nil, fmt.Errorf("%w: unexpected in at offset %d", ErrInvalidCondition, t.pos)
+		}
+		return &attributeNode{name: t.text}, nil
+
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, p.errorf("expected )")
+			}
+			return inner, nil
+
+		case "[":
+			list := &listNode{}
+			for !p.accept("]") {
+				if len(list.values) > 0 && !p.accept(",") {
+					return nil, p.errorf("expected , or ]")
+				}
+				item, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				lit, ok := item.(*literalNode)
+				if !ok {
+					return nil, p.errorf("list items must be literals")
+				}
+				list.values = append(list.values, lit.value)
+			}
+			return list, nil
+		}
+	}
+
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected end of condition", ErrInvalidCondition)
+	}
+	return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrInvalidCondition, t.text, t.pos)
+}
+
+// conditionNode is a node of a compiled condition. eval returns false as its
+// second result when the value is unknown (missing attribute, type mismatch).
+type conditionNode interface {
+	eval(attrs Attributes) (any, bool)
+	boolean() bool // Whether the node can produce a boolean
+}
+
+type literalNode struct{ value any }
+
+func (n *literalNode) eval(Attributes) (any, bool) { return n.value, true }
+func (n *literalNode) boolean() bool {
+	_, ok := n.value.(bool)
+	return ok
+}
+
+type attributeNode struct{ name string }
+
+func (n *attributeNode) eval(attrs Attributes) (any, bool) {
+	v, ok := attrs[n.name]
+	if !ok {
+		return nil, false
+	}
+	return normalizeAttribute(v)
+}
+func (n *attributeNode) boolean() bool { return true }
+
+type listNode struct{ values []any }
+
+func (n *listNode) eval(Attributes) (any, bool) { return nil, false }
+func (n *listNode) boolean() bool               { return false }
+
+type logicalNode struct {
+	or          bool
+	left, right conditionNode
+}
+
+// eval uses three-valued logic so an unknown operand cannot be negated into a
+// grant: the result is known when a known operand decides it (true for ||,
+// false for &&) or both operands are known.
+func (n *logicalNode) eval(attrs Attributes) (any, bool) {
+	left, leftKnown := evalTruth(n.left, attrs)
+	if leftKnown && left == n.or {
+		return left, true
+	}
+	right, rightKnown := evalTruth(n.right, attrs)
+	if rightKnown && right == n.or {
+		return right, true
+	}
+	if !leftKnown || !rightKnown {
+		return false, false
+	}
+	return !n.or, true
+}
+func (n *logicalNode) boolean() bool { return true }
+
+type notNode struct{ operand conditionNode }
+
+func (n *notNode) eval(attrs Attributes) (any, bool) {
+	v, ok := n.operand.eval(attrs)
+	b, isBool := v.(bool)
+	if !ok || !isBool {
+		return false, false // Unknown stays unknown: !missing is not true
+	}
+	return !b, true
+}
+func (n *notNode) boolean() bool { return true }
+
+type compareNode struct {
+	op          string
+	left, right conditionNode
+}
+
+func (n *compareNode) eval(attrs Attributes) (any, bool) {
+	l, ok := n.left.eval(attrs)
+	if !ok {
+		return false, false
+	}
+	r, ok := n.right.eval(attrs)
+	if !ok {
+		return false, false
+	}
+
+	var cmp int
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return false, false
+		}
+		cmp = compareOrdered(lv, rv)
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return false, false
+		}
+		cmp = strings.Compare(lv, rv)
+	case bool:
+		rv, ok := r.(bool)
+		if !ok || n.op != "==" && n.op != "!=" {
+			return false, false
+		}
+		if lv != rv {
+			cmp = 1
+		}
+	default:
+		return false, false
+	}
+
+	switch n.op {
+	case "==":
+		return cmp == 0, true
+	case "!=":
+		return cmp != 0, true
+	case "<":
+		return cmp < 0, true
+	case "<=":
+		return cmp <= 0, true
+	case ">":
+		return cmp > 0, true
+	default:
+		return cmp >= 0, true
+	}
+}
+func (n *compareNode) boolean() bool { return true }
+
+type inListNode struct {
+	value conditionNode
+	list  *listNode
+}
+
+func (n *inListNode) eval(attrs Attributes) (any, bool) {
+	v, ok := n.value.eval(attrs)
+	if !ok {
+		return false, false
+	}
+	for _, item := range n.list.values {
+		if item == v {
+			return true, true
+		}
+	}
+	return false, true
+}
+func (n *inListNode) boolean() bool { return true }
+
+type inNetworkNode struct {
+	value   conditionNode
+	network *net.IPNet
+}
+
+func (n *inNetworkNode) eval(attrs Attributes) (any, bool) {
+	v, ok := n.value.eval(attrs)
+	s, isString := v.(string)
+	if !ok || !isString {
+		return false, false
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		// Addresses may carry a port (e.g. from http.Request.RemoteAddr)
+		if host, _, err := net.SplitHostPort(s); err == nil {
+			ip = net.ParseIP(host)
+		}
+	}
+	if ip == nil {
+		return false, false
+	}
+	return n.network.Contains(ip), true
+}
+func (n *inNetworkNode) boolean() bool { return true }
+
+// evalTruth evaluates a node as a boolean; known is false when the value is
+// unknown or not a boolean.
+func evalTruth(node conditionNode, attrs Attributes) (value, known bool) {
+	v, ok := node.eval(attrs)
+	b, isBool := v.(bool)
+	return b, ok && isBool
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// normalizeAttribute converts attribute values to the types conditions
+// compare: float64, string and bool.
+func normalizeAttribute(v any) (any, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int8:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case string, bool:
+		return x, true
+	case net.IP:
+		return x.String(), true
+	case fmt.Stringer:
+		return x.String(), true
+	}
+	return nil, false
+}
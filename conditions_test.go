@@ -0,0 +1,157 @@
+package rolekit
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompileCondition tests parsing and type-checking condition expressions
+func TestCompileCondition(t *testing.T) {
+	valid := []string{
+		"amount < 10000",
+		"amount <= 10000 && currency == 'EUR'",
+		`request.ip in "10.0.0.0/8" || request.ip in "192.168.0.0/16"`,
+		`region in ["eu-west-1", "eu-central-1"]`,
+		"!(external) && (time.hour >= 8 && time.hour < 18)",
+		"approved",
+		"true",
+		"amount > -5.5",
+	}
+	for _, expr := range valid {
+		cond, err := CompileCondition(expr)
+		assert.NoError(t, err, expr)
+		if err == nil {
+			assert.Equal(t, expr, cond.String())
+		}
+	}
+
+	invalid := []string{
+		"",
+		"amount <",
+		"amount < 10000 &&",
+		"(amount < 10000",
+		"amount = 10",
+		"amount < 'open",
+		"42",
+		"'text'",
+		"amount + 1 > 2",
+		"!42",
+		"1 && true",
+		`request.ip in "10.0.0.0/33"`,
+		"region in region",
+		"region in [a, b]",
+		"[1, 2] == region",
+		"in < 3",
+		"1.2.3 < amount",
+		strings.Repeat("a", maxConditionBytes+1),
+	}
+	for _, expr := range invalid {
+		_, err := CompileCondition(expr)
+		assert.True(t, IsInvalidCondition(err), "expected %q to be rejected, got %v", expr, err)
+	}
+}
+
+// TestConditionEvaluate tests evaluating conditions against attributes
+func TestConditionEvaluate(t *testing.T) {
+	tests := []struct {
+		expr     string
+		attrs    Attributes
+		expected bool
+	}{
+		{"amount < 10000", Attributes{"amount": 2500}, true},
+		{"amount < 10000", Attributes{"amount": int64(10000)}, false},
+		{"amount < 10000", Attributes{"amount": 99.5}, true},
+		{"amount < 10000", Attributes{"amount": "2500"}, false},
+		{"amount < 10000", Attributes{}, false},
+		{"currency == 'EUR'", Attributes{"currency": "EUR"}, true},
+		{"currency != 'EUR'", Attributes{"currency": "USD"}, true},
+		{"approved", Attributes{"approved": true}, true},
+		{"approved", Attributes{"approved": "yes"}, false},
+		{"!external", Attributes{"external": false}, true},
+		{"!external", Attributes{}, false},
+		{"approved == true", Attributes{"approved": true}, true},
+		{"approved < true", Attributes{"approved": true}, false},
+		{`ip in "10.0.0.0/8"`, Attributes{"ip": "10.1.2.3"}, true},
+		{`ip in "10.0.0.0/8"`, Attributes{"ip": "10.1.2.3:51234"}, true},
+		{`ip in "10.0.0.0/8"`, Attributes{"ip": net.ParseIP("10.9.9.9")}, true},
+		{`ip in "10.0.0.0/8"`, Attributes{"ip": "203.0.113.7"}, false},
+		{`ip in "10.0.0.0/8"`, Attributes{"ip": "not-an-ip"}, false},
+		{`region in ["eu", "us"]`, Attributes{"region": "eu"}, true},
+		{`region in ["eu", "us"]`, Attributes{"region": "ap"}, false},
+		{`tier in [1, 2]`, Attributes{"tier": 2}, true},
+		{"a || b", Attributes{"b": true}, true},
+		{"a && b", Attributes{"a": true}, false},
+		{"(a || b) && c", Attributes{"a": true, "c": true}, true},
+		{"!(amount >= 10000 && true)", Attributes{}, false},
+		{"!(blocked == true || blocked == true)", Attributes{}, false},
+		{"!(amount >= 10000 && false)", Attributes{}, true},
+		{"!(blocked || true)", Attributes{}, false},
+		{"!(a && b)", Attributes{"a": true, "b": false}, true},
+	}
+	for _, tt := range tests {
+		cond, err := CompileCondition(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.expected, cond.Evaluate(tt.attrs), "%s with %v", tt.expr, tt.attrs)
+	}
+}
+
+// TestRegistryConditionValidation tests that invalid conditions are reported and never grant
+func TestRegistryConditionValidation(t *testing.T) {
+	r := NewRegistry()
+	r.DefineScope("organization").
+		Role("accountant").Permissions("invoices.read", "invoices.approve").
+		When("invoices.approve", "amount < 10000")
+	require.NoError(t, r.Validate())
+
+	role := r.GetRole("accountant", "organization")
+	assert.True(t, role.HasConditions())
+	require.Len(t, role.GetConditions("invoices.approve"), 1)
+	assert.Equal(t, "amount < 10000", role.GetConditions("invoices.approve")[0].String())
+
+	role.When("invoices.read", "amount <").
+		When("invoices.delete", "amount < 10")
+	err := r.Validate()
+	assert.True(t, IsInvalidCondition(err))
+	assert.Contains(t, err.Error(), "organization/accountant")
+	assert.Contains(t, err.Error(), `"invoices.delete"`)
+
+	assert.False(t, role.grants("invoices.read", Attributes{"amount": 1}))
+}
+
+// TestCheckerHasPermissionWith tests conditional permissions on checks
+func TestCheckerHasPermissionWith(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("accountant").Permissions("invoices.read", "invoices.approve").
+		When("invoices.approve", "amount < 10000").
+		Role("finance_lead").Permissions("invoices.*").
+		When("invoices.*", `request.ip in "10.0.0.0/8"`)
+
+	accountant := NewChecker("user1", NewUserRoles("user1", []RoleAssignment{
+		{UserID: "user1", Role: "accountant", ScopeType: "organization", ScopeID: "org1"},
+	}), registry, nil)
+
+	assert.True(t, accountant.HasPermission("invoices.read", "organization", "org1"))
+	assert.False(t, accountant.HasPermission("invoices.approve", "organization", "org1"))
+	assert.True(t, accountant.HasPermissionWith("invoices.approve", "organization", "org1", Attributes{"amount": 2500}))
+	assert.False(t, accountant.HasPermissionWith("invoices.approve", "organization", "org1", Attributes{"amount": 25000}))
+	assert.False(t, accountant.HasPermissionWith("invoices.approve", "organization", "org2", Attributes{"amount": 2500}))
+
+	lead := NewChecker("user2", NewUserRoles("user2", []RoleAssignment{
+		{UserID: "user2", Role: "finance_lead", ScopeType: "organization", ScopeID: "org1"},
+	}), registry, nil)
+
+	assert.False(t, lead.HasPermission("invoices.approve", "organization", "org1"))
+	assert.True(t, lead.HasPermissionWith("invoices.approve", "organization", "org1", Attributes{AttrRequestIP: "10.0.0.7"}))
+
+	// Request attributes come from the context the checker was created with
+	service := &Service{registry: registry}
+	lead = service.newChecker(WithIPAddress(context.Background(), "10.20.30.40"), "user2", lead.roles)
+	assert.True(t, lead.HasPermission("invoices.approve", "organization", "org1"))
+	assert.False(t, lead.HasPermissionWith("invoices.approve", "organization", "org1", Attributes{AttrRequestIP: "203.0.113.7"}))
+}
@@ -0,0 +1,110 @@
+package rolekit
+
+// RoleRef identifies a role within a scope type.
+type RoleRef struct {
+	ScopeType string
+	Role      string
+}
+
+// NewRoleRef creates a new RoleRef.
+func NewRoleRef(scopeType, role string) RoleRef {
+	return RoleRef{ScopeType: scopeType, Role: role}
+}
+
+// String returns a string representation of the role reference.
+func (r RoleRef) String() string {
+	return r.ScopeType + "/" + r.Role
+}
+
+// RoleConstraint is a static separation-of-duties constraint: no user may hold
+// both roles in the same scope instance, or in scope instances related through
+// the scope hierarchy (for example an organization and one of its projects).
+type RoleConstraint struct {
+	Name   string
+	First  RoleRef
+	Second RoleRef
+}
+
+// Conflicts returns the role that conflicts with the given one under this
+// constraint, or false if the constraint does not involve that role.
+func (c RoleConstraint) Conflicts(ref RoleRef) (RoleRef, bool) {
+	switch ref {
+	case c.First:
+		return c.Second, true
+	case c.Second:
+		return c.First, true
+	}
+	return RoleRef{}, false
+}
+
+// MutuallyExclusive declares a separation-of-duties constraint between two roles.
+// The roles may belong to the same scope type or to scope types that are
+// related through ParentScope.
+//
+// Example:
+//
+//	registry.MutuallyExclusive("sox-payments",
+//	    rolekit.NewRoleRef("organization", "payments_approver"),
+//	    rolekit.NewRoleRef("organization", "payments_submitter"))
+func (r *Registry) MutuallyExclusive(name string, first, second RoleRef) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name == "" {
+		name = first.String() + " <> " + second.String()
+	}
+	r.constraints = append(r.constraints, RoleConstraint{
+		Name:   name,
+		First:  first,
+		Second: second,
+	})
+	return r
+}
+
+// GetConstraints returns all separation-of-duties constraints.
+func (r *Registry) GetConstraints() []RoleConstraint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]RoleConstraint, len(r.constraints))
+	copy(result, r.constraints)
+	return result
+}
+
+// ConflictingRoles returns the constraints that involve a role in a scope type.
+func (r *Registry) ConflictingRoles(role, scopeType string) []RoleConstraint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ref := NewRoleRef(scopeType, role)
+	var result []RoleConstraint
+	for _, c := range r.constraints {
+		if _, ok := c.Conflicts(ref); ok {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// ExclusiveWith declares that this role cannot be held together with the given
+// roles of the same scope type.
+//
+// Example:
+//
+//	scope.Role("payments_approver").ExclusiveWith("payments_submitter")
+func (r *RoleDefinition) ExclusiveWith(roles ...string) *RoleDefinition {
+	return r.ExclusiveWithIn(r.scopeName, roles...)
+}
+
+// ExclusiveWithIn declares that this role cannot be held together with the given
+// roles of another scope type in a related (parent or child) scope instance.
+//
+// Example:
+//
+//	scope.Role("auditor").ExclusiveWithIn("project", "editor")
+func (r *RoleDefinition) ExclusiveWithIn(scopeType string, roles ...string) *RoleDefinition {
+	for _, role := range roles {
+		r.scope.registry.MutuallyExclusive("", NewRoleRef(r.scopeName, r.name), NewRoleRef(scopeType, role))
+	}
+	return r
+}
@@ -0,0 +1,163 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistryMutuallyExclusive tests declaring separation-of-duties constraints
+func TestRegistryMutuallyExclusive(t *testing.T) {
+	r := NewRegistry()
+	r.DefineScope("organization").
+		Role("payments_approver").Permissions("payments.approve").
+		ExclusiveWith("payments_submitter").
+		Role("payments_submitter").Permissions("payments.submit")
+
+	constraints := r.GetConstraints()
+	assert.Len(t, constraints, 1)
+	assert.Equal(t, NewRoleRef("organization", "payments_approver"), constraints[0].First)
+	assert.Equal(t, NewRoleRef("organization", "payments_submitter"), constraints[0].Second)
+	assert.NotEmpty(t, constraints[0].Name)
+
+	r.MutuallyExclusive("auditor-editor",
+		NewRoleRef("organization", "auditor"),
+		NewRoleRef("project", "editor"))
+	assert.Len(t, r.GetConstraints(), 2)
+	assert.Equal(t, "auditor-editor", r.GetConstraints()[1].Name)
+}
+
+// TestRegistryConflictingRoles tests looking up constraints by role
+func TestRegistryConflictingRoles(t *testing.T) {
+	r := NewRegistry()
+	r.DefineScope("organization").
+		Role("auditor").ExclusiveWithIn("project", "editor", "admin")
+
+	assert.Len(t, r.ConflictingRoles("auditor", "organization"), 2)
+	assert.Len(t, r.ConflictingRoles("editor", "project"), 1)
+	assert.Empty(t, r.ConflictingRoles("editor", "organization"))
+	assert.Empty(t, r.ConflictingRoles("viewer", "project"))
+}
+
+// TestRoleConstraintConflicts tests resolving the opposite side of a constraint
+func TestRoleConstraintConflicts(t *testing.T) {
+	c := RoleConstraint{
+		First:  NewRoleRef("organization", "approver"),
+		Second: NewRoleRef("organization", "submitter"),
+	}
+
+	other, ok := c.Conflicts(NewRoleRef("organization", "approver"))
+	assert.True(t, ok)
+	assert.Equal(t, "submitter", other.Role)
+
+	other, ok = c.Conflicts(NewRoleRef("organization", "submitter"))
+	assert.True(t, ok)
+	assert.Equal(t, "approver", other.Role)
+
+	_, ok = c.Conflicts(NewRoleRef("project", "approver"))
+	assert.False(t, ok)
+}
+
+// TestServiceScopesRelatedSameType tests scope relation for scopes of the same type
+func TestServiceScopesRelatedSameType(t *testing.T) {
+	service := &Service{registry: NewRegistry()}
+	ctx := context.Background()
+
+	related, err := service.scopesRelated(ctx, NewScope("organization", "org1"), NewScope("organization", "org1"))
+	assert.NoError(t, err)
+	assert.True(t, related)
+
+	related, err = service.scopesRelated(ctx, NewScope("organization", "org1"), NewScope("organization", "*"))
+	assert.NoError(t, err)
+	assert.True(t, related)
+
+	related, err = service.scopesRelated(ctx, NewScope("organization", "org1"), NewScope("organization", "org2"))
+	assert.NoError(t, err)
+	assert.False(t, related)
+}
+
+// TestServiceConstraintsDatabase tests constraint enforcement with real database
+func TestServiceConstraintsDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	service.Registry().GetScope("organization").GetRole("developer").ExclusiveWith("viewer")
+	service.Registry().GetScope("organization").GetRole("team_lead").ExclusiveWithIn("project", "developer")
+
+	userID := helper.CreateTestUser("user")
+	orgID := helper.CreateTestOrg("org")
+	projectID := helper.CreateTestProject("project")
+	adminID := helper.CreateTestUser("admin")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), adminID)
+
+	t.Run("Same scope conflict", func(t *testing.T) {
+		assert.NoError(t, service.Assign(ctx, userID, "developer", "organization", orgID))
+		err := service.Assign(ctx, userID, "viewer", "organization", orgID)
+		assert.True(t, IsConstraintViolation(err))
+		helper.AssertRoleNotAssigned(userID, "viewer", "organization", orgID)
+	})
+
+	t.Run("Parent scope conflict", func(t *testing.T) {
+		other := helper.CreateTestUser("other")
+		assert.NoError(t, service.SetScopeParent(ctx, "project", projectID, "organization", orgID))
+		assert.NoError(t, service.AssignDirect(ctx, other, "developer", "project", projectID))
+		err := service.AssignDirect(ctx, other, "team_lead", "organization", orgID)
+		assert.True(t, IsConstraintViolation(err))
+	})
+
+	t.Run("Conflict within batch", func(t *testing.T) {
+		batchUser := helper.CreateTestUser("batch")
+		err := service.AssignMultiple(ctx, []RoleAssignment{
+			{UserID: batchUser, Role: "developer", ScopeType: "organization", ScopeID: orgID},
+			{UserID: batchUser, Role: "viewer", ScopeType: "organization", ScopeID: orgID},
+		})
+		assert.True(t, IsConstraintViolation(err))
+	})
+
+	t.Run("Conflict in archived scope", func(t *testing.T) {
+		archivedUser := helper.CreateTestUser("archived")
+		archivedProjectID := helper.CreateTestProject("archived")
+		assert.NoError(t, service.SetScopeParent(ctx, "project", archivedProjectID, "organization", orgID))
+		assert.NoError(t, service.AssignDirect(ctx, archivedUser, "developer", "project", archivedProjectID))
+		assert.NoError(t, service.ArchiveScope(ctx, "project", archivedProjectID))
+
+		// The archived role comes back on RestoreScope, so it still conflicts
+		err := service.Assign(ctx, archivedUser, "team_lead", "organization", orgID)
+		assert.True(t, IsConstraintViolation(err))
+	})
+
+	t.Run("Concurrent conflicting assignments", func(t *testing.T) {
+		racer := helper.CreateTestUser("racer")
+		errs := make(chan error, 2)
+		for _, role := range []string{"developer", "viewer"} {
+			go func() {
+				errs <- service.Assign(ctx, racer, role, "organization", orgID)
+			}()
+		}
+
+		var violations int
+		for range 2 {
+			if err := <-errs; err != nil {
+				assert.True(t, IsConstraintViolation(err))
+				violations++
+			}
+		}
+		assert.Equal(t, 1, violations)
+	})
+
+	t.Run("Report existing violations", func(t *testing.T) {
+		violations, err := service.FindConstraintViolations(ctx)
+		assert.NoError(t, err)
+		for _, v := range violations {
+			assert.NotEqual(t, userID, v.UserID)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package rolekit
 
 import (
 	"context"
+
+	"github.com/fernandezvara/dbkit"
 )
 
 // Context keys for RoleKit values.
@@ -14,6 +16,11 @@ const (
 	contextKeyUserAgent contextKey = "rolekit:user_agent"
 	contextKeyRequestID contextKey = "rolekit:request_id"
 	contextKeyChecker   contextKey = "rolekit:checker"
+	contextKeyTx        contextKey = "rolekit:tx"
+
+	contextKeyImpersonation contextKey = "rolekit:impersonation"
+	contextKeyCorrelationID contextKey = "rolekit:correlation_id"
+	contextKeyAuditMeta     contextKey = "rolekit:audit_metadata"
 )
 
 // WithUserID adds a user ID to the context.
@@ -130,6 +137,72 @@ func FromContext(ctx context.Context) *Checker {
 	return GetChecker(ctx)
 }
 
+// withTx adds the active transaction to the context.
+// Service methods called with this context run their queries inside the transaction.
+func withTx(ctx context.Context, tx *dbkit.Tx) context.Context {
+	return context.WithValue(ctx, contextKeyTx, tx)
+}
+
+// txFromContext retrieves the active transaction from context.
+// Returns nil if the context is not part of a transaction.
+func txFromContext(ctx context.Context) *dbkit.Tx {
+	if v := ctx.Value(contextKeyTx); v != nil {
+		if tx, ok := v.(*dbkit.Tx); ok {
+			return tx
+		}
+	}
+	return nil
+}
+
+// withImpersonation marks the context as an impersonation session.
+// Audit entries written with this context record the impersonated subject.
+func withImpersonation(ctx context.Context, imp *Impersonation) context.Context {
+	return context.WithValue(ctx, contextKeyImpersonation, imp)
+}
+
+// GetImpersonation retrieves the impersonation session from context.
+// Returns nil if the context is not impersonating anyone.
+func GetImpersonation(ctx context.Context) *Impersonation {
+	if v := ctx.Value(contextKeyImpersonation); v != nil {
+		if imp, ok := v.(*Impersonation); ok {
+			return imp
+		}
+	}
+	return nil
+}
+
+// withCorrelationID links the audit entries written with the context to one
+// logical operation (e.g. an access review or a reconciliation run).
+func withCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, contextKeyCorrelationID, correlationID)
+}
+
+// correlationIDFromContext retrieves the correlation ID from context.
+func correlationIDFromContext(ctx context.Context) string {
+	if v := ctx.Value(contextKeyCorrelationID); v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// withAuditMetadata adds metadata to the audit entries written with the
+// context (e.g. the source scope of a copy).
+func withAuditMetadata(ctx context.Context, metadata map[string]any) context.Context {
+	return context.WithValue(ctx, contextKeyAuditMeta, metadata)
+}
+
+// auditMetadataFromContext retrieves the audit metadata from context.
+func auditMetadataFromContext(ctx context.Context) map[string]any {
+	if v := ctx.Value(contextKeyAuditMeta); v != nil {
+		if m, ok := v.(map[string]any); ok {
+			return m
+		}
+	}
+	return nil
+}
+
 // AuditContext holds all audit-related information from context.
 type AuditContext struct {
 	ActorID   string
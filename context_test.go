@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/fernandezvara/dbkit"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -361,6 +362,50 @@ func TestContextKeyConstants(t *testing.T) {
 	assert.Equal(t, contextKey("rolekit:user_agent"), contextKeyUserAgent)
 	assert.Equal(t, contextKey("rolekit:request_id"), contextKeyRequestID)
 	assert.Equal(t, contextKey("rolekit:checker"), contextKeyChecker)
+	assert.Equal(t, contextKey("rolekit:tx"), contextKeyTx)
+	assert.Equal(t, contextKey("rolekit:impersonation"), contextKeyImpersonation)
+	assert.Equal(t, contextKey("rolekit:correlation_id"), contextKeyCorrelationID)
+	assert.Equal(t, contextKey("rolekit:audit_metadata"), contextKeyAuditMeta)
+}
+
+// TestCorrelationIDContext tests carrying a correlation ID in context
+func TestCorrelationIDContext(t *testing.T) {
+	assert.Empty(t, correlationIDFromContext(context.Background()))
+
+	ctx := withCorrelationID(context.Background(), "review-1")
+	assert.Equal(t, "review-1", correlationIDFromContext(ctx))
+}
+
+// TestAuditMetadataContext tests carrying audit metadata in context
+func TestAuditMetadataContext(t *testing.T) {
+	assert.Nil(t, auditMetadataFromContext(context.Background()))
+
+	ctx := withAuditMetadata(context.Background(), map[string]any{"copied_from_scope_id": "proj1"})
+	assert.Equal(t, "proj1", auditMetadataFromContext(ctx)["copied_from_scope_id"])
+}
+
+// TestImpersonationContext tests carrying an impersonation session in context
+func TestImpersonationContext(t *testing.T) {
+	assert.Nil(t, GetImpersonation(context.Background()))
+
+	imp := &Impersonation{ActorID: "support1", SubjectID: "customer1"}
+	ctx := withImpersonation(context.Background(), imp)
+	assert.Same(t, imp, GetImpersonation(ctx))
+
+	ctx = context.WithValue(context.Background(), contextKeyImpersonation, "not a session")
+	assert.Nil(t, GetImpersonation(ctx))
+}
+
+// TestTxContext tests carrying a transaction in context
+func TestTxContext(t *testing.T) {
+	assert.Nil(t, txFromContext(context.Background()))
+
+	tx := &dbkit.Tx{}
+	ctx := withTx(context.Background(), tx)
+	assert.Same(t, tx, txFromContext(ctx))
+
+	ctx = context.WithValue(context.Background(), contextKeyTx, "not a tx")
+	assert.Nil(t, txFromContext(ctx))
 }
 
 // TestContextChaining tests chaining multiple context operations
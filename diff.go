@@ -0,0 +1,242 @@
+package rolekit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// REGISTRY DIFF
+// ============================================================================
+
+// RegistryDiff describes the changes from one registry to another.
+type RegistryDiff struct {
+	AddedScopes   []string     // Scope types only in the new registry
+	RemovedScopes []string     // Scope types only in the old registry
+	AddedRoles    []RoleRef    // Roles only in the new registry, including those of added scopes
+	RemovedRoles  []RoleRef    // Roles only in the old registry, including those of removed scopes
+	ChangedRoles  []RoleChange // Roles in both registries with different permission patterns or conditions
+}
+
+// RoleChange describes the permission patterns added to and removed from a
+// role, and the conditions (see RoleDefinition.When) changed on its patterns.
+type RoleChange struct {
+	Role               RoleRef
+	AddedPermissions   []string
+	RemovedPermissions []string
+	ConditionChanges   []ConditionChange
+}
+
+// ConditionChange describes the conditions added to and removed from one
+// permission pattern of a role, by expression.
+type ConditionChange struct {
+	Permission        string
+	AddedConditions   []string
+	RemovedConditions []string
+}
+
+// IsEmpty reports whether both registries define the same scopes, roles,
+// permission patterns and conditions.
+func (d *RegistryDiff) IsEmpty() bool {
+	return len(d.AddedScopes) == 0 && len(d.RemovedScopes) == 0 &&
+		len(d.AddedRoles) == 0 && len(d.RemovedRoles) == 0 && len(d.ChangedRoles) == 0
+}
+
+// String returns the diff with one change per line, for review.
+//
+// Example:
+//
+//	fmt.Print(current.Diff(proposed))
+//	// + scope team
+//	// - role project/guest
+//	// ~ role project/editor: +files.delete -files.share
+//	// ~ role organization/accountant: invoices.approve +when(amount < 10000)
+func (d *RegistryDiff) String() string {
+	var b strings.Builder
+	for _, scope := range d.AddedScopes {
+		fmt.Fprintf(&b, "+ scope %s\n", scope)
+	}
+	for _, scope := range d.RemovedScopes {
+		fmt.Fprintf(&b, "- scope %s\n", scope)
+	}
+	for _, role := range d.AddedRoles {
+		fmt.Fprintf(&b, "+ role %s\n", role)
+	}
+	for _, role := range d.RemovedRoles {
+		fmt.Fprintf(&b, "- role %s\n", role)
+	}
+	for _, change := range d.ChangedRoles {
+		fmt.Fprintf(&b, "~ role %s:%s%s\n", change.Role,
+			formatPermissionChanges(change.AddedPermissions, change.RemovedPermissions),
+			formatConditionChanges(change.ConditionChanges))
+	}
+	return b.String()
+}
+
+// changedRoles returns the roles of the old registry whose permissions or
+// conditions differ in the new one, including removed roles.
+func (d *RegistryDiff) changedRoles() []RoleRef {
+	roles := append([]RoleRef(nil), d.RemovedRoles...)
+	for _, change := range d.ChangedRoles {
+		roles = append(roles, change.Role)
+	}
+	return roles
+}
+
+// Diff compares the registry with a new version of it and returns the added
+// and removed scopes and roles, and the permission patterns added to and
+// removed from the roles defined in both, along with the conditions changed on
+// their patterns. Patterns and condition expressions are compared literally:
+// replacing "files.read" and "files.write" with "files.*" is reported as a
+// change. Use Service.ImpactOf to see which users it affects.
+//
+// Example:
+//
+//	diff := current.Diff(proposed)
+//	fmt.Print(diff)
+//	// ~ role project/editor: +files.delete -files.share
+func (r *Registry) Diff(other *Registry) *RegistryDiff {
+	diff := &RegistryDiff{}
+
+	oldScopes := r.GetScopes()
+	newScopes := other.GetScopes()
+	diff.AddedScopes = subtractStrings(newScopes, oldScopes)
+	diff.RemovedScopes = subtractStrings(oldScopes, newScopes)
+
+	for _, scopeName := range sortedStrings(unionStrings(oldScopes, newScopes)) {
+		var oldRoles, newRoles []string
+		if scope := r.GetScope(scopeName); scope != nil {
+			oldRoles = scope.GetRoles()
+		}
+		if scope := other.GetScope(scopeName); scope != nil {
+			newRoles = scope.GetRoles()
+		}
+
+		for _, role := range subtractStrings(newRoles, oldRoles) {
+			diff.AddedRoles = append(diff.AddedRoles, NewRoleRef(scopeName, role))
+		}
+		for _, role := range subtractStrings(oldRoles, newRoles) {
+			diff.RemovedRoles = append(diff.RemovedRoles, NewRoleRef(scopeName, role))
+		}
+		for _, role := range sortedStrings(intersectStrings(oldRoles, newRoles)) {
+			oldPerms := r.GetPermissions(role, scopeName)
+			newPerms := other.GetPermissions(role, scopeName)
+			added := subtractStrings(newPerms, oldPerms)
+			removed := subtractStrings(oldPerms, newPerms)
+			conditions := diffConditions(r.GetRole(role, scopeName), other.GetRole(role, scopeName))
+			if len(added) > 0 || len(removed) > 0 || len(conditions) > 0 {
+				diff.ChangedRoles = append(diff.ChangedRoles, RoleChange{
+					Role:               NewRoleRef(scopeName, role),
+					AddedPermissions:   added,
+					RemovedPermissions: removed,
+					ConditionChanges:   conditions,
+				})
+			}
+		}
+	}
+
+	return diff
+}
+
+// diffConditions compares the conditions of two versions of a role, per
+// permission pattern.
+func diffConditions(old, next *RoleDefinition) []ConditionChange {
+	var changes []ConditionChange
+	for _, permission := range sortedStrings(unionStrings(mapKeys(old.conditions), mapKeys(next.conditions))) {
+		oldExprs := conditionExpressions(old.conditions[permission])
+		newExprs := conditionExpressions(next.conditions[permission])
+		added := subtractStrings(newExprs, oldExprs)
+		removed := subtractStrings(oldExprs, newExprs)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, ConditionChange{
+				Permission:        permission,
+				AddedConditions:   added,
+				RemovedConditions: removed,
+			})
+		}
+	}
+	return changes
+}
+
+// conditionExpressions returns the expressions of conditions.
+func conditionExpressions(conditions []*Condition) []string {
+	exprs := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		exprs = append(exprs, cond.String())
+	}
+	return exprs
+}
+
+// formatConditionChanges formats condition changes as
+// " permission +when(added) -when(removed)".
+func formatConditionChanges(changes []ConditionChange) string {
+	var b strings.Builder
+	for _, change := range changes {
+		b.WriteString(" " + change.Permission)
+		for _, expr := range change.AddedConditions {
+			b.WriteString(" +when(" + expr + ")")
+		}
+		for _, expr := range change.RemovedConditions {
+			b.WriteString(" -when(" + expr + ")")
+		}
+	}
+	return b.String()
+}
+
+// formatPermissionChanges formats added and removed permissions as
+// " +added -removed".
+func formatPermissionChanges(added, removed []string) string {
+	var b strings.Builder
+	for _, p := range added {
+		b.WriteString(" +" + p)
+	}
+	for _, p := range removed {
+		b.WriteString(" -" + p)
+	}
+	return b.String()
+}
+
+// subtractStrings returns the distinct values of a missing from b, sorted.
+func subtractStrings(a, b []string) []string {
+	exclude := make(map[string]bool, len(b))
+	for _, v := range b {
+		exclude[v] = true
+	}
+	set := make(map[string]bool)
+	for _, v := range a {
+		if !exclude[v] {
+			set[v] = true
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return sortedStrings(mapKeys(set))
+}
+
+// intersectStrings returns the distinct values present in both a and b.
+func intersectStrings(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, v := range b {
+		in[v] = true
+	}
+	set := make(map[string]bool)
+	for _, v := range a {
+		if in[v] {
+			set[v] = true
+		}
+	}
+	return mapKeys(set)
+}
+
+// unionStrings returns the distinct values present in a or b.
+func unionStrings(a, b []string) []string {
+	set := make(map[string]bool, len(a)+len(b))
+	for _, v := range a {
+		set[v] = true
+	}
+	for _, v := range b {
+		set[v] = true
+	}
+	return mapKeys(set)
+}
@@ -0,0 +1,86 @@
+package rolekit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryDiff(t *testing.T) {
+	current := NewRegistry()
+	current.DefineScope("organization").
+		Role("admin").Permissions("*").
+		Role("member").Permissions("files.read")
+	current.DefineScope("project").
+		Role("editor").Permissions("files.read", "files.share").
+		Role("guest").Permissions("files.read")
+	current.DefineScope("legacy").
+		Role("owner").Permissions("*")
+
+	proposed := NewRegistry()
+	proposed.DefineScope("organization").
+		Role("admin").Permissions("*").
+		Role("member").Permissions("files.read")
+	proposed.DefineScope("project").
+		Role("editor").Permissions("files.read", "files.delete").
+		Role("auditor").Permissions("audit.read")
+	proposed.DefineScope("team").
+		Role("lead").Permissions("team.*")
+
+	diff := current.Diff(proposed)
+
+	assert.Equal(t, []string{"team"}, diff.AddedScopes)
+	assert.Equal(t, []string{"legacy"}, diff.RemovedScopes)
+	assert.Equal(t, []RoleRef{NewRoleRef("project", "auditor"), NewRoleRef("team", "lead")}, diff.AddedRoles)
+	assert.Equal(t, []RoleRef{NewRoleRef("legacy", "owner"), NewRoleRef("project", "guest")}, diff.RemovedRoles)
+	require.Len(t, diff.ChangedRoles, 1)
+	assert.Equal(t, RoleChange{
+		Role:               NewRoleRef("project", "editor"),
+		AddedPermissions:   []string{"files.delete"},
+		RemovedPermissions: []string{"files.share"},
+	}, diff.ChangedRoles[0])
+	assert.False(t, diff.IsEmpty())
+
+	assert.Equal(t, "+ scope team\n"+
+		"- scope legacy\n"+
+		"+ role project/auditor\n"+
+		"+ role team/lead\n"+
+		"- role legacy/owner\n"+
+		"- role project/guest\n"+
+		"~ role project/editor: +files.delete -files.share\n", diff.String())
+
+	t.Run("Identical registries", func(t *testing.T) {
+		diff := current.Diff(current)
+		assert.True(t, diff.IsEmpty())
+		assert.Empty(t, diff.String())
+	})
+
+	t.Run("Condition changes", func(t *testing.T) {
+		before := NewRegistry()
+		before.DefineScope("organization").
+			Role("accountant").Permissions("invoices.read", "invoices.approve").
+			When("invoices.read", "amount < 500")
+		after := NewRegistry()
+		after.DefineScope("organization").
+			Role("accountant").Permissions("invoices.read", "invoices.approve").
+			When("invoices.approve", "amount < 10000")
+
+		diff := before.Diff(after)
+		require.Len(t, diff.ChangedRoles, 1)
+		assert.Equal(t, []ConditionChange{
+			{Permission: "invoices.approve", AddedConditions: []string{"amount < 10000"}},
+			{Permission: "invoices.read", RemovedConditions: []string{"amount < 500"}},
+		}, diff.ChangedRoles[0].ConditionChanges)
+		assert.Empty(t, diff.ChangedRoles[0].AddedPermissions)
+		assert.Equal(t, "~ role organization/accountant: invoices.approve +when(amount < 10000) invoices.read -when(amount < 500)\n", diff.String())
+	})
+
+	t.Run("Pattern order and duplicates are ignored", func(t *testing.T) {
+		reordered := NewRegistry()
+		reordered.DefineScope("project").Role("editor").Permissions("files.share", "files.read", "files.read")
+		original := NewRegistry()
+		original.DefineScope("project").Role("editor").Permissions("files.read", "files.share")
+		assert.True(t, original.Diff(reordered).IsEmpty())
+	})
+}
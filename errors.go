@@ -36,6 +36,61 @@ var (
 
 	// ErrDatabaseError is returned when a database operation fails.
 	ErrDatabaseError = errors.New("rolekit: database error")
+
+	// ErrConstraintViolation is returned when an assignment would break a separation-of-duties constraint.
+	ErrConstraintViolation = errors.New("rolekit: constraint violation")
+
+	// ErrPrivilegeEscalation is returned when a role would grant permissions the actor does not hold.
+	// It wraps ErrCannotAssign, so IsCannotAssign also reports true.
+	ErrPrivilegeEscalation = fmt.Errorf("%w: privilege escalation", ErrCannotAssign)
+
+	// ErrCardinalityViolation is returned when an operation would break a role's MinMembers or MaxMembers limit.
+	ErrCardinalityViolation = errors.New("rolekit: role cardinality violation")
+
+	// ErrAccessRequestNotFound is returned when an access request does not exist.
+	ErrAccessRequestNotFound = errors.New("rolekit: access request not found")
+
+	// ErrInvalidAccessRequest is returned when an access request is malformed or no longer pending.
+	ErrInvalidAccessRequest = errors.New("rolekit: invalid access request")
+
+	// ErrBreakGlassDenied is returned when emergency access is disabled, the user is not a designated principal, or no reason is given.
+	ErrBreakGlassDenied = errors.New("rolekit: break-glass access denied")
+
+	// ErrInvitationNotFound is returned when an invitation or its token does not exist.
+	ErrInvitationNotFound = errors.New("rolekit: invitation not found")
+
+	// ErrInvalidInvitation is returned when an invitation has expired, was already accepted, or was revoked.
+	ErrInvalidInvitation = errors.New("rolekit: invalid invitation")
+
+	// ErrCannotDelegate is returned when a delegation is not allowed (permission not held, invalid window, ...).
+	ErrCannotDelegate = errors.New("rolekit: cannot delegate permission")
+
+	// ErrDelegationNotFound is returned when a delegation does not exist.
+	ErrDelegationNotFound = errors.New("rolekit: delegation not found")
+
+	// ErrReviewNotFound is returned when an access review or review item does not exist.
+	ErrReviewNotFound = errors.New("rolekit: access review not found")
+
+	// ErrInvalidReview is returned when an access review is malformed or already closed.
+	ErrInvalidReview = errors.New("rolekit: invalid access review")
+
+	// ErrInvalidImport is returned when an import contains invalid or conflicting records.
+	ErrInvalidImport = errors.New("rolekit: invalid import")
+
+	// ErrResourceGrantNotFound is returned when a user has no grant on a resource.
+	ErrResourceGrantNotFound = errors.New("rolekit: resource grant not found")
+
+	// ErrInvalidTuple is returned when a relation tuple is malformed or refers to an undefined namespace or relation.
+	ErrInvalidTuple = errors.New("rolekit: invalid relation tuple")
+
+	// ErrTupleDepthExceeded is returned when evaluating a relation recurses deeper than MaxTupleDepth.
+	ErrTupleDepthExceeded = errors.New("rolekit: relation tuple depth exceeded")
+
+	// ErrInvalidCondition is returned when a permission condition does not compile.
+	ErrInvalidCondition = errors.New("rolekit: invalid permission condition")
+
+	// ErrUnknownPermission is returned when a permission is not in the registry's catalog.
+	ErrUnknownPermission = errors.New("rolekit: unknown permission")
 )
 
 // Error wraps a sentinel error with additional context.
@@ -119,3 +174,73 @@ func IsInvalidRole(err error) bool {
 func IsCannotAssign(err error) bool {
 	return errors.Is(err, ErrCannotAssign)
 }
+
+// IsConstraintViolation checks if an error is due to a separation-of-duties constraint.
+func IsConstraintViolation(err error) bool {
+	return errors.Is(err, ErrConstraintViolation)
+}
+
+// IsCardinalityViolation checks if an error is due to a role's member limits.
+func IsCardinalityViolation(err error) bool {
+	return errors.Is(err, ErrCardinalityViolation)
+}
+
+// IsAccessRequestNotFound checks if an error is due to a missing access request.
+func IsAccessRequestNotFound(err error) bool {
+	return errors.Is(err, ErrAccessRequestNotFound)
+}
+
+// IsInvalidAccessRequest checks if an error is due to a malformed or already decided access request.
+func IsInvalidAccessRequest(err error) bool {
+	return errors.Is(err, ErrInvalidAccessRequest)
+}
+
+// IsBreakGlassDenied checks if an error is due to a refused break-glass request.
+func IsBreakGlassDenied(err error) bool {
+	return errors.Is(err, ErrBreakGlassDenied)
+}
+
+// IsInvitationNotFound checks if an error is due to a missing invitation.
+func IsInvitationNotFound(err error) bool {
+	return errors.Is(err, ErrInvitationNotFound)
+}
+
+// IsInvalidInvitation checks if an error is due to an expired, used or revoked invitation.
+func IsInvalidInvitation(err error) bool {
+	return errors.Is(err, ErrInvalidInvitation)
+}
+
+// IsCannotDelegate checks if an error is due to a refused delegation.
+func IsCannotDelegate(err error) bool {
+	return errors.Is(err, ErrCannotDelegate)
+}
+
+// IsInvalidReview checks if an error is due to a malformed or closed access review.
+func IsInvalidReview(err error) bool {
+	return errors.Is(err, ErrInvalidReview)
+}
+
+// IsInvalidImport checks if an error is due to an invalid assignment import.
+func IsInvalidImport(err error) bool {
+	return errors.Is(err, ErrInvalidImport)
+}
+
+// IsInvalidTuple checks if an error is due to a malformed or undefined relation tuple.
+func IsInvalidTuple(err error) bool {
+	return errors.Is(err, ErrInvalidTuple)
+}
+
+// IsInvalidCondition checks if an error is due to a permission condition that does not compile.
+func IsInvalidCondition(err error) bool {
+	return errors.Is(err, ErrInvalidCondition)
+}
+
+// IsUnknownPermission checks if an error is due to a permission missing from the catalog.
+func IsUnknownPermission(err error) bool {
+	return errors.Is(err, ErrUnknownPermission)
+}
+
+// IsPrivilegeEscalation checks if an error is due to the "no escalation" policy.
+func IsPrivilegeEscalation(err error) bool {
+	return errors.Is(err, ErrPrivilegeEscalation)
+}
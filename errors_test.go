@@ -24,6 +24,18 @@ func TestSentinelErrors(t *testing.T) {
 		{"ErrNoUserID", ErrNoUserID, "rolekit: no user ID in context"},
 		{"ErrNoActorID", ErrNoActorID, "rolekit: no actor ID in context"},
 		{"ErrDatabaseError", ErrDatabaseError, "rolekit: database error"},
+		{"ErrConstraintViolation", ErrConstraintViolation, "rolekit: constraint violation"},
+		{"ErrAccessRequestNotFound", ErrAccessRequestNotFound, "rolekit: access request not found"},
+		{"ErrInvalidAccessRequest", ErrInvalidAccessRequest, "rolekit: invalid access request"},
+		{"ErrBreakGlassDenied", ErrBreakGlassDenied, "rolekit: break-glass access denied"},
+		{"ErrInvitationNotFound", ErrInvitationNotFound, "rolekit: invitation not found"},
+		{"ErrInvalidInvitation", ErrInvalidInvitation, "rolekit: invalid invitation"},
+		{"ErrCannotDelegate", ErrCannotDelegate, "rolekit: cannot delegate permission"},
+		{"ErrDelegationNotFound", ErrDelegationNotFound, "rolekit: delegation not found"},
+		{"ErrReviewNotFound", ErrReviewNotFound, "rolekit: access review not found"},
+		{"ErrInvalidReview", ErrInvalidReview, "rolekit: invalid access review"},
+		{"ErrInvalidImport", ErrInvalidImport, "rolekit: invalid import"},
+		{"ErrResourceGrantNotFound", ErrResourceGrantNotFound, "rolekit: resource grant not found"},
 	}
 
 	for _, tt := range tests {
@@ -239,6 +251,60 @@ func TestIsCannotAssign(t *testing.T) {
 	})
 }
 
+// TestIsConstraintViolation tests checking for separation-of-duties errors
+func TestIsConstraintViolation(t *testing.T) {
+	assert.True(t, IsConstraintViolation(ErrConstraintViolation))
+	assert.True(t, IsConstraintViolation(NewError(ErrConstraintViolation, "conflicting roles")))
+	assert.False(t, IsConstraintViolation(ErrCannotAssign))
+	assert.False(t, IsConstraintViolation(nil))
+}
+
+// TestIsAccessRequestErrors tests checking for access request errors
+func TestIsAccessRequestErrors(t *testing.T) {
+	assert.True(t, IsAccessRequestNotFound(NewError(ErrAccessRequestNotFound, "no such request")))
+	assert.False(t, IsAccessRequestNotFound(ErrInvalidAccessRequest))
+	assert.True(t, IsInvalidAccessRequest(NewError(ErrInvalidAccessRequest, "request is not pending")))
+	assert.False(t, IsInvalidAccessRequest(nil))
+}
+
+// TestIsInvitationErrors tests checking for invitation errors
+func TestIsInvitationErrors(t *testing.T) {
+	assert.True(t, IsInvitationNotFound(NewError(ErrInvitationNotFound, "unknown token")))
+	assert.False(t, IsInvitationNotFound(ErrInvalidInvitation))
+	assert.True(t, IsInvalidInvitation(NewError(ErrInvalidInvitation, "invitation has expired")))
+	assert.False(t, IsInvalidInvitation(nil))
+}
+
+// TestIsCannotDelegate tests checking for delegation errors
+func TestIsCannotDelegate(t *testing.T) {
+	assert.True(t, IsCannotDelegate(NewError(ErrCannotDelegate, "permission not held")))
+	assert.False(t, IsCannotDelegate(ErrCannotAssign))
+	assert.False(t, IsCannotDelegate(nil))
+}
+
+// TestIsInvalidReview tests checking for access review errors
+func TestIsInvalidReview(t *testing.T) {
+	assert.True(t, IsInvalidReview(NewError(ErrInvalidReview, "review is closed")))
+	assert.False(t, IsInvalidReview(ErrReviewNotFound))
+	assert.False(t, IsInvalidReview(nil))
+}
+
+// TestIsInvalidImport tests checking for import errors
+func TestIsInvalidImport(t *testing.T) {
+	assert.True(t, IsInvalidImport(NewError(ErrInvalidImport, "2 invalid records")))
+	assert.False(t, IsInvalidImport(ErrInvalidRole))
+	assert.False(t, IsInvalidImport(nil))
+}
+
+// TestIsPrivilegeEscalation tests checking for escalation errors
+func TestIsPrivilegeEscalation(t *testing.T) {
+	err := NewError(ErrPrivilegeEscalation, "role grants more permissions")
+	assert.True(t, IsPrivilegeEscalation(err))
+	assert.True(t, IsCannotAssign(err))
+	assert.False(t, IsPrivilegeEscalation(ErrCannotAssign))
+	assert.Equal(t, "rolekit: cannot assign role: privilege escalation", ErrPrivilegeEscalation.Error())
+}
+
 // TestError_EdgeCases tests edge cases and special values
 func TestError_EdgeCases(t *testing.T) {
 	t.Run("Empty strings in fields", func(t *testing.T) {
@@ -0,0 +1,165 @@
+package rolekit
+
+import (
+	"context"
+	"sort"
+)
+
+// EscalationRisk describes an assignment rule that lets a role hand out
+// permissions it does not hold itself.
+type EscalationRisk struct {
+	Assigner    RoleRef  // Role that can assign
+	Target      RoleRef  // Role that can be assigned
+	Permissions []string // Target permission patterns not covered by the assigner
+}
+
+// PreventEscalation enables the "no escalation" policy: Assign rejects a role
+// whose permissions are not a subset of the permissions the actor holds in the
+// target scope (or its ancestors), even when CanAssign allows it.
+//
+// Example:
+//
+//	registry := rolekit.NewRegistry().PreventEscalation()
+func (r *Registry) PreventEscalation() *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.noEscalation = true
+	return r
+}
+
+// IsEscalationPrevented reports whether the "no escalation" policy is enabled.
+func (r *Registry) IsEscalationPrevented() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.noEscalation
+}
+
+// EscalationReport lists every CanAssign and CanAssignIn rule where the target
+// role grants permissions the assigning role does not hold unconditionally.
+// Review it at startup (or in tests) to find roles that could escalate
+// privileges.
+//
+// Example:
+//
+//	for _, risk := range registry.EscalationReport() {
+//	    log.Printf("%s can grant %s: %v", risk.Assigner, risk.Target, risk.Permissions)
+//	}
+func (r *Registry) EscalationReport() []EscalationRisk {
+	var risks []EscalationRisk
+
+	for _, scopeName := range sortedStrings(r.GetScopes()) {
+		scope := r.GetScope(scopeName)
+		for _, roleName := range sortedStrings(scope.GetRoles()) {
+			assigner := scope.GetRole(roleName)
+
+			targets := map[string][]string{scopeName: assigner.GetCanAssign()}
+			for targetScope, roles := range assigner.canAssignIn {
+				targets[targetScope] = append(targets[targetScope], roles...)
+			}
+
+			for _, targetScopeName := range sortedStrings(mapKeys(targets)) {
+				targetScope := r.GetScope(targetScopeName)
+				if targetScope == nil {
+					continue
+				}
+				for _, targetRole := range expandRoleNames(targetScope, targets[targetScopeName]) {
+					missing := uncoveredPermissions(assigner.unconditionalPermissions(),
+						r.GetPermissions(targetRole, targetScopeName))
+					if len(missing) > 0 {
+						risks = append(risks, EscalationRisk{
+							Assigner:    NewRoleRef(scopeName, roleName),
+							Target:      NewRoleRef(targetScopeName, targetRole),
+							Permissions: missing,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return risks
+}
+
+// EscalatingPermissions returns the permission patterns of a role that the
+// user does not hold in the scope. An empty result means assigning the role
+// would not escalate privileges. Conditional permissions of the user's roles
+// (see RoleDefinition.When) do not count as held, since they may not be
+// granted when the role is used. Use EscalatingPermissionsContext to also
+// count the permissions held in the scope's ancestors.
+//
+// Example:
+//
+//	if missing := checker.EscalatingPermissions("owner", "organization", orgID); len(missing) > 0 {
+//	    // Assigning "owner" would grant permissions the user does not have
+//	}
+func (c *Checker) EscalatingPermissions(targetRole, scopeType, scopeID string) []string {
+	return c.escalatingPermissions(targetRole, scopeType, scopeID, nil)
+}
+
+// EscalatingPermissionsContext returns the permission patterns of a role that
+// the user holds neither in the scope nor in its ancestors, which are looked
+// up with ctx.
+func (c *Checker) EscalatingPermissionsContext(ctx context.Context, targetRole, scopeType, scopeID string) ([]string, error) {
+	ancestors, err := c.scopeAncestors(ctx, scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	return c.escalatingPermissions(targetRole, scopeType, scopeID, ancestors), nil
+}
+
+func (c *Checker) escalatingPermissions(targetRole, scopeType, scopeID string, ancestors []Scope) []string {
+	held := c.unconditionalPermissions(scopeType, scopeID)
+	for _, ancestor := range ancestors {
+		held = append(held, c.unconditionalPermissions(ancestor.Type, ancestor.ID)...)
+	}
+	return uncoveredPermissions(held, c.registry.GetPermissions(targetRole, scopeType))
+}
+
+// uncoveredPermissions returns the patterns that no held pattern covers.
+// A held pattern covers another when it matches it literally, so "files.*"
+// covers "files.read" and "files.*" but not "*". A "*" segment never covers a
+// "**" segment: "files.*" does not cover "files.**", while "files.**" covers both.
+func uncoveredPermissions(held, patterns []string) []string {
+	var missing []string
+	for _, pattern := range patterns {
+		covered := false
+		for _, h := range held {
+			if DefaultMatcher.Covers(h, pattern) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, pattern)
+		}
+	}
+	return missing
+}
+
+// expandRoleNames resolves "*" to every role of the scope, in stable order.
+func expandRoleNames(scope *ScopeDefinition, roles []string) []string {
+	set := make(map[string]bool)
+	for _, role := range roles {
+		if role == "*" {
+			for _, r := range scope.GetRoles() {
+				set[r] = true
+			}
+		} else if scope.GetRole(role) != nil {
+			set[role] = true
+		}
+	}
+	return sortedStrings(mapKeys(set))
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func sortedStrings(values []string) []string {
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,144 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func escalationTestRegistry() *Registry {
+	r := NewRegistry().PreventEscalation()
+	r.DefineScope("organization").
+		Role("owner").Permissions("*").CanAssign("*").
+		Role("admin").Permissions("members.*", "settings.read").CanAssign("*").
+		Role("member").Permissions("members.read").
+		Role("billing").Permissions("billing.*")
+	return r
+}
+
+// TestRegistryPreventEscalation tests enabling the no-escalation policy
+func TestRegistryPreventEscalation(t *testing.T) {
+	assert.False(t, NewRegistry().IsEscalationPrevented())
+	assert.True(t, escalationTestRegistry().IsEscalationPrevented())
+}
+
+// TestRegistryEscalationReport tests reporting roles that could escalate privileges
+func TestRegistryEscalationReport(t *testing.T) {
+	risks := escalationTestRegistry().EscalationReport()
+
+	// Only admin escalates: it can assign owner ("*") and billing ("billing.*")
+	assert.Len(t, risks, 2)
+	for _, risk := range risks {
+		assert.Equal(t, NewRoleRef("organization", "admin"), risk.Assigner)
+	}
+	assert.Equal(t, NewRoleRef("organization", "billing"), risks[0].Target)
+	assert.Equal(t, []string{"billing.*"}, risks[0].Permissions)
+	assert.Equal(t, NewRoleRef("organization", "owner"), risks[1].Target)
+	assert.Equal(t, []string{"*"}, risks[1].Permissions)
+}
+
+// TestRegistryEscalationReportCrossScope tests reporting CanAssignIn rules
+func TestRegistryEscalationReportCrossScope(t *testing.T) {
+	r := NewRegistry()
+	r.DefineScope("organization").
+		Role("admin").Permissions("files.read").CanAssignIn("project", "editor", "viewer")
+	r.DefineScope("project").ParentScope("organization").
+		Role("editor").Permissions("files.*").
+		Role("viewer").Permissions("files.read")
+
+	risks := r.EscalationReport()
+	assert.Len(t, risks, 1)
+	assert.Equal(t, NewRoleRef("project", "editor"), risks[0].Target)
+}
+
+// TestCheckerEscalatingPermissions tests comparing a role against the user's permissions
+func TestCheckerEscalatingPermissions(t *testing.T) {
+	registry := escalationTestRegistry()
+	assignments := []RoleAssignment{
+		{UserID: "admin1", Role: "admin", ScopeType: "organization", ScopeID: "org1"},
+	}
+	checker := NewChecker("admin1", NewUserRoles("admin1", assignments), registry, &Service{registry: registry})
+
+	assert.Empty(t, checker.EscalatingPermissions("member", "organization", "org1"))
+	assert.Empty(t, checker.EscalatingPermissions("admin", "organization", "org1"))
+	assert.Equal(t, []string{"*"}, checker.EscalatingPermissions("owner", "organization", "org1"))
+	assert.Equal(t, []string{"billing.*"}, checker.EscalatingPermissions("billing", "organization", "org1"))
+
+	// No permissions in another organization
+	assert.Equal(t, []string{"members.read"}, checker.EscalatingPermissions("member", "organization", "org2"))
+}
+
+// TestCheckerEscalatingPermissionsConditional tests that conditional permissions do not count as held
+func TestCheckerEscalatingPermissionsConditional(t *testing.T) {
+	registry := NewRegistry().PreventEscalation()
+	registry.DefineScope("organization").
+		Role("approver").Permissions("invoices.*").When("invoices.*", "amount < 10000").CanAssign("*").
+		Role("clerk").Permissions("invoices.approve")
+	assignments := []RoleAssignment{
+		{UserID: "approver1", Role: "approver", ScopeType: "organization", ScopeID: "org1"},
+	}
+	checker := NewChecker("approver1", NewUserRoles("approver1", assignments), registry, &Service{registry: registry})
+
+	assert.Equal(t, []string{"invoices.approve"}, checker.EscalatingPermissions("clerk", "organization", "org1"))
+	assert.Empty(t, checker.ExpandPermissions("organization", "org1"))
+
+	risks := registry.EscalationReport()
+	require.Len(t, risks, 2)
+	assert.Equal(t, NewRoleRef("organization", "clerk"), risks[1].Target)
+}
+
+// TestUncoveredPermissions tests the pattern subset relation
+func TestUncoveredPermissions(t *testing.T) {
+	assert.Empty(t, uncoveredPermissions([]string{"*"}, []string{"*", "files.read"}))
+	assert.Empty(t, uncoveredPermissions([]string{"files.*"}, []string{"files.*", "files.read"}))
+	assert.Equal(t, []string{"*"}, uncoveredPermissions([]string{"files.*"}, []string{"*"}))
+	assert.Equal(t, []string{"files.*"}, uncoveredPermissions([]string{"files.read"}, []string{"files.*"}))
+	assert.Equal(t, []string{"files.*"}, uncoveredPermissions([]string{"*.read"}, []string{"files.*"}))
+	assert.Empty(t, uncoveredPermissions([]string{"files.**"}, []string{"files.*", "files.versions.restore"}))
+	assert.Equal(t, []string{"files.**"}, uncoveredPermissions([]string{"files.*"}, []string{"files.**"}))
+	assert.Empty(t, uncoveredPermissions(nil, nil))
+}
+
+// TestServiceCheckEscalation tests the assignment guard
+func TestServiceCheckEscalation(t *testing.T) {
+	registry := escalationTestRegistry()
+	service := &Service{registry: registry}
+	assignments := []RoleAssignment{
+		{UserID: "admin1", Role: "admin", ScopeType: "organization", ScopeID: "org1"},
+	}
+	checker := NewChecker("admin1", NewUserRoles("admin1", assignments), registry, service)
+
+	assert.NoError(t, service.checkEscalation(context.Background(), checker, "member", "organization", "org1"))
+
+	err := service.checkEscalation(context.Background(), checker, "owner", "organization", "org1")
+	assert.True(t, IsPrivilegeEscalation(err))
+	assert.True(t, IsCannotAssign(err))
+
+	// Policy disabled
+	open := &Service{registry: NewRegistry()}
+	assert.NoError(t, open.checkEscalation(context.Background(), checker, "owner", "organization", "org1"))
+}
+
+// TestServiceAssignSelfEscalation tests that the policy covers self-assignment
+func TestServiceAssignSelfEscalation(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := NewService(escalationTestRegistry(), helper.GetService().db)
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	ctx := WithActorID(helper.GetContext(), adminID)
+
+	// Roles covered by the user's own permissions can be self-assigned
+	require.NoError(t, service.AssignDirect(ctx, adminID, "admin", "organization", orgID))
+	require.NoError(t, service.Assign(ctx, adminID, "member", "organization", orgID))
+
+	err := service.Assign(ctx, adminID, "owner", "organization", orgID)
+	assert.True(t, IsPrivilegeEscalation(err))
+	helper.AssertRoleNotAssigned(adminID, "owner", "organization", orgID)
+}
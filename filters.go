@@ -1,6 +1,54 @@
 package rolekit
 
-import "time"
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// AssignmentFilter selects role assignments by scope and role, e.g. the
+// assignments covered by an access review or a reconciliation.
+type AssignmentFilter struct {
+	ScopeType string   // Required
+	ScopeID   string   // Optional: a single scope instance
+	Roles     []string // Optional: only these roles
+}
+
+// Matches reports whether an assignment is selected by the filter.
+func (f AssignmentFilter) Matches(a RoleAssignment) bool {
+	if a.ScopeType != f.ScopeType {
+		return false
+	}
+	if f.ScopeID != "" && a.ScopeID != f.ScopeID {
+		return false
+	}
+	return len(f.Roles) == 0 || containsString(f.Roles, a.Role)
+}
+
+// validate checks the filter's scope type and roles against the registry.
+func (f AssignmentFilter) validate(registry *Registry) error {
+	if err := registry.ValidateScope(f.ScopeType); err != nil {
+		return err
+	}
+	for _, role := range f.Roles {
+		if err := registry.ValidateRole(role, f.ScopeType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply adds the filter's conditions to a role_assignments query.
+func (f AssignmentFilter) apply(q *bun.SelectQuery) *bun.SelectQuery {
+	q = q.Where("scope_type = ?", f.ScopeType)
+	if f.ScopeID != "" {
+		q = q.Where("scope_id = ?", f.ScopeID)
+	}
+	if len(f.Roles) > 0 {
+		q = q.Where("role IN (?)", bun.In(f.Roles))
+	}
+	return q
+}
 
 // AuditLogFilter provides options for filtering audit log queries.
 type AuditLogFilter struct {
@@ -22,6 +70,15 @@ type AuditLogFilter struct {
 	// Filter by role
 	Role string
 
+	// Filter by correlation ID (entries written by one logical operation)
+	CorrelationID string
+
+	// Filter by severity (e.g. "high")
+	Severity string
+
+	// Filter by impersonated user (entries written while impersonating them)
+	ImpersonatedUserID string
+
 	// Filter by time range
 	Since time.Time
 	Until time.Time
@@ -75,6 +132,24 @@ func (f AuditLogFilter) WithRole(role string) AuditLogFilter {
 	return f
 }
 
+// WithCorrelationID sets the correlation ID filter.
+func (f AuditLogFilter) WithCorrelationID(correlationID string) AuditLogFilter {
+	f.CorrelationID = correlationID
+	return f
+}
+
+// WithSeverity sets the severity filter.
+func (f AuditLogFilter) WithSeverity(severity AuditSeverity) AuditLogFilter {
+	f.Severity = string(severity)
+	return f
+}
+
+// WithImpersonatedUser sets the impersonated user filter.
+func (f AuditLogFilter) WithImpersonatedUser(userID string) AuditLogFilter {
+	f.ImpersonatedUserID = userID
+	return f
+}
+
 // WithTimeRange sets the time range filter.
 func (f AuditLogFilter) WithTimeRange(since, until time.Time) AuditLogFilter {
 	f.Since = since
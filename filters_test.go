@@ -86,6 +86,36 @@ func TestAuditLogFilterWithRole(t *testing.T) {
 	assert.Equal(t, 100, result.Limit) // Other fields unchanged
 }
 
+// TestAuditLogFilterWithCorrelationID tests setting correlation ID filter
+func TestAuditLogFilterWithCorrelationID(t *testing.T) {
+	filter := NewAuditLogFilter()
+
+	result := filter.WithCorrelationID("corr-123")
+
+	assert.Equal(t, "corr-123", result.CorrelationID)
+	assert.Equal(t, 100, result.Limit) // Other fields unchanged
+}
+
+// TestAuditLogFilterWithSeverity tests setting severity filter
+func TestAuditLogFilterWithSeverity(t *testing.T) {
+	filter := NewAuditLogFilter()
+
+	result := filter.WithSeverity(AuditSeverityHigh)
+
+	assert.Equal(t, "high", result.Severity)
+	assert.Equal(t, 100, result.Limit) // Other fields unchanged
+}
+
+// TestAuditLogFilterWithImpersonatedUser tests setting impersonated user filter
+func TestAuditLogFilterWithImpersonatedUser(t *testing.T) {
+	filter := NewAuditLogFilter()
+
+	result := filter.WithImpersonatedUser("customer1")
+
+	assert.Equal(t, "customer1", result.ImpersonatedUserID)
+	assert.Equal(t, 100, result.Limit) // Other fields unchanged
+}
+
 // TestAuditLogFilterWithTimeRange tests setting time range filter
 func TestAuditLogFilterWithTimeRange(t *testing.T) {
 	filter := NewAuditLogFilter()
@@ -298,3 +328,14 @@ func TestAuditLogFilterActionConversion(t *testing.T) {
 	result3 := filter.WithAction("custom_action")
 	assert.Equal(t, "custom_action", result3.Action)
 }
+
+// TestAssignmentFilterMatches tests selecting assignments by scope and role
+func TestAssignmentFilterMatches(t *testing.T) {
+	a := RoleAssignment{UserID: "user1", Role: "member", ScopeType: "team", ScopeID: "team1"}
+
+	assert.True(t, AssignmentFilter{ScopeType: "team"}.Matches(a))
+	assert.True(t, AssignmentFilter{ScopeType: "team", ScopeID: "team1", Roles: []string{"member"}}.Matches(a))
+	assert.False(t, AssignmentFilter{ScopeType: "project"}.Matches(a))
+	assert.False(t, AssignmentFilter{ScopeType: "team", ScopeID: "team2"}.Matches(a))
+	assert.False(t, AssignmentFilter{ScopeType: "team", Roles: []string{"lead"}}.Matches(a))
+}
@@ -6,6 +6,7 @@ require (
 	github.com/fernandezvara/dbkit v0.0.0-20260119113233-d28b15247586
 	github.com/stretchr/testify v1.11.1
 	github.com/uptrace/bun v1.2.16
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -35,6 +36,5 @@ require (
 	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
 	google.golang.org/protobuf v1.36.11 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	mellium.im/sasl v0.3.2 // indirect
 )
@@ -0,0 +1,203 @@
+// Package definition reads the names defined by a rolekit registry, from a
+// YAML file or from the Go package that builds the registry. It is shared by
+// the rolekit-gen and rolekit-vet commands.
+package definition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is the part of a registry the generator needs: names only.
+// It is read from YAML, or dumped as JSON from a Go package.
+//
+// YAML example:
+//
+//	scopes:
+//	  organization:
+//	    roles:
+//	      admin:
+//	        permissions: ["organization.*", "files.upload"]
+//	  project:
+//	    parent: organization
+//	    roles:
+//	      viewer:
+//	        permissions: ["files.read"]
+//	permissions:
+//	  files.upload: Upload files
+//	  files.read: Download and preview files
+type Definition struct {
+	Scopes      map[string]ScopeDefinition `json:"scopes" yaml:"scopes"`
+	Permissions map[string]string          `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+}
+
+// ScopeDefinition lists the roles of a scope type.
+type ScopeDefinition struct {
+	Parent string                    `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Roles  map[string]RoleDefinition `json:"roles" yaml:"roles"`
+}
+
+// RoleDefinition lists the permission patterns of a role.
+type RoleDefinition struct {
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+}
+
+// LoadYAML reads a definition from a YAML file.
+func LoadYAML(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	def := new(Definition)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(def); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, def.Validate()
+}
+
+// dumpProgram calls the registry function of a package and prints its
+// definition. It only uses the public rolekit API.
+var dumpProgram = template.Must(template.New("dump").Parse(`// Code generated by rolekit-gen. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	target {{printf "%q" .Package}}
+)
+
+type role struct {
+	Permissions []string ` + "`json:\"permissions,omitempty\"`" + `
+}
+
+type scope struct {
+	Parent string          ` + "`json:\"parent,omitempty\"`" + `
+	Roles  map[string]role ` + "`json:\"roles\"`" + `
+}
+
+func main() {
+	registry := target.{{.Func}}()
+
+	def := struct {
+		Scopes      map[string]scope  ` + "`json:\"scopes\"`" + `
+		Permissions map[string]string ` + "`json:\"permissions,omitempty\"`" + `
+	}{Scopes: map[string]scope{}, Permissions: map[string]string{}}
+
+	for _, name := range registry.GetScopes() {
+		s := registry.GetScope(name)
+		roles := map[string]role{}
+		for _, roleName := range s.GetRoles() {
+			roles[roleName] = role{Permissions: s.GetRole(roleName).GetPermissions()}
+		}
+		def.Scopes[name] = scope{Parent: s.GetParentScope(), Roles: roles}
+	}
+	for _, p := range registry.GetCatalog() {
+		def.Permissions[p.Name] = p.Description
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(def); err != nil {
+		panic(err)
+	}
+}
+`))
+
+// LoadPackage builds and runs a throwaway program, inside the current module,
+// that calls fn in the package at importPath and prints the registry it
+// returns. fn must have the signature func() *rolekit.Registry.
+func LoadPackage(importPath, fn string) (*Definition, error) {
+	dir, err := os.MkdirTemp(".", "rolekitgen")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var src bytes.Buffer
+	if err := dumpProgram.Execute(&src, struct{ Package, Func string }{importPath, fn}); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("loading %s.%s: %w\n%s", importPath, fn, err, strings.TrimSpace(stderr.String()))
+	}
+
+	def := new(Definition)
+	if err := json.Unmarshal(stdout.Bytes(), def); err != nil {
+		return nil, fmt.Errorf("loading %s.%s: %w", importPath, fn, err)
+	}
+	return def, def.Validate()
+}
+
+// Validate checks that parents refer to defined scopes.
+func (d *Definition) Validate() error {
+	if len(d.Scopes) == 0 && len(d.Permissions) == 0 {
+		return fmt.Errorf("definition has no scopes and no permissions")
+	}
+	for _, name := range sortedKeys(d.Scopes) {
+		if parent := d.Scopes[name].Parent; parent != "" {
+			if _, ok := d.Scopes[parent]; !ok {
+				return fmt.Errorf("scope %q: parent scope %q is not defined", name, parent)
+			}
+		}
+	}
+	return nil
+}
+
+// PermissionNames returns the catalogued permissions or, without a catalog,
+// every role permission without wildcards.
+func (d *Definition) PermissionNames() []string {
+	if len(d.Permissions) > 0 {
+		return sortedKeys(d.Permissions)
+	}
+
+	set := make(map[string]bool)
+	for _, scope := range d.Scopes {
+		for _, role := range scope.Roles {
+			for _, p := range role.Permissions {
+				if !strings.Contains(p, "*") {
+					set[p] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(set)
+}
+
+// ScopeNames returns the scope types, sorted by name.
+func (d *Definition) ScopeNames() []string {
+	return sortedKeys(d.Scopes)
+}
+
+// RoleNames returns the roles of the scope, sorted by name.
+func (s ScopeDefinition) RoleNames() []string {
+	return sortedKeys(s.Roles)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package definition
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadYAML(t *testing.T) {
+	def, err := LoadYAML(filepath.Join("testdata", "roles.yaml"))
+	require.NoError(t, err)
+
+	assert.Len(t, def.Scopes, 2)
+	assert.Equal(t, "organization", def.Scopes["project"].Parent)
+	assert.Equal(t, []string{"files.read"}, def.Scopes["project"].Roles["viewer"].Permissions)
+	assert.Equal(t, "Upload files", def.Permissions["files.upload"])
+}
+
+func TestLoadYAMLErrors(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "roles.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadYAML(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := LoadYAML(write(t, "scopes:\n  org:\n    role:\n      admin: {}\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("undefined parent", func(t *testing.T) {
+		_, err := LoadYAML(write(t, "scopes:\n  project:\n    parent: organization\n"))
+		assert.ErrorContains(t, err, `parent scope "organization" is not defined`)
+	})
+
+	t.Run("empty definition", func(t *testing.T) {
+		_, err := LoadYAML(write(t, "scopes: {}\n"))
+		assert.ErrorContains(t, err, "no scopes and no permissions")
+	})
+}
+
+func TestLoadPackage(t *testing.T) {
+	def, err := LoadPackage("github.com/fernandezvara/rolekit/internal/definition/testdata/registry", "Registry")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"organization", "project"}, def.ScopeNames())
+	assert.Equal(t, "organization", def.Scopes["project"].Parent)
+	assert.Equal(t, []string{"files.read"}, def.Scopes["project"].Roles["viewer"].Permissions)
+	assert.Equal(t, "Read files", def.Permissions["files.read"])
+
+	_, err = LoadPackage("github.com/fernandezvara/rolekit/internal/definition/testdata/registry", "Missing")
+	assert.Error(t, err)
+}
+
+func TestPermissionNames(t *testing.T) {
+	def := &Definition{Scopes: map[string]ScopeDefinition{
+		"organization": {Roles: map[string]RoleDefinition{
+			"admin":  {Permissions: []string{"*", "members.invite"}},
+			"member": {Permissions: []string{"files.read", "files.**", "members.invite"}},
+		}},
+	}}
+
+	// Without a catalog: role permissions without wildcards
+	assert.Equal(t, []string{"files.read", "members.invite"}, def.PermissionNames())
+
+	// With a catalog: the catalog only
+	def.Permissions = map[string]string{"billing.view": ""}
+	assert.Equal(t, []string{"billing.view"}, def.PermissionNames())
+}
@@ -0,0 +1,15 @@
+// Package registry is a LoadPackage fixture.
+package registry
+
+import "github.com/fernandezvara/rolekit"
+
+// Registry returns the fixture registry.
+func Registry() *rolekit.Registry {
+	r := rolekit.NewRegistry()
+	r.DefinePermission("files.read", "Read files")
+	r.DefineScope("organization").
+		Role("admin").Permissions("files.read")
+	r.DefineScope("project").ParentScope("organization").
+		Role("viewer").Permissions("files.read")
+	return r
+}
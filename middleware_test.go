@@ -347,3 +347,55 @@ func TestMiddlewareInjectAuditContext(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+// TestMiddlewareImpersonatedContextDatabase tests that middleware keeps the
+// limits of an impersonation session when it loads the subject's checker
+func TestMiddlewareImpersonatedContextDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	otherOrgID := helper.CreateTestOrg("other")
+	supportID := helper.CreateTestUser("support")
+	customerID := helper.CreateTestUser("customer")
+	require.NoError(t, helper.SetupAdminUser(supportID, orgID))
+	require.NoError(t, helper.SetupDeveloper(customerID, orgID))
+	require.NoError(t, helper.SetupDeveloper(customerID, otherOrgID))
+
+	ctx, _, err := service.Impersonate(WithActorID(helper.GetContext(), supportID), customerID, "organization", orgID)
+	require.NoError(t, err)
+
+	mw := NewMiddleware(service)
+	var loaded *Checker
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loaded = GetChecker(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(permission, scopeID string) int {
+		loaded = nil
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		mw.RequirePermission(permission, StaticScope("organization", scopeID))(next).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Reads in the impersonated scope pass with a checker bounded by the session
+	assert.Equal(t, http.StatusOK, serve("task.read", orgID))
+	require.NotNil(t, loaded)
+	assert.NotNil(t, loaded.Impersonation())
+	assert.False(t, loaded.HasPermission("task.write", "organization", orgID))
+
+	// Writes and other scopes stay blocked although the subject holds them
+	assert.Equal(t, http.StatusForbidden, serve("task.write", orgID))
+	assert.Equal(t, http.StatusForbidden, serve("task.read", otherOrgID))
+	assert.False(t, service.HasPermission(ctx, customerID, "task.write", "organization", orgID))
+
+	// Checkers for other users are not bounded by the session
+	checker, err := service.GetChecker(ctx, supportID)
+	require.NoError(t, err)
+	assert.Nil(t, checker.Impersonation())
+}
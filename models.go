@@ -22,6 +22,14 @@ type RoleAssignment struct {
 	// Optional: parent scope for hierarchical queries
 	ParentScopeType string `bun:"parent_scope_type"`
 	ParentScopeID   string `bun:"parent_scope_id"`
+
+	// Optional: the assignment stops granting its role after this time
+	ExpiresAt *time.Time `bun:"expires_at"`
+}
+
+// IsExpired reports whether the assignment has an expiry that is not after now.
+func (a RoleAssignment) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !a.ExpiresAt.After(now)
 }
 
 // RoleAuditLog records all role assignment changes for compliance and debugging.
@@ -53,10 +61,165 @@ type RoleAuditLog struct {
 	UserAgent string `bun:"user_agent"`
 	RequestID string `bun:"request_id"`
 
+	// Links entries written by one logical operation (e.g. both sides of a transfer)
+	CorrelationID string `bun:"correlation_id"`
+
+	// Empty for routine changes; "high" for entries that need review (e.g. break-glass)
+	Severity string `bun:"severity"`
+
+	// Set when the actor was impersonating another user
+	ImpersonatedUserID string `bun:"impersonated_user_id"`
+
 	// Additional context (JSON)
 	Metadata map[string]any `bun:"metadata,type:jsonb"`
 }
 
+// AccessRequestStatus is the state of an access request.
+type AccessRequestStatus string
+
+const (
+	AccessRequestPending  AccessRequestStatus = "pending"
+	AccessRequestApproved AccessRequestStatus = "approved"
+	AccessRequestDenied   AccessRequestStatus = "denied"
+)
+
+// AccessRequest is a user's request for temporary elevation to a role.
+// Once approved, the role is assigned until ExpiresAt.
+type AccessRequest struct {
+	bun.BaseModel `bun:"table:role_access_requests,alias:rar"`
+
+	ID        string        `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	UserID    string        `bun:"user_id,notnull"`
+	Role      string        `bun:"role,notnull"`
+	ScopeType string        `bun:"scope_type,notnull"`
+	ScopeID   string        `bun:"scope_id,notnull"`
+	Reason    string        `bun:"reason,notnull"`
+	Duration  time.Duration `bun:"duration,notnull"` // How long the role is granted once approved
+
+	Status         AccessRequestStatus `bun:"status,notnull"`
+	DecidedBy      string              `bun:"decided_by"`
+	DecisionReason string              `bun:"decision_reason"`
+
+	RequestedAt time.Time  `bun:"requested_at,notnull,default:current_timestamp"`
+	DecidedAt   *time.Time `bun:"decided_at"`
+	ExpiresAt   *time.Time `bun:"expires_at"` // Set on approval
+}
+
+// InvitationStatus is the state of an invitation.
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// Invitation is a pending role assignment for someone who has no user ID yet
+// (identified by e.g. an email address). Accepting it assigns the role under
+// the inviter's authority. Only a hash of the single-use token is stored.
+type Invitation struct {
+	bun.BaseModel `bun:"table:role_invitations,alias:ri"`
+
+	ID        string `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	Invitee   string `bun:"invitee,notnull"` // e.g. email address
+	Role      string `bun:"role,notnull"`
+	ScopeType string `bun:"scope_type,notnull"`
+	ScopeID   string `bun:"scope_id,notnull"`
+	InviterID string `bun:"inviter_id,notnull"`
+	TokenHash string `bun:"token_hash,notnull"`
+
+	Status     InvitationStatus `bun:"status,notnull"`
+	AcceptedBy string           `bun:"accepted_by"` // User ID that accepted the invitation
+
+	CreatedAt  time.Time  `bun:"created_at,notnull,default:current_timestamp"`
+	ExpiresAt  time.Time  `bun:"expires_at,notnull"`
+	AcceptedAt *time.Time `bun:"accepted_at"`
+}
+
+// Delegation lends a subset of the delegator's permissions in a scope to
+// another user for a time window. It only grants what the delegator still holds.
+type Delegation struct {
+	bun.BaseModel `bun:"table:role_delegations,alias:rd"`
+
+	ID          string   `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	DelegatorID string   `bun:"delegator_id,notnull"`
+	DelegateID  string   `bun:"delegate_id,notnull"`
+	Permissions []string `bun:"permissions,type:text[]"`
+	ScopeType   string   `bun:"scope_type,notnull"`
+	ScopeID     string   `bun:"scope_id,notnull"` // Can be "*" for wildcard
+
+	// Whether the delegate may delegate these permissions further
+	AllowRedelegation bool `bun:"allow_redelegation,notnull"`
+
+	StartsAt  time.Time  `bun:"starts_at,notnull"`
+	EndsAt    time.Time  `bun:"ends_at,notnull"`
+	CreatedAt time.Time  `bun:"created_at,notnull,default:current_timestamp"`
+	RevokedAt *time.Time `bun:"revoked_at"`
+	RevokedBy string     `bun:"revoked_by"`
+}
+
+// IsActive reports whether the delegation is in its time window and not revoked.
+func (d Delegation) IsActive(now time.Time) bool {
+	return d.RevokedAt == nil && !now.Before(d.StartsAt) && now.Before(d.EndsAt)
+}
+
+// AccessReviewStatus is the state of an access review campaign.
+type AccessReviewStatus string
+
+const (
+	AccessReviewOpen   AccessReviewStatus = "open"
+	AccessReviewClosed AccessReviewStatus = "closed"
+)
+
+// ReviewDecision is a reviewer's verdict on a review item.
+type ReviewDecision string
+
+const (
+	ReviewPending ReviewDecision = "pending"
+	ReviewKeep    ReviewDecision = "keep"
+	ReviewRevoke  ReviewDecision = "revoke"
+)
+
+// AccessReview is a recertification campaign over the assignments matching a
+// scope/role filter, snapshotted into AccessReviewItems when it is created.
+type AccessReview struct {
+	bun.BaseModel `bun:"table:role_reviews,alias:rr"`
+
+	ID        string   `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	Name      string   `bun:"name,notnull"`
+	ScopeType string   `bun:"scope_type,notnull"`
+	ScopeID   string   `bun:"scope_id"`          // Empty: every instance of the scope type
+	Roles     []string `bun:"roles,type:text[]"` // Empty: every role
+	CreatedBy string   `bun:"created_by,notnull"`
+
+	// Revoke items still pending when the deadline passes
+	AutoRevoke bool `bun:"auto_revoke,notnull"`
+
+	Status    AccessReviewStatus `bun:"status,notnull"`
+	Deadline  time.Time          `bun:"deadline,notnull"`
+	CreatedAt time.Time          `bun:"created_at,notnull,default:current_timestamp"`
+	ClosedAt  *time.Time         `bun:"closed_at"`
+}
+
+// AccessReviewItem is one assignment under review.
+type AccessReviewItem struct {
+	bun.BaseModel `bun:"table:role_review_items,alias:rri"`
+
+	ID           string `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	ReviewID     string `bun:"review_id,type:uuid,notnull"`
+	AssignmentID string `bun:"assignment_id,type:uuid,notnull"`
+	UserID       string `bun:"user_id,notnull"`
+	Role         string `bun:"role,notnull"`
+	ScopeType    string `bun:"scope_type,notnull"`
+	ScopeID      string `bun:"scope_id,notnull"`
+
+	Decision   ReviewDecision `bun:"decision,notnull"`
+	ReviewerID string         `bun:"reviewer_id"`
+	Comment    string         `bun:"comment"`
+	DecidedAt  *time.Time     `bun:"decided_at"`
+	AppliedAt  *time.Time     `bun:"applied_at"` // When a revoke decision was carried out
+}
+
 // ScopeHierarchy stores the parent-child relationships between scopes.
 // This is used for hierarchical queries like "get all projects in org where user has role X".
 type ScopeHierarchy struct {
@@ -70,6 +233,66 @@ type ScopeHierarchy struct {
 	CreatedAt       time.Time `bun:"created_at,notnull,default:current_timestamp"`
 }
 
+// ArchivedScope marks a scope instance as archived: its role assignments are
+// kept but no longer grant anything.
+type ArchivedScope struct {
+	bun.BaseModel `bun:"table:archived_scopes,alias:ars"`
+
+	ScopeType  string    `bun:"scope_type,pk"`
+	ScopeID    string    `bun:"scope_id,pk"`
+	ArchivedBy string    `bun:"archived_by,notnull"`
+	ArchivedAt time.Time `bun:"archived_at,notnull,default:current_timestamp"`
+}
+
+// ResourceScope links a resource (e.g. a document) to the scope instance that
+// contains it. Roles held in that scope apply to the resource.
+type ResourceScope struct {
+	bun.BaseModel `bun:"table:resource_scopes,alias:rs"`
+
+	ResourceType string    `bun:"resource_type,pk"`
+	ResourceID   string    `bun:"resource_id,pk"`
+	ScopeType    string    `bun:"scope_type,notnull"`
+	ScopeID      string    `bun:"scope_id,notnull"`
+	CreatedAt    time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+// ResourceGrant gives one user permissions on a single resource, independently
+// of their roles (e.g. a document shared with an external user).
+type ResourceGrant struct {
+	bun.BaseModel `bun:"table:resource_grants,alias:rg"`
+
+	ID           string    `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	ResourceType string    `bun:"resource_type,notnull"`
+	ResourceID   string    `bun:"resource_id,notnull"`
+	PrincipalID  string    `bun:"principal_id,notnull"`
+	Permissions  []string  `bun:"permissions,type:text[]"`
+	GrantedBy    string    `bun:"granted_by,notnull"`
+	CreatedAt    time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+// TupleRecord is the stored form of a RelationTuple.
+type TupleRecord struct {
+	bun.BaseModel `bun:"table:relation_tuples,alias:rt"`
+
+	ID               string    `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	Namespace        string    `bun:"namespace,notnull"`
+	ObjectID         string    `bun:"object_id,notnull"`
+	Relation         string    `bun:"relation,notnull"`
+	SubjectNamespace string    `bun:"subject_namespace,notnull"`
+	SubjectID        string    `bun:"subject_id,notnull"`
+	SubjectRelation  string    `bun:"subject_relation,notnull"` // Empty for a single subject
+	CreatedAt        time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+// Tuple returns the relation tuple stored in the record.
+func (r *TupleRecord) Tuple() RelationTuple {
+	return RelationTuple{
+		Object:   ObjectRef{Namespace: r.Namespace, ID: r.ObjectID},
+		Relation: r.Relation,
+		Subject:  Subject{Namespace: r.SubjectNamespace, ID: r.SubjectID, Relation: r.SubjectRelation},
+	}
+}
+
 // Scope represents a scope context for permission checks.
 type Scope struct {
 	Type string // e.g., "organization", "project"
@@ -97,20 +320,33 @@ type UserRoles struct {
 	Assignments []RoleAssignment
 
 	// Indexed for fast lookup
-	byScope map[string][]string // scope_type:scope_id -> []roles
+	byScope    map[string]map[string][]string // scope_type -> scope_id -> []roles
+	nextExpiry time.Time                      // Earliest expiry of the indexed assignments, zero if none expire
 }
 
 // NewUserRoles creates a UserRoles from a list of assignments.
 func NewUserRoles(userID string, assignments []RoleAssignment) *UserRoles {
 	ur := &UserRoles{
-		UserID:      userID,
-		Assignments: assignments,
-		byScope:     make(map[string][]string),
+		UserID:  userID,
+		byScope: make(map[string]map[string][]string),
 	}
 
+	// Expired assignments grant nothing, even before they are cleaned up
+	now := time.Now()
 	for _, a := range assignments {
-		key := a.ScopeType + ":" + a.ScopeID
-		ur.byScope[key] = append(ur.byScope[key], a.Role)
+		if a.IsExpired(now) {
+			continue
+		}
+		ur.Assignments = append(ur.Assignments, a)
+		if a.ExpiresAt != nil && (ur.nextExpiry.IsZero() || a.ExpiresAt.Before(ur.nextExpiry)) {
+			ur.nextExpiry = *a.ExpiresAt
+		}
+		ids := ur.byScope[a.ScopeType]
+		if ids == nil {
+			ids = make(map[string][]string)
+			ur.byScope[a.ScopeType] = ids
+		}
+		ids[a.ScopeID] = append(ids[a.ScopeID], a.Role)
 	}
 
 	return ur
@@ -119,40 +355,112 @@ func NewUserRoles(userID string, assignments []RoleAssignment) *UserRoles {
 // GetRoles returns all roles for a specific scope.
 // Also checks for wildcard assignments (scope_id = "*").
 func (ur *UserRoles) GetRoles(scopeType, scopeID string) []string {
-	var roles []string
+	exact, wildcard := ur.rolesIn(scopeType, scopeID)
+	if len(exact)+len(wildcard) == 0 {
+		return nil
+	}
 
-	// Check exact match
-	key := scopeType + ":" + scopeID
-	if r, ok := ur.byScope[key]; ok {
-		roles = append(roles, r...)
+	roles := make([]string, 0, len(exact)+len(wildcard))
+	roles = append(roles, exact...)
+	return append(roles, wildcard...)
+}
+
+// rolesIn returns the roles held in a scope instance and in the wildcard
+// scope of its type, without copying them unless an assignment has expired
+// since the UserRoles was created.
+func (ur *UserRoles) rolesIn(scopeType, scopeID string) (exact, wildcard []string) {
+	if ur.hasExpired() {
+		for _, a := range ur.active() {
+			switch {
+			case a.ScopeType != scopeType:
+			case a.ScopeID == "*":
+				wildcard = append(wildcard, a.Role)
+			case a.ScopeID == scopeID:
+				exact = append(exact, a.Role)
+			}
+		}
+		return exact, wildcard
 	}
 
-	// Check wildcard match
-	wildcardKey := scopeType + ":*"
-	if r, ok := ur.byScope[wildcardKey]; ok {
-		roles = append(roles, r...)
+	ids := ur.byScope[scopeType]
+	if ids == nil {
+		return nil, nil
 	}
+	if scopeID != "*" {
+		exact = ids[scopeID]
+	}
+	return exact, ids["*"]
+}
 
-	return roles
+// hasExpired reports whether one of the assignments has expired since the
+// UserRoles was created.
+func (ur *UserRoles) hasExpired() bool {
+	return !ur.nextExpiry.IsZero() && !time.Now().Before(ur.nextExpiry)
 }
 
-// HasRole checks if the user has a specific role in a scope.
-func (ur *UserRoles) HasRole(role, scopeType, scopeID string) bool {
-	roles := ur.GetRoles(scopeType, scopeID)
-	for _, r := range roles {
-		if r == role {
-			return true
+// active returns the assignments that have not expired. Long-lived values,
+// such as a Checker kept in a context, stop granting a role once its
+// assignment expires, even before RevokeExpired deletes it.
+func (ur *UserRoles) active() []RoleAssignment {
+	if !ur.hasExpired() {
+		return ur.Assignments
+	}
+	now := time.Now()
+	var active []RoleAssignment
+	for _, a := range ur.Assignments {
+		if !a.IsExpired(now) {
+			active = append(active, a)
 		}
 	}
-	return false
+	return active
+}
+
+// HasRole checks if the user has a specific role in a scope.
+func (ur *UserRoles) HasRole(role, scopeType, scopeID string) bool {
+	exact, wildcard := ur.rolesIn(scopeType, scopeID)
+	return containsString(exact, role) || containsString(wildcard, role)
 }
 
 // AuditAction represents the type of action in the audit log.
 type AuditAction string
 
 const (
-	AuditActionAssigned AuditAction = "assigned"
-	AuditActionRevoked  AuditAction = "revoked"
+	AuditActionAssigned    AuditAction = "assigned"
+	AuditActionRevoked     AuditAction = "revoked"
+	AuditActionTransferOut AuditAction = "transfer_out"
+	AuditActionTransferIn  AuditAction = "transfer_in"
+	AuditActionExpired     AuditAction = "expired"
+
+	AuditActionAccessRequested AuditAction = "access_requested"
+	AuditActionAccessApproved  AuditAction = "access_approved"
+	AuditActionAccessDenied    AuditAction = "access_denied"
+
+	AuditActionBreakGlass AuditAction = "break_glass"
+
+	AuditActionInvited            AuditAction = "invited"
+	AuditActionInvitationAccepted AuditAction = "invitation_accepted"
+	AuditActionInvitationRevoked  AuditAction = "invitation_revoked"
+
+	AuditActionDelegated         AuditAction = "delegated"
+	AuditActionDelegationRevoked AuditAction = "delegation_revoked"
+
+	AuditActionImpersonationStarted AuditAction = "impersonation_started"
+
+	AuditActionScopeDeleted  AuditAction = "scope_deleted"
+	AuditActionScopeArchived AuditAction = "scope_archived"
+	AuditActionScopeRestored AuditAction = "scope_restored"
+	AuditActionScopeMoved    AuditAction = "scope_moved"
+
+	AuditActionResourceGranted AuditAction = "resource_granted"
+	AuditActionResourceRevoked AuditAction = "resource_revoked"
+	AuditActionResourceScoped  AuditAction = "resource_scoped"
+)
+
+// AuditSeverity flags audit entries that need attention.
+type AuditSeverity string
+
+const (
+	AuditSeverityHigh AuditSeverity = "high"
 )
 
 // AuditEntry is used to create new audit log entries.
@@ -169,7 +477,11 @@ type AuditEntry struct {
 	IPAddress     string
 	UserAgent     string
 	RequestID     string
+	CorrelationID string
+	Severity      AuditSeverity
 	Metadata      map[string]any
+
+	ImpersonatedUserID string
 }
 
 // ToModel converts an AuditEntry to a RoleAuditLog model.
@@ -187,7 +499,11 @@ func (e *AuditEntry) ToModel() *RoleAuditLog {
 		IPAddress:     e.IPAddress,
 		UserAgent:     e.UserAgent,
 		RequestID:     e.RequestID,
+		CorrelationID: e.CorrelationID,
+		Severity:      string(e.Severity),
 		Metadata:      e.Metadata,
 		Timestamp:     time.Now(),
+
+		ImpersonatedUserID: e.ImpersonatedUserID,
 	}
 }
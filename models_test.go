@@ -163,8 +163,8 @@ func TestNewUserRoles(t *testing.T) {
 
 		assert.Equal(t, "user123", ur.UserID)
 		assert.Len(t, ur.Assignments, 1)
-		assert.Contains(t, ur.byScope, "organization:org123")
-		assert.Equal(t, []string{"admin"}, ur.byScope["organization:org123"])
+		assert.Contains(t, ur.byScope["organization"], "org123")
+		assert.Equal(t, []string{"admin"}, ur.byScope["organization"]["org123"])
 	})
 
 	t.Run("Multiple assignments", func(t *testing.T) {
@@ -179,9 +179,9 @@ func TestNewUserRoles(t *testing.T) {
 
 		assert.Equal(t, "user123", ur.UserID)
 		assert.Len(t, ur.Assignments, 4)
-		assert.Equal(t, []string{"admin", "member"}, ur.byScope["organization:org123"])
-		assert.Equal(t, []string{"owner"}, ur.byScope["project:proj456"])
-		assert.Equal(t, []string{"admin"}, ur.byScope["project:*"])
+		assert.Equal(t, []string{"admin", "member"}, ur.byScope["organization"]["org123"])
+		assert.Equal(t, []string{"owner"}, ur.byScope["project"]["proj456"])
+		assert.Equal(t, []string{"admin"}, ur.byScope["project"]["*"])
 	})
 
 	t.Run("Multiple users in assignments", func(t *testing.T) {
@@ -194,9 +194,52 @@ func TestNewUserRoles(t *testing.T) {
 
 		// NewUserRoles stores all assignments but indexes by scope
 		assert.Equal(t, "user123", ur.UserID)
-		assert.Len(t, ur.Assignments, 2)                                                // Both assignments are kept
-		assert.Equal(t, []string{"admin", "member"}, ur.byScope["organization:org123"]) // All roles in scope
+		assert.Len(t, ur.Assignments, 2)                                                   // Both assignments are kept
+		assert.Equal(t, []string{"admin", "member"}, ur.byScope["organization"]["org123"]) // All roles in scope
 	})
+
+	t.Run("Expired assignments are skipped", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		future := time.Now().Add(time.Hour)
+		assignments := []RoleAssignment{
+			{UserID: "user123", Role: "admin", ScopeType: "organization", ScopeID: "org123", ExpiresAt: &past},
+			{UserID: "user123", Role: "viewer", ScopeType: "organization", ScopeID: "org123", ExpiresAt: &future},
+		}
+
+		ur := NewUserRoles("user123", assignments)
+
+		assert.Len(t, ur.Assignments, 1)
+		assert.False(t, ur.HasRole("admin", "organization", "org123"))
+		assert.True(t, ur.HasRole("viewer", "organization", "org123"))
+	})
+
+	t.Run("Assignments expiring later stop granting", func(t *testing.T) {
+		soon := time.Now().Add(20 * time.Millisecond)
+		assignments := []RoleAssignment{
+			{UserID: "user123", Role: "owner", ScopeType: "organization", ScopeID: "org123", ExpiresAt: &soon},
+			{UserID: "user123", Role: "viewer", ScopeType: "organization", ScopeID: "*"},
+		}
+
+		ur := NewUserRoles("user123", assignments)
+		assert.True(t, ur.HasRole("owner", "organization", "org123"))
+
+		time.Sleep(time.Until(soon) + time.Millisecond)
+		assert.False(t, ur.HasRole("owner", "organization", "org123"))
+		assert.Equal(t, []string{"viewer"}, ur.GetRoles("organization", "org123"))
+		assert.Len(t, ur.active(), 1)
+	})
+}
+
+// TestRoleAssignment_IsExpired tests assignment expiry
+func TestRoleAssignment_IsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Second)
+	future := now.Add(time.Second)
+
+	assert.False(t, RoleAssignment{}.IsExpired(now))
+	assert.True(t, RoleAssignment{ExpiresAt: &past}.IsExpired(now))
+	assert.True(t, RoleAssignment{ExpiresAt: &now}.IsExpired(now))
+	assert.False(t, RoleAssignment{ExpiresAt: &future}.IsExpired(now))
 }
 
 // TestUserRoles_GetRoles tests role retrieval
@@ -278,6 +321,8 @@ func TestAuditAction(t *testing.T) {
 	t.Run("Constants", func(t *testing.T) {
 		assert.Equal(t, AuditAction("assigned"), AuditActionAssigned)
 		assert.Equal(t, AuditAction("revoked"), AuditActionRevoked)
+		assert.Equal(t, AuditAction("transfer_out"), AuditActionTransferOut)
+		assert.Equal(t, AuditAction("transfer_in"), AuditActionTransferIn)
 	})
 
 	t.Run("String values", func(t *testing.T) {
@@ -336,6 +381,8 @@ func TestAuditEntry_ToModel(t *testing.T) {
 		IPAddress:     "192.168.1.1",
 		UserAgent:     "Mozilla/5.0",
 		RequestID:     "req-123",
+		CorrelationID: "corr-123",
+		Severity:      AuditSeverityHigh,
 		Metadata:      map[string]any{"key": "value"},
 	}
 
@@ -353,6 +400,8 @@ func TestAuditEntry_ToModel(t *testing.T) {
 	assert.Equal(t, "192.168.1.1", model.IPAddress)
 	assert.Equal(t, "Mozilla/5.0", model.UserAgent)
 	assert.Equal(t, "req-123", model.RequestID)
+	assert.Equal(t, "corr-123", model.CorrelationID)
+	assert.Equal(t, "high", model.Severity)
 	assert.Equal(t, "value", model.Metadata["key"])
 	assert.NotZero(t, model.Timestamp)
 	assert.WithinDuration(t, time.Now(), model.Timestamp, time.Second)
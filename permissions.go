@@ -7,29 +7,62 @@ import (
 // PermissionMatcher handles permission matching with wildcard support.
 //
 // Supported patterns:
-//   - "*" matches all permissions
+//   - "*" (or "**") matches all permissions
 //   - "resource.*" matches all actions on a resource (e.g., "files.*" matches "files.read")
 //   - "*.action" matches an action on all resources (e.g., "*.read" matches "files.read")
+//   - "resource.**" matches every permission below a resource, at any depth
+//     (e.g., "files.**" matches "files.read" and "files.versions.restore")
 //   - "exact.match" matches exactly
-type PermissionMatcher struct{}
+//
+// A "*" segment matches exactly one segment. A "**" segment matches one or
+// more segments, so "files.**" does not match "files" itself and "a.**.c"
+// does not match "a.c".
+type PermissionMatcher struct {
+	separator string
+}
+
+// DefaultPermissionSeparator separates the segments of a permission.
+const DefaultPermissionSeparator = "."
 
 // NewPermissionMatcher creates a new PermissionMatcher.
 func NewPermissionMatcher() *PermissionMatcher {
-	return &PermissionMatcher{}
+	return &PermissionMatcher{separator: DefaultPermissionSeparator}
+}
+
+// NewPermissionMatcherWithSeparator creates a PermissionMatcher for
+// permissions whose segments are separated by something other than ".",
+// e.g. ":" for "files:versions:restore". The registry and checkers always
+// use DefaultPermissionSeparator.
+func NewPermissionMatcherWithSeparator(separator string) *PermissionMatcher {
+	if separator == "" {
+		separator = DefaultPermissionSeparator
+	}
+	return &PermissionMatcher{separator: separator}
+}
+
+// Separator returns the segment separator of the matcher.
+func (pm *PermissionMatcher) Separator() string {
+	if pm.separator == "" {
+		return DefaultPermissionSeparator
+	}
+	return pm.separator
 }
 
 // Match checks if a permission pattern matches a required permission.
 //
 // Examples:
 //
-//	Match("*", "files.read")           // true - wildcard matches all
-//	Match("files.*", "files.read")     // true - resource wildcard
-//	Match("files.*", "files.write")    // true - resource wildcard
-//	Match("*.read", "files.read")      // true - action wildcard
-//	Match("*.read", "members.read")    // true - action wildcard
-//	Match("files.read", "files.read")  // true - exact match
-//	Match("files.read", "files.write") // false - no match
-//	Match("files.*", "members.read")   // false - different resource
+//	Match("*", "files.read")                     // true - wildcard matches all
+//	Match("files.*", "files.read")               // true - resource wildcard
+//	Match("files.*", "files.write")              // true - resource wildcard
+//	Match("*.read", "files.read")                // true - action wildcard
+//	Match("*.read", "members.read")              // true - action wildcard
+//	Match("files.read", "files.read")            // true - exact match
+//	Match("files.read", "files.write")           // false - no match
+//	Match("files.*", "members.read")             // false - different resource
+//	Match("files.*", "files.versions.restore")   // false - "*" is one segment
+//	Match("files.**", "files.versions.restore")  // true - recursive wildcard
+//	Match("files.**", "files")                   // false - "**" needs a segment
 func (pm *PermissionMatcher) Match(pattern, permission string) bool {
 	// Exact match
 	if pattern == permission {
@@ -37,31 +70,66 @@ func (pm *PermissionMatcher) Match(pattern, permission string) bool {
 	}
 
 	// Universal wildcard
-	if pattern == "*" {
+	if pattern == "*" || pattern == "**" {
 		return true
 	}
 
-	// Split into parts
-	patternParts := strings.Split(pattern, ".")
-	permParts := strings.Split(permission, ".")
+	return pm.matchParts(pattern, permission, false)
+}
 
-	// Must have same number of parts (or pattern is just "*")
-	if len(patternParts) != len(permParts) {
-		return false
-	}
+// matchParts compares pattern and permission segment by segment without
+// allocating. When covering is set the permission is itself a pattern, and a
+// "*" segment does not cover a "**" segment (see uncoveredPermissions).
+func (pm *PermissionMatcher) matchParts(pattern, permission string, covering bool) bool {
+	sep := pm.Separator()
+	for {
+		patternPart, patternRest, patternMore := strings.Cut(pattern, sep)
+		permPart, permRest, permMore := strings.Cut(permission, sep)
 
-	// Match each part
-	for i, pp := range patternParts {
-		if pp == "*" {
-			// Wildcard matches anything
-			continue
+		if patternPart == "**" {
+			if !patternMore {
+				return true // Trailing "**" takes every remaining segment
+			}
+			// "**" takes this segment, then as many more as needed
+			for permMore {
+				if pm.matchParts(patternRest, permRest, covering) {
+					return true
+				}
+				_, permRest, permMore = strings.Cut(permRest, sep)
+			}
+			return false
+		}
+
+		if patternPart == "*" {
+			if covering && permPart == "**" {
+				return false
+			}
+		} else if patternPart != permPart {
+			return false
 		}
-		if pp != permParts[i] {
+
+		// Both must have the same number of segments
+		if patternMore != permMore {
 			return false
 		}
+		if !patternMore {
+			return true
+		}
+		pattern, permission = patternRest, permRest
 	}
+}
 
-	return true
+// Covers reports whether every permission granted by pattern is also granted
+// by held. Unlike Match, a "*" segment in held does not cover a "**" segment
+// in pattern, so "files.*" does not cover "files.**".
+func (pm *PermissionMatcher) Covers(held, pattern string) bool {
+	if held == pattern || held == "*" || held == "**" {
+		return true
+	}
+	if pattern == "*" || pattern == "**" {
+		return false
+	}
+	return pm.matchParts(held, pattern, true)
 }
 
 // MatchAny checks if any of the patterns match the required permission.
@@ -97,17 +165,18 @@ func (pm *PermissionMatcher) ExpandPermissions(patterns []string, all []string)
 }
 
 // Validate checks if a permission string is valid.
-// A valid permission is either "*" or a dot-separated string of identifiers.
+// A valid permission is either "*", "**" or a dot-separated string of
+// identifiers, "*" and "**" segments.
 func (pm *PermissionMatcher) Validate(permission string) error {
 	if permission == "" {
 		return NewError(ErrInvalidPermission, "permission cannot be empty")
 	}
 
-	if permission == "*" {
+	if permission == "*" || permission == "**" {
 		return nil
 	}
 
-	parts := strings.Split(permission, ".")
+	parts := strings.Split(permission, pm.Separator())
 	if len(parts) < 2 {
 		return NewError(ErrInvalidPermission, "permission must have at least two parts (resource.action)")
 	}
@@ -116,8 +185,8 @@ func (pm *PermissionMatcher) Validate(permission string) error {
 		if part == "" {
 			return NewError(ErrInvalidPermission, "permission parts cannot be empty")
 		}
-		// Allow * as a part
-		if part == "*" {
+		// Allow * and ** as parts
+		if part == "*" || part == "**" {
 			continue
 		}
 		// Check for valid identifier characters (alphanumeric and underscore)
@@ -138,6 +207,116 @@ func isValidPermissionChar(c rune) bool {
 		c == '_'
 }
 
+// CompiledPermissions is a set of permission patterns compiled into a trie of
+// dot-separated segments, so that checking a permission costs one walk down
+// the trie instead of one comparison per pattern. Match does not allocate.
+type CompiledPermissions struct {
+	patterns []string
+	all      bool // Some pattern is "*"
+	root     permissionNode
+}
+
+type permissionNode struct {
+	children  map[string]*permissionNode
+	wildcard  *permissionNode // Child for a "*" segment
+	recursive *permissionNode // Child for a "**" segment
+	terminal  bool            // A pattern ends here
+}
+
+// CompilePermissions compiles permission patterns for repeated matching.
+//
+// Example:
+//
+//	perms := rolekit.CompilePermissions([]string{"files.*", "*.read"})
+//	perms.Match("files.write")   // true
+//	perms.Match("members.read")  // true
+//	perms.Match("members.write") // false
+func CompilePermissions(patterns []string) *CompiledPermissions {
+	c := &CompiledPermissions{patterns: append([]string{}, patterns...)}
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == "**" {
+			c.all = true
+			continue
+		}
+
+		node := &c.root
+		for _, part := range strings.Split(pattern, ".") {
+			node = node.child(part)
+		}
+		node.terminal = true
+	}
+	return c
+}
+
+// Match reports whether any of the compiled patterns matches the permission.
+func (c *CompiledPermissions) Match(permission string) bool {
+	if c.all {
+		return true
+	}
+	return c.root.match(permission)
+}
+
+// Patterns returns the patterns the set was compiled from.
+func (c *CompiledPermissions) Patterns() []string {
+	return c.patterns
+}
+
+// child returns the node for a segment, creating it if needed.
+func (n *permissionNode) child(part string) *permissionNode {
+	if part == "**" {
+		if n.recursive == nil {
+			n.recursive = &permissionNode{}
+		}
+		return n.recursive
+	}
+	if part == "*" {
+		if n.wildcard == nil {
+			n.wildcard = &permissionNode{}
+		}
+		return n.wildcard
+	}
+	if n.children == nil {
+		n.children = make(map[string]*permissionNode)
+	}
+	child := n.children[part]
+	if child == nil {
+		child = &permissionNode{}
+		n.children[part] = child
+	}
+	return child
+}
+
+// match follows the literal, wildcard and recursive children for the next segment.
+func (n *permissionNode) match(permission string) bool {
+	part, rest, more := strings.Cut(permission, ".")
+	if child := n.children[part]; child != nil && child.matchRest(rest, more) {
+		return true
+	}
+	if n.wildcard != nil && n.wildcard.matchRest(rest, more) {
+		return true
+	}
+	if n.recursive != nil {
+		// "**" takes this segment, then as many more as needed
+		if n.recursive.terminal {
+			return true
+		}
+		for more {
+			if n.recursive.match(rest) {
+				return true
+			}
+			_, rest, more = strings.Cut(rest, ".")
+		}
+	}
+	return false
+}
+
+func (n *permissionNode) matchRest(rest string, more bool) bool {
+	if !more {
+		return n.terminal
+	}
+	return n.match(rest)
+}
+
 // DefaultMatcher is the default permission matcher instance.
 var DefaultMatcher = NewPermissionMatcher()
 
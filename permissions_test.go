@@ -513,3 +513,113 @@ func TestPermissionEdgeCases(t *testing.T) {
 		assert.Empty(t, result2)
 	})
 }
+
+// TestCompilePermissions tests that compiled pattern sets match like PermissionMatcher
+func TestCompilePermissions(t *testing.T) {
+	patterns := []string{"*", "**", "files.*", "*.read", "files.read", "a.*.c", "a.b", "x..y", "",
+		"files.**", "a.**.c", "**.read", "a.**.b.**", "*.**"}
+	permissions := []string{"files.read", "files.write", "members.read", "members.write", "files", "files.read.all",
+		"a.b.c", "a.x.c", "a.b", "a.b.d", "x..y", "x.z.y", "", "*", "files.*",
+		"files.versions.restore", "a.c", "a.x.y.c", "a.x.b.y", "a.b.b", "a.b.c.b.d", "x.y.z.read"}
+
+	matcher := NewPermissionMatcher()
+	for _, pattern := range patterns {
+		compiled := CompilePermissions([]string{pattern})
+		for _, permission := range permissions {
+			assert.Equal(t, matcher.Match(pattern, permission), compiled.Match(permission),
+				"pattern %q, permission %q", pattern, permission)
+		}
+	}
+
+	set := []string{"files.*", "*.read", "a.*.c", "x.**.y"}
+	compiled := CompilePermissions(set)
+	assert.Equal(t, set, compiled.Patterns())
+	for _, permission := range permissions {
+		assert.Equal(t, matcher.MatchAny(set, permission), compiled.Match(permission), "permission %q", permission)
+	}
+
+	assert.False(t, CompilePermissions(nil).Match("files.read"))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { compiled.Match("members.read") }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { matcher.Match("a.*.c", "a.b.c") }))
+}
+
+// TestPermissionMatcherRecursiveWildcard documents how "*" and "**" interact
+func TestPermissionMatcherRecursiveWildcard(t *testing.T) {
+	matcher := NewPermissionMatcher()
+
+	tests := []struct {
+		pattern    string
+		permission string
+		expected   bool
+	}{
+		// "*" matches exactly one segment
+		{"files.*", "files.read", true},
+		{"files.*", "files.versions.restore", false},
+		{"*.restore", "files.versions.restore", false},
+
+		// "**" matches one or more segments, at any position
+		{"files.**", "files.read", true},
+		{"files.**", "files.versions.restore", true},
+		{"files.**", "files", false},
+		{"files.**", "members.read", false},
+		{"**.restore", "files.versions.restore", true},
+		{"**.restore", "restore", false},
+		{"files.**.restore", "files.versions.restore", true},
+		{"files.**.restore", "files.a.b.c.restore", true},
+		{"files.**.restore", "files.restore", false},
+		{"files.**.restore", "files.versions.delete", false},
+		{"files.**.*", "files.versions.restore", true},
+		{"files.**.*", "files.read", false},
+
+		// A whole-permission wildcard matches everything, whatever the depth
+		{"**", "files.versions.restore", true},
+		{"*", "files.versions.restore", true},
+
+		// Wildcards in the permission being checked are literal segments
+		{"files.read", "files.*", false},
+		{"files.*", "files.**", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, matcher.Match(tt.pattern, tt.permission), "Match(%q, %q)", tt.pattern, tt.permission)
+	}
+
+	assert.Equal(t, []string{"files.versions.restore"},
+		matcher.ExpandPermissions([]string{"**.restore"}, []string{"files.read", "files.versions.restore", "restore"}))
+	assert.ElementsMatch(t, []string{"files.read", "files.versions.restore"},
+		matcher.ExpandPermissions([]string{"files.**"}, []string{"files.read", "files.versions.restore", "members.read"}))
+
+	assert.NoError(t, matcher.Validate("**"))
+	assert.NoError(t, matcher.Validate("files.**"))
+	assert.NoError(t, matcher.Validate("files.**.restore"))
+	assert.Error(t, matcher.Validate("files.***"))
+	assert.Error(t, matcher.Validate("files.a**"))
+}
+
+// TestPermissionMatcherSeparator tests matching with a custom segment separator
+func TestPermissionMatcherSeparator(t *testing.T) {
+	matcher := NewPermissionMatcherWithSeparator(":")
+	assert.Equal(t, ":", matcher.Separator())
+	assert.Equal(t, DefaultPermissionSeparator, NewPermissionMatcherWithSeparator("").Separator())
+
+	assert.True(t, matcher.Match("files:*", "files:read"))
+	assert.True(t, matcher.Match("files:**", "files:versions:restore"))
+	assert.False(t, matcher.Match("files:*", "files:versions:restore"))
+	assert.False(t, matcher.Match("files.*", "files:read"))
+
+	assert.NoError(t, matcher.Validate("files:versions:restore"))
+	assert.Error(t, matcher.Validate("files.read"))
+}
+
+// TestPermissionMatcherCovers tests pattern coverage used by the escalation guard
+func TestPermissionMatcherCovers(t *testing.T) {
+	matcher := NewPermissionMatcher()
+
+	assert.True(t, matcher.Covers("files.**", "files.*"))
+	assert.True(t, matcher.Covers("files.**", "files.**"))
+	assert.True(t, matcher.Covers("files.**", "files.versions.restore"))
+	assert.True(t, matcher.Covers("**", "*"))
+	assert.False(t, matcher.Covers("files.*", "files.**"))
+	assert.False(t, matcher.Covers("*.*", "files.**"))
+	assert.False(t, matcher.Covers("files.**", "*"))
+	assert.False(t, matcher.Covers("a.**.c", "a.**"))
+}
@@ -1,15 +1,25 @@
 package rolekit
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // Registry holds all scope and role definitions for the application.
 // It is created at startup and should be treated as immutable after initialization.
 type Registry struct {
-	mu     sync.RWMutex
-	scopes map[string]*ScopeDefinition
+	mu               sync.RWMutex
+	scopes           map[string]*ScopeDefinition
+	constraints      []RoleConstraint                // Separation-of-duties constraints
+	noEscalation     bool                            // Reject assignments granting more than the actor holds
+	delegation       bool                            // Load and honor permission delegations
+	namespaces       map[string]*NamespaceDefinition // Relation-tuple namespaces
+	definitionErrors []error                         // Invalid definitions, reported by Validate
+	permissions      map[string]string               // Permission catalog: name -> description
+	strict           bool                            // Deny checks of uncatalogued permissions
+	onUnknown        func(permission string)         // Called for denied uncatalogued permissions
 }
 
 // ScopeDefinition defines a scope type (e.g., "organization", "project")
@@ -26,15 +36,22 @@ type ScopeDefinition struct {
 type RoleDefinition struct {
 	name           string
 	scopeName      string
-	permissions    []string // Permissions this role grants
-	canAssignRoles []string // Roles this role can assign to others
+	permissions    []string                     // Permissions this role grants
+	canAssignRoles []string                     // Roles this role can assign to others
+	canAssignIn    map[string][]string          // Roles this role can assign in descendant scope types
+	minMembers     int                          // Minimum holders per scope instance (0 = no minimum)
+	maxMembers     int                          // Maximum holders per scope instance (0 = unlimited)
+	conditions     map[string][]*Condition      // Conditions per permission pattern (see When)
+	compiled       atomic.Pointer[compiledRole] // Compiled permissions, reset when the role changes
 	scope          *ScopeDefinition
 }
 
 // NewRegistry creates a new role registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		scopes: make(map[string]*ScopeDefinition),
+		scopes:      make(map[string]*ScopeDefinition),
+		namespaces:  make(map[string]*NamespaceDefinition),
+		permissions: make(map[string]string),
 	}
 }
 
@@ -142,6 +159,38 @@ func (r *Registry) CanRoleAssign(assignerRole, targetRole, scopeType string) boo
 	return false
 }
 
+// CanRoleAssignIn checks if a role held in one scope type can assign a role in a
+// descendant scope of another scope type (see RoleDefinition.CanAssignIn).
+func (r *Registry) CanRoleAssignIn(assignerRole, assignerScopeType, targetRole, targetScopeType string) bool {
+	roleDef := r.GetRole(assignerRole, assignerScopeType)
+	if roleDef == nil {
+		return false
+	}
+
+	for _, allowed := range roleDef.GetCanAssignIn(targetScopeType) {
+		if allowed == "*" || allowed == targetRole {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCrossScopeAssigners reports whether any role can assign roles in the given
+// scope type from an ancestor scope. Checkers use it to skip hierarchy lookups.
+func (r *Registry) HasCrossScopeAssigners(targetScopeType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, scope := range r.scopes {
+		for _, role := range scope.roles {
+			if len(role.canAssignIn[targetScopeType]) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ParentScope sets the parent scope type for hierarchical queries.
 // This creates awareness but does NOT grant automatic access.
 //
@@ -205,6 +254,7 @@ func (s *ScopeDefinition) Name() string {
 //	role.Permissions("files.read", "files.write", "comments.*")
 func (r *RoleDefinition) Permissions(perms ...string) *RoleDefinition {
 	r.permissions = append(r.permissions, perms...)
+	r.compiled.Store(nil)
 	return r
 }
 
@@ -220,6 +270,48 @@ func (r *RoleDefinition) CanAssign(roles ...string) *RoleDefinition {
 	return r
 }
 
+// CanAssignIn sets which roles this role can assign in descendant scopes of
+// another scope type. The target scope instance must be a descendant of the
+// scope where the assigner holds this role, as recorded by SetScopeParent.
+// Use "*" to allow assigning any role of that scope type.
+//
+// Example:
+//
+//	registry.DefineScope("organization").
+//	    Role("admin").CanAssignIn("project", "editor", "viewer")
+//
+// An organization admin can now assign "editor" on any project inside the organization.
+func (r *RoleDefinition) CanAssignIn(scopeType string, roles ...string) *RoleDefinition {
+	if r.canAssignIn == nil {
+		r.canAssignIn = make(map[string][]string)
+	}
+	r.canAssignIn[scopeType] = append(r.canAssignIn[scopeType], roles...)
+	return r
+}
+
+// MinMembers sets the minimum number of users that must hold this role in each
+// scope instance once it has been assigned. Revocations that would leave fewer
+// holders are rejected, which protects against removing the last owner.
+//
+// Example:
+//
+//	scope.Role("owner").Permissions("*").MinMembers(1)
+func (r *RoleDefinition) MinMembers(n int) *RoleDefinition {
+	r.minMembers = n
+	return r
+}
+
+// MaxMembers sets the maximum number of users that may hold this role in each
+// scope instance. Use 0 for no limit.
+//
+// Example:
+//
+//	scope.Role("owner").Permissions("*").MaxMembers(3)
+func (r *RoleDefinition) MaxMembers(n int) *RoleDefinition {
+	r.maxMembers = n
+	return r
+}
+
 // Role continues defining roles in the parent scope (fluent API).
 // This allows chaining role definitions.
 //
@@ -247,6 +339,26 @@ func (r *RoleDefinition) GetCanAssign() []string {
 	return r.canAssignRoles
 }
 
+// GetCanAssignIn returns the roles this role can assign in descendant scopes of a scope type.
+func (r *RoleDefinition) GetCanAssignIn(scopeType string) []string {
+	return r.canAssignIn[scopeType]
+}
+
+// GetMinMembers returns the minimum number of holders per scope instance (0 = no minimum).
+func (r *RoleDefinition) GetMinMembers() int {
+	return r.minMembers
+}
+
+// GetMaxMembers returns the maximum number of holders per scope instance (0 = unlimited).
+func (r *RoleDefinition) GetMaxMembers() int {
+	return r.maxMembers
+}
+
+// HasCardinality reports whether the role has a minimum or maximum number of holders.
+func (r *RoleDefinition) HasCardinality() bool {
+	return r.minMembers > 0 || r.maxMembers > 0
+}
+
 // Name returns the role name.
 func (r *RoleDefinition) Name() string {
 	return r.name
@@ -256,3 +368,74 @@ func (r *RoleDefinition) Name() string {
 func (r *RoleDefinition) ScopeName() string {
 	return r.scopeName
 }
+
+// compiledRole holds the permissions of a role prepared for checks.
+type compiledRole struct {
+	unconditional *CompiledPermissions
+	conditional   []string // Patterns with conditions, evaluated per check
+}
+
+// compiledPermissions returns the role's compiled permissions, compiling them
+// on first use after the role was defined or changed.
+func (r *RoleDefinition) compiledPermissions() *compiledRole {
+	if c := r.compiled.Load(); c != nil {
+		return c
+	}
+
+	var unconditional, conditional []string
+	for _, pattern := range r.permissions {
+		if _, ok := r.conditions[pattern]; ok {
+			conditional = append(conditional, pattern)
+		} else {
+			unconditional = append(unconditional, pattern)
+		}
+	}
+	c := &compiledRole{unconditional: CompilePermissions(unconditional), conditional: conditional}
+	r.compiled.Store(c)
+	return c
+}
+
+// Freeze compiles the permissions of every role for fast checks and reports
+// configuration errors (see Validate). Call it once the registry is fully
+// defined. NewService compiles the roles as well but reports nothing, and
+// roles changed afterwards are recompiled on their next check.
+//
+// Example:
+//
+//	if err := registry.Freeze(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *Registry) Freeze() error {
+	r.compileRoles()
+	return r.Validate()
+}
+
+// Validate reports the configuration errors found while defining the
+// registry: conditions that do not compile, invalid catalogued permissions,
+// and role patterns that match no catalogued permission. Call it once at
+// startup.
+//
+// Example:
+//
+//	if err := registry.Validate(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *Registry) Validate() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	errs := append([]error{}, r.definitionErrors...)
+	return errors.Join(append(errs, r.danglingPatterns()...)...)
+}
+
+// compileRoles compiles the permissions of every role.
+func (r *Registry) compileRoles() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, scope := range r.scopes {
+		for _, role := range scope.roles {
+			role.compiledPermissions()
+		}
+	}
+}
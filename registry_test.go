@@ -121,3 +121,31 @@ func TestRegistryFluentAPIBasic(t *testing.T) {
 	assert.NotNil(t, r.GetRole("owner", "organization"))
 	assert.NotNil(t, r.GetRole("editor", "project"))
 }
+
+// TestRegistryCanAssignIn tests cross-scope assignment rules
+func TestRegistryCanAssignIn(t *testing.T) {
+	r := NewRegistry()
+	r.DefineScope("organization").
+		Role("admin").CanAssign("member").CanAssignIn("project", "editor", "viewer").
+		Role("owner").CanAssignIn("project", "*").
+		Role("member")
+	r.DefineScope("project").ParentScope("organization").
+		Role("editor").
+		Role("viewer")
+
+	admin := r.GetRole("admin", "organization")
+	assert.Equal(t, []string{"editor", "viewer"}, admin.GetCanAssignIn("project"))
+	assert.Empty(t, admin.GetCanAssignIn("team"))
+
+	assert.True(t, r.CanRoleAssignIn("admin", "organization", "editor", "project"))
+	assert.False(t, r.CanRoleAssignIn("admin", "organization", "admin", "project"))
+	assert.True(t, r.CanRoleAssignIn("owner", "organization", "admin", "project"))
+	assert.False(t, r.CanRoleAssignIn("member", "organization", "viewer", "project"))
+	assert.False(t, r.CanRoleAssignIn("unknown", "organization", "viewer", "project"))
+
+	// Same-scope rules are unaffected
+	assert.False(t, r.CanRoleAssign("admin", "editor", "organization"))
+
+	assert.True(t, r.HasCrossScopeAssigners("project"))
+	assert.False(t, r.HasCrossScopeAssigners("organization"))
+}
@@ -0,0 +1,24 @@
+// Command rolekit-vet reports scope types, roles and permissions passed as
+// constant strings to rolekit but missing from the registry definition.
+//
+// Run it directly, with the registry definition used by rolekit-gen:
+//
+//	rolekit-vet -config roles.yaml ./...
+//
+// or as a go vet tool, with an absolute path since go vet runs it from each
+// package directory:
+//
+//	go vet -vettool=$(which rolekit-vet) -config=$PWD/roles.yaml ./...
+//
+// A registry defined in Go can be loaded with -pkg and -func instead of
+// -config; see rolekit-gen.
+package main
+
+import (
+	"github.com/fernandezvara/rolekit/rolekitvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(rolekitvet.Analyzer)
+}
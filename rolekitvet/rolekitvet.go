@@ -0,0 +1,250 @@
+// Package rolekitvet defines an analyzer that reports scope types, roles and
+// permissions passed as constant strings to rolekit APIs but missing from the
+// registry definition, so that a typo such as checker.HasPermission("file.upload",
+// "project", id) is caught by go vet instead of silently denying access.
+//
+// Arguments are recognised by the name of the rolekit parameter they are
+// passed to, so every API is covered: permission and permissions,
+// role, roles, targetRole and assignerRole, scopeType and the parent, child and
+// new-parent scope types. Roles are checked against the scope type of the same
+// call when it is a constant, and against every scope otherwise. Permissions
+// must be catalogued when the registry has a catalog, and granted by some role
+// otherwise; wildcard patterns are only checked against a catalog.
+//
+// The registry is read from a YAML definition (-config) or from a Go package
+// exposing a function that returns it (-pkg and -func), as in rolekit-gen.
+package rolekitvet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync"
+
+	"github.com/fernandezvara/rolekit"
+	"github.com/fernandezvara/rolekit/internal/definition"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// rolekitPath is the import path of the package whose calls are checked.
+const rolekitPath = "github.com/fernandezvara/rolekit"
+
+// Analyzer reports unknown scope types, roles and permissions in calls to
+// rolekit.
+var Analyzer = &analysis.Analyzer{
+	Name:     "rolekit",
+	Doc:      "report unknown scope types, roles and permissions passed to rolekit",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	configFlag string
+	pkgFlag    string
+	funcFlag   string
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&configFlag, "config", "", "YAML registry definition")
+	Analyzer.Flags.StringVar(&pkgFlag, "pkg", "", "import path of a Go package defining the registry")
+	Analyzer.Flags.StringVar(&funcFlag, "func", "Registry", "function of -pkg returning the *rolekit.Registry")
+}
+
+// Kinds of names a parameter takes.
+type nameKind int
+
+const (
+	kindNone nameKind = iota
+	kindScope
+	kindRole
+	kindPermission
+)
+
+// paramKinds maps rolekit parameter names to the kind of name they take.
+var paramKinds = map[string]nameKind{
+	"scopeType":       kindScope,
+	"parentScopeType": kindScope,
+	"childScopeType":  kindScope,
+	"newParentType":   kindScope,
+	"role":            kindRole,
+	"roles":           kindRole,
+	"targetRole":      kindRole,
+	"assignerRole":    kindRole,
+	"permission":      kindPermission,
+	"permissions":     kindPermission,
+}
+
+// registry is the loaded definition, shared by every package of a run.
+var registry struct {
+	once sync.Once
+	def  *definition.Definition
+	err  error
+}
+
+func loadDefinition() (*definition.Definition, error) {
+	registry.once.Do(func() {
+		switch {
+		case configFlag != "" && pkgFlag != "":
+			registry.err = fmt.Errorf("use either -config or -pkg, not both")
+		case configFlag != "":
+			registry.def, registry.err = definition.LoadYAML(configFlag)
+		case pkgFlag != "":
+			registry.def, registry.err = definition.LoadPackage(pkgFlag, funcFlag)
+		default:
+			registry.err = fmt.Errorf("one of -config or -pkg is required")
+		}
+	})
+	return registry.def, registry.err
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	def, err := loadDefinition()
+	if err != nil {
+		return nil, err
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != rolekitPath {
+			return
+		}
+		checkCall(pass, def, call, fn.Type().(*types.Signature))
+	})
+	return nil, nil
+}
+
+// literal is a constant string argument.
+type literal struct {
+	pos   token.Pos
+	value string
+}
+
+// checkCall reports the unknown names passed to a rolekit function.
+func checkCall(pass *analysis.Pass, def *definition.Definition, call *ast.CallExpr, sig *types.Signature) {
+	args := make(map[nameKind][]literal)
+	scopeType := ""
+	for i, arg := range call.Args {
+		param := parameter(sig, i)
+		if param == nil {
+			continue
+		}
+		kind := paramKinds[param.Name()]
+		if kind == kindNone {
+			continue
+		}
+		for _, lit := range literals(pass, arg) {
+			args[kind] = append(args[kind], lit)
+			if param.Name() == "scopeType" {
+				scopeType = lit.value
+			}
+		}
+	}
+
+	for _, lit := range args[kindScope] {
+		if _, ok := def.Scopes[lit.value]; !ok {
+			pass.Reportf(lit.pos, "unknown scope type %q", lit.value)
+		}
+	}
+	for _, lit := range args[kindRole] {
+		if scope, ok := def.Scopes[scopeType]; ok {
+			if _, ok := scope.Roles[lit.value]; !ok {
+				pass.Reportf(lit.pos, "role %q is not defined in scope %q", lit.value, scopeType)
+			}
+		} else if !hasRole(def, lit.value) {
+			pass.Reportf(lit.pos, "unknown role %q", lit.value)
+		}
+	}
+	for _, lit := range args[kindPermission] {
+		if msg := checkPermission(def, lit.value); msg != "" {
+			pass.Reportf(lit.pos, "%s", msg)
+		}
+	}
+}
+
+// parameter returns the parameter receiving the i-th argument of a call. All
+// trailing arguments of a variadic call go to its last parameter.
+func parameter(sig *types.Signature, i int) *types.Var {
+	params := sig.Params()
+	n := params.Len()
+	if sig.Variadic() && i >= n-1 {
+		return params.At(n - 1)
+	}
+	if i < n {
+		return params.At(i)
+	}
+	return nil
+}
+
+// literals returns the constant strings of an argument: the argument itself,
+// or the elements of a slice literal.
+func literals(pass *analysis.Pass, arg ast.Expr) []literal {
+	if lit, ok := constantString(pass, arg); ok {
+		return []literal{lit}
+	}
+	composite, ok := ast.Unparen(arg).(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	var result []literal
+	for _, elt := range composite.Elts {
+		if lit, ok := constantString(pass, elt); ok {
+			result = append(result, lit)
+		}
+	}
+	return result
+}
+
+func constantString(pass *analysis.Pass, expr ast.Expr) (literal, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return literal{}, false
+	}
+	return literal{pos: expr.Pos(), value: constant.StringVal(tv.Value)}, true
+}
+
+func hasRole(def *definition.Definition, role string) bool {
+	for _, scope := range def.Scopes {
+		if _, ok := scope.Roles[role]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPermission returns why a permission is unknown, or "" when it is known.
+func checkPermission(def *definition.Definition, permission string) string {
+	if len(def.Permissions) > 0 {
+		if !strings.Contains(permission, "*") {
+			if _, ok := def.Permissions[permission]; !ok {
+				return fmt.Sprintf("unknown permission %q: not in the catalog", permission)
+			}
+			return ""
+		}
+		for name := range def.Permissions {
+			if rolekit.MatchPermission(permission, name) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("permission pattern %q matches no catalogued permission", permission)
+	}
+
+	if strings.Contains(permission, "*") {
+		return ""
+	}
+	for _, scope := range def.Scopes {
+		for _, role := range scope.Roles {
+			if rolekit.MatchAnyPermission(role.Permissions, permission) {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("unknown permission %q: no role grants it", permission)
+}
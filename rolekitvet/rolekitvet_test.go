@@ -0,0 +1,28 @@
+package rolekitvet
+
+import (
+	"testing"
+
+	"github.com/fernandezvara/rolekit/internal/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	require.NoError(t, Analyzer.Flags.Set("config", "testdata/roles.yaml"))
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestCheckPermissionWithoutCatalog(t *testing.T) {
+	def := &definition.Definition{Scopes: map[string]definition.ScopeDefinition{
+		"project": {Roles: map[string]definition.RoleDefinition{
+			"editor": {Permissions: []string{"files.**", "members.read"}},
+		}},
+	}}
+
+	assert.Empty(t, checkPermission(def, "files.upload"))
+	assert.Empty(t, checkPermission(def, "files.versions.restore"))
+	assert.Empty(t, checkPermission(def, "members.*"), "patterns need a catalog to be checked")
+	assert.Equal(t, `unknown permission "members.invite": no role grants it`, checkPermission(def, "members.invite"))
+}
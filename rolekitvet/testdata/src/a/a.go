@@ -0,0 +1,33 @@
+package a
+
+import (
+	"context"
+
+	"github.com/fernandezvara/rolekit"
+)
+
+const upload = "files.upload"
+
+func checks(ctx context.Context, checker *rolekit.Checker, service *rolekit.Service, m *rolekit.Middleware, id string) {
+	checker.HasPermission("files.read", "project", id)
+	checker.HasPermission(upload, "organization", id)
+	checker.HasPermission("file.upload", "project", id) // want `unknown permission "file.upload": not in the catalog`
+	checker.HasPermission("files.read", "projct", id)   // want `unknown scope type "projct"`
+	checker.HasPermission(id, "project", id)
+
+	checker.HasAnyPermission([]string{"files.read", "files.delete"}, "project", id) // want `unknown permission "files.delete": not in the catalog`
+	checker.HasAnyPermission([]string{"files.*", "billing.*"}, "project", id)       // want `permission pattern "billing.\*" matches no catalogued permission`
+
+	service.Can(ctx, id, "admin", "organization", id)
+	service.Can(ctx, id, "admin", "project", id) // want `role "admin" is not defined in scope "project"`
+	service.Assign(ctx, id, "viewer", "project", id)
+	service.Assign(ctx, id, "viwer", "project", id) // want `role "viwer" is not defined in scope "project"`
+	checker.HasRoleInAnyScope("viewer", id)
+	checker.HasRoleInAnyScope("owner", id) // want `unknown role "owner"`
+
+	rolekit.CopyRoles("admin", "ownr") // want `unknown role "ownr"`
+
+	m.RequirePermission("files.upload", rolekit.ScopeFromParam("project", "id"))
+	m.RequirePermission("files.uplaod", rolekit.ScopeFromParam("project", "id"))   // want `unknown permission "files.uplaod": not in the catalog`
+	m.RequireRole("project_manager", rolekit.ScopeFromParam("organisation", "id")) // want `unknown scope type "organisation"`
+}
@@ -0,0 +1,45 @@
+// Package rolekit is a stub of the rolekit API for the analyzer tests.
+package rolekit
+
+import (
+	"context"
+	"net/http"
+)
+
+type Checker struct{}
+
+func (c *Checker) HasPermission(permission, scopeType, scopeID string) bool { return false }
+
+func (c *Checker) HasAnyPermission(permissions []string, scopeType, scopeID string) bool {
+	return false
+}
+
+func (c *Checker) HasRoleInAnyScope(role, scopeType string) bool { return false }
+
+type Service struct{}
+
+func (s *Service) Can(ctx context.Context, userID, role, scopeType, scopeID string) bool {
+	return false
+}
+
+func (s *Service) Assign(ctx context.Context, userID, role, scopeType, scopeID string) error {
+	return nil
+}
+
+type CopyOption func()
+
+func CopyRoles(roles ...string) CopyOption { return nil }
+
+type ScopeExtractor func(*http.Request) (string, string, error)
+
+func ScopeFromParam(scopeType, paramName string) ScopeExtractor { return nil }
+
+type Middleware struct{}
+
+func (m *Middleware) RequireRole(role string, extractor ScopeExtractor) func(http.Handler) http.Handler {
+	return nil
+}
+
+func (m *Middleware) RequirePermission(permission string, extractor ScopeExtractor) func(http.Handler) http.Handler {
+	return nil
+}
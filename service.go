@@ -37,6 +37,8 @@ type Service struct {
 	db        dbkit.IDB
 	registry  *Registry
 	txMonitor *transactionMonitor
+
+	breakGlass *BreakGlassConfig
 }
 
 // NewService creates a new RoleKit service.
@@ -48,6 +50,9 @@ type Service struct {
 //	db, _ := dbkit.New(dbkit.Config{URL: "postgres://..."})
 //	service := rolekit.NewService(registry, db)
 func NewService(registry *Registry, db dbkit.IDB) *Service {
+	if registry != nil {
+		registry.compileRoles()
+	}
 	return &Service{
 		db:        db,
 		registry:  registry,
@@ -67,7 +72,7 @@ func (s *Service) Registry() *Registry {
 // GetAuditLog retrieves audit log entries with optional filters.
 func (s *Service) GetAuditLog(ctx context.Context, filter AuditLogFilter) ([]RoleAuditLog, error) {
 	var logs []RoleAuditLog
-	q := s.db.NewSelect().Model(&logs)
+	q := s.conn(ctx).NewSelect().Model(&logs)
 	if filter.ActorID != "" {
 		q = q.Where("actor_id = ?", filter.ActorID)
 	}
@@ -83,6 +88,15 @@ func (s *Service) GetAuditLog(ctx context.Context, filter AuditLogFilter) ([]Rol
 	if filter.Action != "" {
 		q = q.Where("action = ?", filter.Action)
 	}
+	if filter.CorrelationID != "" {
+		q = q.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.ImpersonatedUserID != "" {
+		q = q.Where("impersonated_user_id = ?", filter.ImpersonatedUserID)
+	}
+	if filter.Severity != "" {
+		q = q.Where("severity = ?", filter.Severity)
+	}
 	if !filter.Since.IsZero() {
 		q = q.Where("timestamp >= ?", filter.Since)
 	}
@@ -0,0 +1,440 @@
+package rolekit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fernandezvara/dbkit"
+	"github.com/uptrace/bun"
+)
+
+// ============================================================================
+// ACCESS REQUESTS (JUST-IN-TIME ELEVATION)
+// ============================================================================
+
+// RequestRole asks for temporary elevation to a role. The requester is the
+// actor in the context; the request stays pending until a user who can assign
+// the role approves or denies it.
+//
+// Example:
+//
+//	ctx = rolekit.WithActorID(ctx, engineerID)
+//	req, err := service.RequestRole(ctx, "admin", "project", "prod",
+//	    2*time.Hour, "INC-1234: restart stuck workers")
+func (s *Service) RequestRole(ctx context.Context, role, scopeType, scopeID string, duration time.Duration, reason string) (*AccessRequest, error) {
+	// Validate role exists for scope
+	if err := s.registry.ValidateRole(role, scopeType); err != nil {
+		return nil, err
+	}
+
+	userID := GetActorID(ctx)
+	if userID == "" {
+		return nil, NewError(ErrNoActorID, "actor ID required for access request")
+	}
+	if duration <= 0 {
+		return nil, NewError(ErrInvalidAccessRequest, "duration must be positive").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithUser(userID)
+	}
+	if reason == "" {
+		return nil, NewError(ErrInvalidAccessRequest, "reason is required").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithUser(userID)
+	}
+
+	request := &AccessRequest{
+		UserID:    userID,
+		Role:      role,
+		ScopeType: scopeType,
+		ScopeID:   scopeID,
+		Reason:    reason,
+		Duration:  duration,
+		Status:    AccessRequestPending,
+	}
+
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		currentRoles, err := s.getUserRoleNames(ctx, userID, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		if containsString(currentRoles, role) {
+			return NewError(ErrRoleAlreadyAssigned, "user already has this role").
+				WithScope(scopeType, scopeID).
+				WithRole(role).
+				WithUser(userID)
+		}
+
+		pending, err := dbkit.Exists[AccessRequest](ctx, s.conn(ctx), func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("user_id = ? AND role = ? AND scope_type = ? AND scope_id = ? AND status = ?",
+				userID, role, scopeType, scopeID, AccessRequestPending)
+		})
+		if err != nil {
+			return err
+		}
+		if pending {
+			return NewError(ErrInvalidAccessRequest, "a request for this role is already pending").
+				WithScope(scopeType, scopeID).
+				WithRole(role).
+				WithUser(userID)
+		}
+
+		result, err := s.conn(ctx).NewInsert().Model(request).Returning("*").Exec(ctx)
+		if err = dbkit.WithErr(result, err, "CreateAccessRequest").Err(); err != nil {
+			return err
+		}
+
+		return s.logAudit(ctx, s.accessRequestAudit(ctx, AuditActionAccessRequested, request, userID, currentRoles, currentRoles,
+			map[string]any{"reason": reason, "duration": duration.String()}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ApproveRequest approves a pending access request and assigns the role until
+// the requested duration has elapsed. The approver is the actor in the context
+// and must be able to assign the role; requesters cannot approve their own
+// requests. Separation-of-duties, member limits and the escalation guard apply
+// as for Assign.
+//
+// Example:
+//
+//	ctx = rolekit.WithActorID(ctx, managerID)
+//	req, err := service.ApproveRequest(ctx, requestID, "approved for INC-1234")
+func (s *Service) ApproveRequest(ctx context.Context, requestID, comment string) (*AccessRequest, error) {
+	var request *AccessRequest
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		var approverRoles *UserRoles
+		var err error
+		request, approverRoles, err = s.authorizeDecision(ctx, requestID)
+		if err != nil {
+			return err
+		}
+
+		previousRoles, err := s.getUserRoleNames(ctx, request.UserID, request.ScopeType, request.ScopeID)
+		if err != nil {
+			return err
+		}
+		if containsString(previousRoles, request.Role) {
+			return NewError(ErrRoleAlreadyAssigned, "user already has this role").
+				WithScope(request.ScopeType, request.ScopeID).
+				WithRole(request.Role).
+				WithUser(request.UserID)
+		}
+
+		if err := s.checkConstraints(ctx, request.UserID, request.Role, request.ScopeType, request.ScopeID, nil); err != nil {
+			return err
+		}
+		if err := s.checkCardinality(ctx, request.Role, request.ScopeType, request.ScopeID, 1); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(request.Duration)
+		if err := s.insertExpiringAssignment(ctx, request.UserID, request.Role, request.ScopeType, request.ScopeID, &expiresAt); err != nil {
+			return err
+		}
+
+		if err := s.decideRequest(ctx, request, AccessRequestApproved, approverRoles.UserID, comment, now, &expiresAt); err != nil {
+			return err
+		}
+
+		entry := s.accessRequestAudit(ctx, AuditActionAccessApproved, request, approverRoles.UserID,
+			previousRoles, append(append([]string{}, previousRoles...), request.Role),
+			map[string]any{"comment": comment, "expires_at": expiresAt})
+		entry.ActorRoles = approverRoles.GetRoles(request.ScopeType, request.ScopeID)
+		return s.logAudit(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// DenyRequest denies a pending access request. The actor in the context must
+// be able to assign the requested role.
+//
+// Example:
+//
+//	req, err := service.DenyRequest(ctx, requestID, "use the read-only role instead")
+func (s *Service) DenyRequest(ctx context.Context, requestID, reason string) (*AccessRequest, error) {
+	var request *AccessRequest
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		var approverRoles *UserRoles
+		var err error
+		request, approverRoles, err = s.authorizeDecision(ctx, requestID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.decideRequest(ctx, request, AccessRequestDenied, approverRoles.UserID, reason, time.Now(), nil); err != nil {
+			return err
+		}
+
+		entry := s.accessRequestAudit(ctx, AuditActionAccessDenied, request, approverRoles.UserID, nil, nil,
+			map[string]any{"reason": reason})
+		entry.ActorRoles = approverRoles.GetRoles(request.ScopeType, request.ScopeID)
+		return s.logAudit(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// GetAccessRequest retrieves an access request by ID.
+func (s *Service) GetAccessRequest(ctx context.Context, requestID string) (*AccessRequest, error) {
+	request := new(AccessRequest)
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(request).Where("id = ?", requestID).Scan(ctx), "GetAccessRequest").Err()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, NewError(ErrAccessRequestNotFound, "access request "+requestID+" not found")
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListPendingRequests returns the pending access requests for a scope, oldest first.
+//
+// Example:
+//
+//	requests, err := service.ListPendingRequests(ctx, "project", projectID)
+func (s *Service) ListPendingRequests(ctx context.Context, scopeType, scopeID string) ([]AccessRequest, error) {
+	var requests []AccessRequest
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&requests).
+		Where("scope_type = ? AND scope_id = ? AND status = ?", scopeType, scopeID, AccessRequestPending).
+		Order("requested_at ASC").
+		Scan(ctx), "ListPendingRequests").Err()
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ListPendingRequestsForApprover returns the pending access requests the user
+// can decide: requests by other users for roles the user can assign. The
+// requests are selected in SQL by the scopes and roles the user can assign in,
+// including descendant scopes reached through CanAssignIn. Roles held in every
+// scope of a type ("*") match requests in any scope of the types they can
+// assign into. When the registry prevents escalation, requests for roles that
+// grant permissions the user does not hold are left out, as ApproveRequest
+// would reject them.
+//
+// Example:
+//
+//	inbox, err := service.ListPendingRequestsForApprover(ctx, managerID)
+func (s *Service) ListPendingRequestsForApprover(ctx context.Context, approverID string) ([]AccessRequest, error) {
+	approverRoles, err := s.GetUserRoles(ctx, approverID)
+	if err != nil {
+		return nil, err
+	}
+	checker := s.newChecker(ctx, approverID, approverRoles)
+	inScope, inAnyScope, err := s.assignableRoleTuples(ctx, checker)
+	if err != nil {
+		return nil, err
+	}
+	if len(inScope) == 0 && len(inAnyScope) == 0 {
+		return nil, nil
+	}
+
+	var requests []AccessRequest
+	err = dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&requests).
+		Where("status = ? AND user_id <> ?", AccessRequestPending, approverID).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			if len(inScope) > 0 {
+				q = q.WhereOr("(scope_type, scope_id, role) IN (?)", bun.In(inScope))
+			}
+			if len(inAnyScope) > 0 {
+				q = q.WhereOr("(scope_type, role) IN (?)", bun.In(inAnyScope))
+			}
+			return q
+		}).
+		Order("requested_at ASC").
+		Scan(ctx), "ListPendingRequestsForApprover").Err()
+	if err != nil {
+		return nil, err
+	}
+	if !s.registry.IsEscalationPrevented() {
+		return requests, nil
+	}
+
+	// Apply the escalation policy like authorizeDecision, once per role and scope
+	type target struct{ role, scopeType, scopeID string }
+	escalates := make(map[target]bool)
+	decidable := requests[:0]
+	for _, request := range requests {
+		t := target{request.Role, request.ScopeType, request.ScopeID}
+		escalating, ok := escalates[t]
+		if !ok {
+			missing, err := checker.EscalatingPermissionsContext(ctx, t.role, t.scopeType, t.scopeID)
+			if err != nil {
+				return nil, err
+			}
+			escalating = len(missing) > 0
+			escalates[t] = escalating
+		}
+		if !escalating {
+			decidable = append(decidable, request)
+		}
+	}
+	return decidable, nil
+}
+
+// assignableRoleTuples returns the roles the checker's user can assign, as
+// (scope type, scope ID, role) tuples and, for roles held in every scope of a
+// type, (scope type, role) tuples. Descendant scopes are looked up only for
+// roles with CanAssignIn rules.
+func (s *Service) assignableRoleTuples(ctx context.Context, checker *Checker) (inScope, inAnyScope [][]string, err error) {
+	add := func(scopeType, scopeID string, roles []string) {
+		for _, role := range roles {
+			if scopeID == "*" {
+				inAnyScope = append(inAnyScope, []string{scopeType, role})
+			} else {
+				inScope = append(inScope, []string{scopeType, scopeID, role})
+			}
+		}
+	}
+
+	held := make(map[Scope][]string)
+	var scopes []Scope
+	for _, a := range checker.roles.active() {
+		scope := NewScope(a.ScopeType, a.ScopeID)
+		if _, ok := held[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+		held[scope] = append(held[scope], a.Role)
+	}
+
+	for _, scope := range scopes {
+		add(scope.Type, scope.ID, checker.assignableRoles(scope.Type, scope.ID, nil))
+
+		// Roles assignable in descendant scopes, per descendant scope type.
+		// Roles held in every scope of the type are covered by their own entry.
+		childRoles := make(map[string][]string)
+		for _, role := range held[scope] {
+			roleDef := s.registry.GetRole(role, scope.Type)
+			if roleDef == nil {
+				continue
+			}
+			for childType, roles := range roleDef.canAssignIn {
+				if childScope := s.registry.GetScope(childType); childScope != nil {
+					childRoles[childType] = append(childRoles[childType], expandRoleNames(childScope, roles)...)
+				}
+			}
+		}
+		if len(childRoles) == 0 {
+			continue
+		}
+		if scope.ID == "*" {
+			for childType, roles := range childRoles {
+				add(childType, "*", roles)
+			}
+			continue
+		}
+
+		descendants, err := s.getDescendantScopes(ctx, scope.Type, scope.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, descendant := range descendants {
+			add(descendant.Type, descendant.ID, childRoles[descendant.Type])
+		}
+	}
+	return inScope, inAnyScope, nil
+}
+
+// authorizeDecision locks a pending request and verifies the actor may decide it.
+// It must run inside a transaction.
+func (s *Service) authorizeDecision(ctx context.Context, requestID string) (*AccessRequest, *UserRoles, error) {
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return nil, nil, NewError(ErrNoActorID, "actor ID required to decide an access request")
+	}
+
+	request := new(AccessRequest)
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(request).Where("id = ?", requestID).For("UPDATE").Scan(ctx), "GetAccessRequest").Err()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, NewError(ErrAccessRequestNotFound, "access request "+requestID+" not found").WithActor(actorID)
+		}
+		return nil, nil, err
+	}
+
+	if request.Status != AccessRequestPending {
+		return nil, nil, NewError(ErrInvalidAccessRequest, "access request is already "+string(request.Status)).
+			WithScope(request.ScopeType, request.ScopeID).
+			WithRole(request.Role).
+			WithUser(request.UserID).
+			WithActor(actorID)
+	}
+
+	if request.UserID == actorID {
+		return nil, nil, NewError(ErrCannotAssign, "requesters cannot decide their own access request").
+			WithScope(request.ScopeType, request.ScopeID).
+			WithRole(request.Role).
+			WithActor(actorID)
+	}
+
+	actorRoles, err := s.GetUserRoles(ctx, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	actorChecker := s.newChecker(ctx, actorID, actorRoles)
+	canAssign, err := actorChecker.CanAssignRoleContext(ctx, request.Role, request.ScopeType, request.ScopeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canAssign {
+		return nil, nil, NewError(ErrCannotAssign, "actor cannot approve this role").
+			WithScope(request.ScopeType, request.ScopeID).
+			WithRole(request.Role).
+			WithActor(actorID)
+	}
+	if err := s.checkEscalation(ctx, actorChecker, request.Role, request.ScopeType, request.ScopeID); err != nil {
+		return nil, nil, err
+	}
+
+	return request, actorRoles, nil
+}
+
+// decideRequest records the outcome of an access request.
+func (s *Service) decideRequest(ctx context.Context, request *AccessRequest, status AccessRequestStatus, deciderID, reason string, decidedAt time.Time, expiresAt *time.Time) error {
+	request.Status = status
+	request.DecidedBy = deciderID
+	request.DecisionReason = reason
+	request.DecidedAt = &decidedAt
+	request.ExpiresAt = expiresAt
+
+	result, err := s.conn(ctx).NewUpdate().Model(request).
+		Column("status", "decided_by", "decision_reason", "decided_at", "expires_at").
+		WherePK().
+		Exec(ctx)
+	return dbkit.WithErr(result, err, "DecideAccessRequest").Err()
+}
+
+// accessRequestAudit builds the audit entry for a state change of an access request.
+// The request ID is used as correlation ID so the whole lifecycle can be queried.
+func (s *Service) accessRequestAudit(ctx context.Context, action AuditAction, request *AccessRequest, actorID string, previousRoles, newRoles []string, metadata map[string]any) *AuditEntry {
+	audit := GetAuditContext(ctx)
+	return &AuditEntry{
+		ActorID:       actorID,
+		Action:        action,
+		TargetUserID:  request.UserID,
+		Role:          request.Role,
+		ScopeType:     request.ScopeType,
+		ScopeID:       request.ScopeID,
+		PreviousRoles: previousRoles,
+		NewRoles:      newRoles,
+		IPAddress:     audit.IPAddress,
+		UserAgent:     audit.UserAgent,
+		RequestID:     audit.RequestID,
+		CorrelationID: request.ID,
+		Metadata:      metadata,
+	}
+}
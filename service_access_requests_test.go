@@ -0,0 +1,198 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceRequestRoleValidation tests argument validation before any database access
+func TestServiceRequestRoleValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("project").
+		Role("admin").Permissions("*").
+		Role("viewer").Permissions("*.read")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "engineer1")
+
+	t.Run("Invalid role", func(t *testing.T) {
+		_, err := service.RequestRole(ctx, "unknown", "project", "prod", time.Hour, "incident")
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, err := service.RequestRole(context.Background(), "admin", "project", "prod", time.Hour, "incident")
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("Non-positive duration", func(t *testing.T) {
+		_, err := service.RequestRole(ctx, "admin", "project", "prod", 0, "incident")
+		assert.True(t, IsInvalidAccessRequest(err))
+	})
+
+	t.Run("Missing reason", func(t *testing.T) {
+		_, err := service.RequestRole(ctx, "admin", "project", "prod", time.Hour, "")
+		assert.True(t, IsInvalidAccessRequest(err))
+	})
+}
+
+// TestServiceAssignableRoleTuples tests the approver inbox filter without descendant scopes
+func TestServiceAssignableRoleTuples(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("admin").Permissions("*").CanAssign("member").CanAssignIn("project", "viewer").
+		Role("member").Permissions("*.read")
+	registry.DefineScope("project").ParentScope("organization").
+		Role("viewer").Permissions("*.read")
+	service := &Service{registry: registry}
+	assignments := []RoleAssignment{
+		{UserID: "admin1", Role: "admin", ScopeType: "organization", ScopeID: "*"},
+		{UserID: "admin1", Role: "member", ScopeType: "organization", ScopeID: "org1"},
+	}
+	checker := NewChecker("admin1", NewUserRoles("admin1", assignments), registry, service)
+
+	inScope, inAnyScope, err := service.assignableRoleTuples(context.Background(), checker)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"organization", "org1", "member"}}, inScope)
+	assert.ElementsMatch(t, [][]string{{"organization", "member"}, {"project", "viewer"}}, inAnyScope)
+}
+
+// TestServiceAccessRequestWorkflowDatabase tests request, approval and expiry with real database
+func TestServiceAccessRequestWorkflowDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	engineerID := helper.CreateTestUser("engineer")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+
+	engineerCtx := WithActorID(helper.GetContext(), engineerID)
+	adminCtx := WithActorID(helper.GetContext(), adminID)
+
+	request, err := service.RequestRole(engineerCtx, "admin", "organization", orgID, time.Hour, "INC-1: restart workers")
+	require.NoError(t, err)
+	assert.Equal(t, AccessRequestPending, request.Status)
+	helper.AssertRoleNotAssigned(engineerID, "admin", "organization", orgID)
+
+	// A second request for the same role is rejected while the first is pending
+	_, err = service.RequestRole(engineerCtx, "admin", "organization", orgID, time.Hour, "again")
+	assert.True(t, IsInvalidAccessRequest(err))
+
+	pending, err := service.ListPendingRequests(adminCtx, "organization", orgID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, request.ID, pending[0].ID)
+
+	inbox, err := service.ListPendingRequestsForApprover(adminCtx, adminID)
+	require.NoError(t, err)
+	assert.Len(t, inbox, 1)
+
+	// Requesters see nothing to approve and cannot approve themselves
+	inbox, err = service.ListPendingRequestsForApprover(engineerCtx, engineerID)
+	require.NoError(t, err)
+	assert.Empty(t, inbox)
+	_, err = service.ApproveRequest(engineerCtx, request.ID, "self")
+	assert.True(t, IsCannotAssign(err))
+
+	approved, err := service.ApproveRequest(adminCtx, request.ID, "go ahead")
+	require.NoError(t, err)
+	assert.Equal(t, AccessRequestApproved, approved.Status)
+	assert.Equal(t, adminID, approved.DecidedBy)
+	require.NotNil(t, approved.ExpiresAt)
+	helper.AssertRoleAssigned(engineerID, "admin", "organization", orgID)
+
+	// Decisions are final
+	_, err = service.DenyRequest(adminCtx, request.ID, "too late")
+	assert.True(t, IsInvalidAccessRequest(err))
+
+	logs, err := service.GetAuditLog(adminCtx, NewAuditLogFilter().WithCorrelationID(request.ID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	// Once expired, the assignment no longer counts and is cleaned up
+	_, err = service.conn(adminCtx).NewUpdate().Table("role_assignments").
+		Set("expires_at = ?", time.Now().Add(-time.Minute)).
+		Where("user_id = ?", engineerID).
+		Exec(adminCtx)
+	require.NoError(t, err)
+	helper.AssertRoleNotAssigned(engineerID, "admin", "organization", orgID)
+
+	expired, err := service.RevokeExpired(adminCtx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, expired)
+}
+
+// TestServiceDenyRequestDatabase tests denying an access request with real database
+func TestServiceDenyRequestDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	engineerID := helper.CreateTestUser("engineer")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+
+	request, err := service.RequestRole(WithActorID(helper.GetContext(), engineerID), "admin", "organization", orgID, time.Hour, "curious")
+	require.NoError(t, err)
+
+	denied, err := service.DenyRequest(WithActorID(helper.GetContext(), adminID), request.ID, "not needed")
+	require.NoError(t, err)
+	assert.Equal(t, AccessRequestDenied, denied.Status)
+	assert.Equal(t, "not needed", denied.DecisionReason)
+	helper.AssertRoleNotAssigned(engineerID, "admin", "organization", orgID)
+
+	_, err = service.GetAccessRequest(helper.GetContext(), "00000000-0000-0000-0000-000000000000")
+	assert.True(t, IsAccessRequestNotFound(err))
+}
+
+// TestServiceApproverInboxEscalationDatabase tests that the approver inbox
+// follows the escalation policy with real database
+func TestServiceApproverInboxEscalationDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	rootID := helper.CreateTestUser("root")
+	approverID := helper.CreateTestUser("approver")
+	engineerID := helper.CreateTestUser("engineer")
+	require.NoError(t, helper.SetupAdminUser(rootID, orgID))
+	require.NoError(t, service.Assign(WithActorID(helper.GetContext(), rootID), approverID, "admin", "organization", orgID))
+
+	// Admins can assign project_manager, whose task.* they do not hold
+	_, err := service.RequestRole(WithActorID(helper.GetContext(), engineerID), "project_manager", "organization", orgID, time.Hour, "release")
+	require.NoError(t, err)
+
+	approverCtx := WithActorID(helper.GetContext(), approverID)
+	inbox, err := service.ListPendingRequestsForApprover(approverCtx, approverID)
+	require.NoError(t, err)
+	require.Len(t, inbox, 1)
+
+	service.Registry().PreventEscalation()
+	defer func() { service.Registry().noEscalation = false }()
+
+	_, err = service.ApproveRequest(approverCtx, inbox[0].ID, "ok")
+	assert.True(t, IsPrivilegeEscalation(err))
+	inbox, err = service.ListPendingRequestsForApprover(approverCtx, approverID)
+	require.NoError(t, err)
+	assert.Empty(t, inbox)
+}
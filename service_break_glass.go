@@ -0,0 +1,160 @@
+package rolekit
+
+import (
+	"context"
+	"time"
+)
+
+// ============================================================================
+// BREAK-GLASS EMERGENCY ACCESS
+// ============================================================================
+
+// DefaultBreakGlassDuration is the emergency access window used when
+// BreakGlassConfig.Duration is not set.
+const DefaultBreakGlassDuration = time.Hour
+
+// BreakGlassConfig configures emergency access.
+type BreakGlassConfig struct {
+	// Role granted by BreakGlass; it must be defined for the requested scope type
+	Role string
+
+	// Fixed window after which the emergency assignment expires
+	Duration time.Duration
+
+	// User IDs allowed to break the glass
+	Principals []string
+
+	// Optional hook called after emergency access is granted (e.g. to page on-call)
+	Notify func(ctx context.Context, event BreakGlassEvent)
+}
+
+// BreakGlassEvent describes a granted emergency access.
+type BreakGlassEvent struct {
+	UserID    string
+	Role      string
+	ScopeType string
+	ScopeID   string
+	Reason    string
+	ExpiresAt time.Time
+}
+
+// EnableBreakGlass turns on emergency access for the configured principals.
+// Call it during setup, before the service handles requests.
+//
+// Example:
+//
+//	service.EnableBreakGlass(rolekit.BreakGlassConfig{
+//	    Role:       "owner",
+//	    Duration:   30 * time.Minute,
+//	    Principals: []string{"sre-oncall-1", "sre-oncall-2"},
+//	    Notify: func(ctx context.Context, e rolekit.BreakGlassEvent) {
+//	        pager.Alert("break-glass by %s in %s/%s: %s", e.UserID, e.ScopeType, e.ScopeID, e.Reason)
+//	    },
+//	})
+func (s *Service) EnableBreakGlass(config BreakGlassConfig) *Service {
+	if config.Duration <= 0 {
+		config.Duration = DefaultBreakGlassDuration
+	}
+	config.Principals = append([]string{}, config.Principals...)
+	s.breakGlass = &config
+	return s
+}
+
+// BreakGlass grants the configured emergency role to the actor in the context
+// for the configured window. It bypasses CanAssignRole and member limits (the
+// emergency role is often capped, e.g. a single owner), but still enforces
+// separation-of-duties constraints. The grant is written to the audit log with
+// high severity. The assignment stops granting the role when it expires, also
+// through checkers created before; the row is deleted and the expiry audited
+// only by RevokeExpired, which the application must run periodically.
+//
+// Example:
+//
+//	ctx = rolekit.WithActorID(ctx, "sre-oncall-1")
+//	expiresAt, err := service.BreakGlass(ctx, "organization", orgID, "owner locked out, INC-4321")
+func (s *Service) BreakGlass(ctx context.Context, scopeType, scopeID, reason string) (time.Time, error) {
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return time.Time{}, NewError(ErrNoActorID, "actor ID required for break-glass access")
+	}
+
+	config := s.breakGlass
+	if config == nil {
+		return time.Time{}, NewError(ErrBreakGlassDenied, "break-glass access is not enabled").
+			WithScope(scopeType, scopeID).
+			WithActor(actorID)
+	}
+	if !containsString(config.Principals, actorID) {
+		return time.Time{}, NewError(ErrBreakGlassDenied, "user is not a break-glass principal").
+			WithScope(scopeType, scopeID).
+			WithRole(config.Role).
+			WithActor(actorID)
+	}
+	if reason == "" {
+		return time.Time{}, NewError(ErrBreakGlassDenied, "reason is required").
+			WithScope(scopeType, scopeID).
+			WithRole(config.Role).
+			WithActor(actorID)
+	}
+
+	// Validate role exists for scope
+	if err := s.registry.ValidateRole(config.Role, scopeType); err != nil {
+		return time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(config.Duration)
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		previousRoles, err := s.getUserRoleNames(ctx, actorID, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		if containsString(previousRoles, config.Role) {
+			return NewError(ErrRoleAlreadyAssigned, "user already has this role").
+				WithScope(scopeType, scopeID).
+				WithRole(config.Role).
+				WithUser(actorID)
+		}
+
+		if err := s.checkConstraints(ctx, actorID, config.Role, scopeType, scopeID, nil); err != nil {
+			return err
+		}
+
+		if err := s.insertExpiringAssignment(ctx, actorID, config.Role, scopeType, scopeID, &expiresAt); err != nil {
+			return err
+		}
+
+		audit := GetAuditContext(ctx)
+		return s.logAudit(ctx, &AuditEntry{
+			ActorID:       actorID,
+			Action:        AuditActionBreakGlass,
+			TargetUserID:  actorID,
+			Role:          config.Role,
+			ScopeType:     scopeType,
+			ScopeID:       scopeID,
+			ActorRoles:    previousRoles,
+			PreviousRoles: previousRoles,
+			NewRoles:      append(append([]string{}, previousRoles...), config.Role),
+			IPAddress:     audit.IPAddress,
+			UserAgent:     audit.UserAgent,
+			RequestID:     audit.RequestID,
+			Severity:      AuditSeverityHigh,
+			Metadata:      map[string]any{"reason": reason, "expires_at": expiresAt},
+		})
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if config.Notify != nil {
+		config.Notify(ctx, BreakGlassEvent{
+			UserID:    actorID,
+			Role:      config.Role,
+			ScopeType: scopeType,
+			ScopeID:   scopeID,
+			Reason:    reason,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	return expiresAt, nil
+}
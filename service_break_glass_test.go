@@ -0,0 +1,119 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceBreakGlassValidation tests break-glass checks before any database access
+func TestServiceBreakGlassValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("owner").Permissions("*")
+	registry.DefineScope("project").
+		Role("viewer").Permissions("*.read")
+	ctx := WithActorID(context.Background(), "sre1")
+
+	t.Run("Disabled", func(t *testing.T) {
+		service := &Service{registry: registry}
+		_, err := service.BreakGlass(ctx, "organization", "org1", "incident")
+		assert.True(t, IsBreakGlassDenied(err))
+	})
+
+	service := (&Service{registry: registry}).EnableBreakGlass(BreakGlassConfig{
+		Role:       "owner",
+		Principals: []string{"sre1"},
+	})
+
+	t.Run("Default duration", func(t *testing.T) {
+		assert.Equal(t, DefaultBreakGlassDuration, service.breakGlass.Duration)
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, err := service.BreakGlass(context.Background(), "organization", "org1", "incident")
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("Not a principal", func(t *testing.T) {
+		_, err := service.BreakGlass(WithActorID(context.Background(), "dev1"), "organization", "org1", "incident")
+		assert.True(t, IsBreakGlassDenied(err))
+	})
+
+	t.Run("Missing reason", func(t *testing.T) {
+		_, err := service.BreakGlass(ctx, "organization", "org1", "")
+		assert.True(t, IsBreakGlassDenied(err))
+	})
+
+	t.Run("Role not defined in scope", func(t *testing.T) {
+		_, err := service.BreakGlass(ctx, "project", "proj1", "incident")
+		assert.True(t, IsInvalidRole(err))
+	})
+}
+
+// TestServiceBreakGlassDatabase tests emergency access with real database
+func TestServiceBreakGlassDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	sreID := helper.CreateTestUser("sre")
+
+	var events []BreakGlassEvent
+	service.EnableBreakGlass(BreakGlassConfig{
+		Role:       "super_admin",
+		Duration:   15 * time.Minute,
+		Principals: []string{sreID},
+		Notify: func(ctx context.Context, event BreakGlassEvent) {
+			events = append(events, event)
+		},
+	})
+	defer func() { service.breakGlass = nil }()
+
+	ctx := WithActorID(helper.GetContext(), sreID)
+	expiresAt, err := service.BreakGlass(ctx, "organization", orgID, "owner locked out")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, time.Minute)
+	helper.AssertRoleAssigned(sreID, "super_admin", "organization", orgID)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "owner locked out", events[0].Reason)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithScope("organization", orgID).WithSeverity(AuditSeverityHigh))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, string(AuditActionBreakGlass), logs[0].Action)
+
+	// A checker created during the window stops granting the role at expiry
+	checker, err := service.GetChecker(ctx, sreID)
+	require.NoError(t, err)
+	require.True(t, checker.Can("super_admin", "organization", orgID))
+	_, err = service.conn(ctx).NewUpdate().Model((*RoleAssignment)(nil)).
+		Set("expires_at = current_timestamp - interval '1 second'").
+		Where("user_id = ?", sreID).
+		Exec(ctx)
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Second)
+	checker.roles.nextExpiry = past
+	checker.roles.Assignments[0].ExpiresAt = &past
+	assert.False(t, checker.Can("super_admin", "organization", orgID))
+
+	// RevokeExpired deletes the grant and audits its expiry
+	expired, err := service.RevokeExpired(ctx)
+	require.NoError(t, err)
+	revoked := false
+	for _, a := range expired {
+		revoked = revoked || a.UserID == sreID && a.Role == "super_admin"
+	}
+	assert.True(t, revoked)
+	logs, err = service.GetAuditLog(ctx, NewAuditLogFilter().WithScope("organization", orgID).WithAction(AuditActionExpired))
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
@@ -0,0 +1,91 @@
+package rolekit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fernandezvara/dbkit"
+)
+
+// ============================================================================
+// ROLE CARDINALITY
+// ============================================================================
+
+// hasCardinality reports whether a role has MinMembers or MaxMembers configured.
+func (s *Service) hasCardinality(role, scopeType string) bool {
+	roleDef := s.registry.GetRole(role, scopeType)
+	return roleDef != nil && roleDef.HasCardinality()
+}
+
+// withAssignmentLocks runs fn inside a transaction when any of the roles has
+// member limits or separation-of-duties constraints, so that the holder count
+// read by checkCardinality and the user's roles read by checkConstraints stay
+// locked until the change is committed. Other roles run fn directly.
+func (s *Service) withAssignmentLocks(ctx context.Context, scopeType string, roles []string, fn func(ctx context.Context) error) error {
+	for _, role := range roles {
+		if s.hasCardinality(role, scopeType) || len(s.registry.ConflictingRoles(role, scopeType)) > 0 {
+			return s.Transaction(ctx, fn)
+		}
+	}
+	return fn(ctx)
+}
+
+// lockRoleHolders locks the holders of a role in a scope instance and returns how
+// many there are. The advisory lock serializes concurrent assignments (which have
+// no row to lock yet) and FOR UPDATE locks the existing rows against revocation.
+// Both are released when the surrounding transaction ends.
+func (s *Service) lockRoleHolders(ctx context.Context, role, scopeType, scopeID string) (int, error) {
+	db := s.conn(ctx)
+
+	key := role + "@" + NewScope(scopeType, scopeID).String()
+	_, err := db.NewRaw("SELECT pg_advisory_xact_lock(hashtext(?))", key).Exec(ctx)
+	if err = dbkit.WithErr1(err, "LockRoleHolders").Err(); err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	err = dbkit.WithErr1(db.NewRaw("SELECT id FROM role_assignments WHERE role = ? AND scope_type = ? AND scope_id = ? AND "+activeAssignment+" FOR UPDATE", role, scopeType, scopeID).Scan(ctx, &ids), "LockRoleHolders").Err()
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// checkCardinality verifies that adding (delta > 0) or removing (delta < 0)
+// holders of a role in a scope instance keeps it within its MinMembers and
+// MaxMembers limits. It must run inside a transaction (see withAssignmentLocks).
+func (s *Service) checkCardinality(ctx context.Context, role, scopeType, scopeID string, delta int) error {
+	roleDef := s.registry.GetRole(role, scopeType)
+	if roleDef == nil || !roleDef.HasCardinality() {
+		return nil
+	}
+
+	count, err := s.lockRoleHolders(ctx, role, scopeType, scopeID)
+	if err != nil {
+		return err
+	}
+
+	scope := NewScope(scopeType, scopeID)
+	after := count + delta
+
+	if delta > 0 && roleDef.maxMembers > 0 && after > roleDef.maxMembers {
+		return NewError(ErrCardinalityViolation,
+			fmt.Sprintf("role %q in %s allows at most %d member(s), currently has %d", role, scope, roleDef.maxMembers, count)).
+			WithScope(scopeType, scopeID).
+			WithRole(role)
+	}
+
+	if delta < 0 && roleDef.minMembers > 0 && after < roleDef.minMembers {
+		message := fmt.Sprintf("role %q in %s requires at least %d member(s), currently has %d", role, scope, roleDef.minMembers, count)
+		if count == 1 {
+			message = fmt.Sprintf("cannot remove the last %q of %s", role, scope)
+		}
+		return NewError(ErrCardinalityViolation, message).
+			WithScope(scopeType, scopeID).
+			WithRole(role)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRoleDefinitionCardinality tests configuring member limits on a role
+func TestRoleDefinitionCardinality(t *testing.T) {
+	r := NewRegistry()
+	r.DefineScope("organization").
+		Role("owner").Permissions("*").MinMembers(1).MaxMembers(3).
+		Role("member").Permissions("projects.list")
+
+	owner := r.GetRole("owner", "organization")
+	assert.Equal(t, 1, owner.GetMinMembers())
+	assert.Equal(t, 3, owner.GetMaxMembers())
+	assert.True(t, owner.HasCardinality())
+
+	member := r.GetRole("member", "organization")
+	assert.Equal(t, 0, member.GetMinMembers())
+	assert.Equal(t, 0, member.GetMaxMembers())
+	assert.False(t, member.HasCardinality())
+}
+
+// TestServiceCheckCardinalityWithoutLimits tests that roles without limits skip the database
+func TestServiceCheckCardinalityWithoutLimits(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").Role("member")
+	service := &Service{registry: registry}
+	ctx := context.Background()
+
+	assert.NoError(t, service.checkCardinality(ctx, "member", "organization", "org1", 1))
+	assert.NoError(t, service.checkCardinality(ctx, "member", "organization", "org1", -1))
+	assert.NoError(t, service.checkCardinality(ctx, "unknown", "organization", "org1", -1))
+	assert.False(t, service.hasCardinality("member", "organization"))
+
+	called := false
+	err := service.withAssignmentLocks(ctx, "organization", []string{"member"}, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+// TestServiceCardinalityDatabase tests last-owner protection and maximum members with real database
+func TestServiceCardinalityDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	service.Registry().GetRole("super_admin", "organization").MinMembers(1)
+	service.Registry().GetRole("team_lead", "organization").MaxMembers(1)
+
+	orgID := helper.CreateTestOrg("org")
+	ownerID := helper.CreateTestUser("owner")
+	if err := helper.SetupAdminUser(ownerID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), ownerID)
+
+	t.Run("Cannot revoke last owner", func(t *testing.T) {
+		err := service.Revoke(ctx, ownerID, "super_admin", "organization", orgID)
+		assert.True(t, IsCardinalityViolation(err))
+		helper.AssertRoleAssigned(ownerID, "super_admin", "organization", orgID)
+
+		err = service.RevokeAll(ctx, ownerID, "organization", orgID)
+		assert.True(t, IsCardinalityViolation(err))
+		helper.AssertRoleAssigned(ownerID, "super_admin", "organization", orgID)
+	})
+
+	t.Run("Maximum members", func(t *testing.T) {
+		first := helper.CreateTestUser("lead")
+		second := helper.CreateTestUser("lead")
+		assert.NoError(t, service.Assign(ctx, first, "team_lead", "organization", orgID))
+		err := service.Assign(ctx, second, "team_lead", "organization", orgID)
+		assert.True(t, IsCardinalityViolation(err))
+		helper.AssertRoleNotAssigned(second, "team_lead", "organization", orgID)
+	})
+
+	t.Run("Revoke owner once another exists", func(t *testing.T) {
+		other := helper.CreateTestUser("owner")
+		assert.NoError(t, service.Assign(ctx, other, "super_admin", "organization", orgID))
+		assert.NoError(t, service.Revoke(ctx, other, "super_admin", "organization", orgID))
+	})
+}
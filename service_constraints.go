@@ -0,0 +1,169 @@
+package rolekit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fernandezvara/dbkit"
+)
+
+// ============================================================================
+// SEPARATION OF DUTIES
+// ============================================================================
+
+// ConstraintViolation describes a user that holds two roles forbidden together
+// by a separation-of-duties constraint.
+type ConstraintViolation struct {
+	Constraint RoleConstraint
+	UserID     string
+	First      RoleAssignment
+	Second     RoleAssignment
+}
+
+// FindConstraintViolations reports existing assignments that break the
+// separation-of-duties constraints declared in the registry. Use it to audit
+// data created before a constraint was introduced.
+//
+// Example:
+//
+//	violations, err := service.FindConstraintViolations(ctx)
+//	for _, v := range violations {
+//	    log.Printf("%s holds %s and %s", v.UserID, v.First.Role, v.Second.Role)
+//	}
+func (s *Service) FindConstraintViolations(ctx context.Context) ([]ConstraintViolation, error) {
+	var violations []ConstraintViolation
+
+	for _, c := range s.registry.GetConstraints() {
+		var assignments []RoleAssignment
+		err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&assignments).
+			Where("((scope_type = ? AND role = ?) OR (scope_type = ? AND role = ?)) AND "+activeAssignment,
+				c.First.ScopeType, c.First.Role, c.Second.ScopeType, c.Second.Role).
+			Order("user_id").
+			Scan(ctx), "FindConstraintViolations").Err()
+		if err != nil {
+			return nil, err
+		}
+
+		// Group each user's assignments by side of the constraint
+		firsts := make(map[string][]RoleAssignment)
+		seconds := make(map[string][]RoleAssignment)
+		for _, a := range assignments {
+			ref := NewRoleRef(a.ScopeType, a.Role)
+			if ref == c.First {
+				firsts[a.UserID] = append(firsts[a.UserID], a)
+			}
+			if ref == c.Second {
+				seconds[a.UserID] = append(seconds[a.UserID], a)
+			}
+		}
+
+		for userID, held := range firsts {
+			for _, first := range held {
+				for _, second := range seconds[userID] {
+					if first.ID == second.ID {
+						continue
+					}
+					related, err := s.scopesRelated(ctx,
+						NewScope(first.ScopeType, first.ScopeID),
+						NewScope(second.ScopeType, second.ScopeID))
+					if err != nil {
+						return nil, err
+					}
+					if related {
+						violations = append(violations, ConstraintViolation{
+							Constraint: c,
+							UserID:     userID,
+							First:      first,
+							Second:     second,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// checkConstraints verifies that assigning a role would not break any
+// separation-of-duties constraint. Pending assignments (from the same batch)
+// are considered in addition to the user's stored assignments, including those
+// in archived scopes, which come back on RestoreScope. It locks the user's
+// assignments against concurrent checks, so it must run inside a transaction
+// (see withAssignmentLocks).
+func (s *Service) checkConstraints(ctx context.Context, userID, role, scopeType, scopeID string, pending []RoleAssignment) error {
+	constraints := s.registry.ConflictingRoles(role, scopeType)
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	// Serialize constraint checks for the user: two concurrent assignments of
+	// conflicting roles would otherwise both pass
+	_, err := s.conn(ctx).NewRaw("SELECT pg_advisory_xact_lock(hashtext(?))", "user@"+userID).Exec(ctx)
+	if err = dbkit.WithErr1(err, "LockUserAssignments").Err(); err != nil {
+		return err
+	}
+
+	userRoles, err := s.loadUserRoles(ctx, userID, true)
+	if err != nil {
+		return err
+	}
+
+	held := append([]RoleAssignment{}, userRoles.Assignments...)
+	for _, p := range pending {
+		if p.UserID == userID {
+			held = append(held, p)
+		}
+	}
+
+	ref := NewRoleRef(scopeType, role)
+	target := NewScope(scopeType, scopeID)
+	for _, c := range constraints {
+		other, _ := c.Conflicts(ref)
+		for _, a := range held {
+			if a.Role != other.Role || a.ScopeType != other.ScopeType {
+				continue
+			}
+			heldScope := NewScope(a.ScopeType, a.ScopeID)
+			related, err := s.scopesRelated(ctx, target, heldScope)
+			if err != nil {
+				return err
+			}
+			if related {
+				return NewError(ErrConstraintViolation,
+					fmt.Sprintf("role %s conflicts with %s held in %s (constraint %q)", ref, other, heldScope, c.Name)).
+					WithScope(scopeType, scopeID).
+					WithRole(role).
+					WithUser(userID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// scopesRelated reports whether two scope instances are the same instance or
+// one is an ancestor of the other in scope_hierarchy.
+func (s *Service) scopesRelated(ctx context.Context, a, b Scope) (bool, error) {
+	if a.Type == b.Type {
+		return a.ID == b.ID || a.IsWildcard() || b.IsWildcard(), nil
+	}
+
+	for _, pair := range [][2]Scope{{a, b}, {b, a}} {
+		child, ancestor := pair[0], pair[1]
+		if child.IsWildcard() {
+			continue
+		}
+		ancestors, err := s.getAncestorScopes(ctx, child.Type, child.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, anc := range ancestors {
+			if anc.Type == ancestor.Type && (anc.ID == ancestor.ID || ancestor.IsWildcard()) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
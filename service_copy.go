@@ -0,0 +1,126 @@
+package rolekit
+
+import (
+	"context"
+	"errors"
+)
+
+// ============================================================================
+// SCOPE MEMBER COPY
+// ============================================================================
+
+// CopyOption configures a copy of scope members.
+type CopyOption func(*copyOptions)
+
+type copyOptions struct {
+	roles   []string
+	roleMap map[string]string
+}
+
+// CopyRoles copies only the given source roles.
+func CopyRoles(roles ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.roles = append(o.roles, roles...)
+	}
+}
+
+// MapRole assigns toRole in the target scope to holders of fromRole in the
+// source scope. Roles without a mapping keep their name, which must then
+// exist in the target scope type.
+//
+// Example:
+//
+//	// Copy an organization's admins as project managers
+//	rolekit.MapRole("admin", "manager")
+func MapRole(fromRole, toRole string) CopyOption {
+	return func(o *copyOptions) {
+		if o.roleMap == nil {
+			o.roleMap = make(map[string]string)
+		}
+		o.roleMap[fromRole] = toRole
+	}
+}
+
+// CopyScopeMembers copies the assignments of one scope instance to another in
+// a single transaction. Every copy goes through Assign, so the actor in the
+// context must be able to assign each target role and constraints and member
+// limits apply. Members who already hold a target role are skipped, and
+// expiring assignments are not copied. The audit entries reference the source
+// scope in their metadata and share a correlation ID. It returns the number of
+// assignments created.
+//
+// Example:
+//
+//	// New project from a template: same team
+//	copied, err := service.CopyScopeMembers(ctx,
+//	    rolekit.NewScope("project", templateID),
+//	    rolekit.NewScope("project", newProjectID))
+func (s *Service) CopyScopeMembers(ctx context.Context, from, to Scope, opts ...CopyOption) (int, error) {
+	options := copyOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	filter := AssignmentFilter{ScopeType: from.Type, ScopeID: from.ID, Roles: options.roles}
+	if err := filter.validate(s.registry); err != nil {
+		return 0, err
+	}
+	if err := s.registry.ValidateScope(to.Type); err != nil {
+		return 0, err
+	}
+	for fromRole, toRole := range options.roleMap {
+		if err := s.registry.ValidateRole(fromRole, from.Type); err != nil {
+			return 0, err
+		}
+		if err := s.registry.ValidateRole(toRole, to.Type); err != nil {
+			return 0, err
+		}
+	}
+	if from == to {
+		return 0, NewError(ErrInvalidScope, "cannot copy members of a scope onto itself").
+			WithScope(to.Type, to.ID)
+	}
+
+	ctx = withCorrelationID(ctx, newCorrelationID())
+	ctx = withAuditMetadata(ctx, map[string]any{
+		"copied_from_scope_type": from.Type,
+		"copied_from_scope_id":   from.ID,
+	})
+
+	copied := 0
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		assignments, err := s.findAssignments(ctx, filter)
+		if err != nil {
+			return err
+		}
+		sortAssignments(assignments)
+
+		for _, a := range assignments {
+			if a.ExpiresAt != nil {
+				continue
+			}
+			role := a.Role
+			if mapped, ok := options.roleMap[role]; ok {
+				role = mapped
+			}
+			if err := s.registry.ValidateRole(role, to.Type); err != nil {
+				return err
+			}
+
+			err := s.Assign(ctx, a.UserID, role, to.Type, to.ID)
+			switch {
+			case err == nil:
+				copied++
+			case errors.Is(err, ErrRoleAlreadyAssigned):
+				// Already a member of the target scope
+			default:
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
@@ -0,0 +1,90 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceCopyScopeMembersValidation tests argument validation before any database access
+func TestServiceCopyScopeMembersValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("admin").Permissions("*").CanAssign("*")
+	registry.DefineScope("project").
+		Role("manager").Permissions("*").CanAssign("*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "admin1")
+	org := NewScope("organization", "org1")
+	project := NewScope("project", "proj1")
+
+	t.Run("Invalid source scope", func(t *testing.T) {
+		_, err := service.CopyScopeMembers(ctx, NewScope("unknown", "x"), project)
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("Invalid target scope", func(t *testing.T) {
+		_, err := service.CopyScopeMembers(ctx, org, NewScope("unknown", "x"))
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("Invalid role filter", func(t *testing.T) {
+		_, err := service.CopyScopeMembers(ctx, org, project, CopyRoles("manager"))
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("Invalid role mapping", func(t *testing.T) {
+		_, err := service.CopyScopeMembers(ctx, org, project, MapRole("admin", "owner"))
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("Same scope", func(t *testing.T) {
+		_, err := service.CopyScopeMembers(ctx, org, org)
+		assert.True(t, IsInvalidScope(err))
+	})
+}
+
+// TestServiceCopyScopeMembersDatabase tests copying members between scopes with real database
+func TestServiceCopyScopeMembersDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	templateID := helper.CreateTestOrg("template")
+	targetID := helper.CreateTestOrg("target")
+	adminID := helper.CreateTestUser("admin")
+	devID := helper.CreateTestUser("dev")
+	viewerID := helper.CreateTestUser("viewer")
+	require.NoError(t, helper.SetupAdminUser(adminID, templateID))
+	require.NoError(t, helper.SetupAdminUser(adminID, targetID))
+
+	ctx := WithActorID(helper.GetContext(), adminID)
+	require.NoError(t, service.Assign(ctx, devID, "developer", "organization", templateID))
+	require.NoError(t, service.Assign(ctx, viewerID, "viewer", "organization", templateID))
+
+	copied, err := service.CopyScopeMembers(ctx,
+		NewScope("organization", templateID),
+		NewScope("organization", targetID),
+		CopyRoles("developer", "viewer"),
+		MapRole("developer", "team_lead"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied)
+	helper.AssertRoleAssigned(devID, "team_lead", "organization", targetID)
+	helper.AssertRoleAssigned(viewerID, "viewer", "organization", targetID)
+	helper.AssertRoleNotAssigned(devID, "developer", "organization", targetID)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithScope("organization", targetID).WithTargetUser(viewerID))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, templateID, logs[0].Metadata["copied_from_scope_id"])
+
+	// Copying again skips existing members
+	copied, err = service.CopyScopeMembers(ctx, NewScope("organization", templateID), NewScope("organization", targetID), CopyRoles("viewer"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, copied)
+}
@@ -11,9 +11,20 @@ import (
 // ============================================================================
 
 // GetUserRoles retrieves all role assignments for a user.
+// Assignments in archived scopes are ignored.
 func (s *Service) GetUserRoles(ctx context.Context, userID string) (*UserRoles, error) {
+	return s.loadUserRoles(ctx, userID, false)
+}
+
+// loadUserRoles retrieves a user's active role assignments, optionally
+// including those in archived scopes.
+func (s *Service) loadUserRoles(ctx context.Context, userID string, includeArchived bool) (*UserRoles, error) {
 	var assignments []RoleAssignment
-	err := dbkit.WithErr1(s.db.NewSelect().Model(&assignments).Where("user_id = ?", userID).Scan(ctx), "GetUserRoles").Err()
+	q := s.conn(ctx).NewSelect().Model(&assignments).Where("user_id = ? AND "+activeAssignment, userID)
+	if !includeArchived {
+		q = q.Where(unarchivedScope)
+	}
+	err := dbkit.WithErr1(q.Scan(ctx), "GetUserRoles").Err()
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +34,7 @@ func (s *Service) GetUserRoles(ctx context.Context, userID string) (*UserRoles,
 // GetScopeMembers retrieves all users with roles in a scope.
 func (s *Service) GetScopeMembers(ctx context.Context, scopeType, scopeID string) ([]RoleAssignment, error) {
 	var assignments []RoleAssignment
-	err := dbkit.WithErr1(s.db.NewSelect().Model(&assignments).Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).Scan(ctx), "GetScopeMembers").Err()
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&assignments).Where("scope_type = ? AND scope_id = ? AND "+activeAssignment, scopeType, scopeID).Scan(ctx), "GetScopeMembers").Err()
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +44,18 @@ func (s *Service) GetScopeMembers(ctx context.Context, scopeType, scopeID string
 // GetScopeMembersWithRole retrieves all users with a specific role in a scope.
 func (s *Service) GetScopeMembersWithRole(ctx context.Context, role, scopeType, scopeID string) ([]RoleAssignment, error) {
 	var assignments []RoleAssignment
-	err := dbkit.WithErr1(s.db.NewSelect().Model(&assignments).Where("scope_type = ? AND scope_id = ? AND role = ?", scopeType, scopeID, role).Scan(ctx), "GetScopeMembersWithRole").Err()
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&assignments).Where("scope_type = ? AND scope_id = ? AND role = ? AND "+activeAssignment, scopeType, scopeID, role).Scan(ctx), "GetScopeMembersWithRole").Err()
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// findAssignments retrieves the active assignments selected by a filter.
+func (s *Service) findAssignments(ctx context.Context, filter AssignmentFilter) ([]RoleAssignment, error) {
+	var assignments []RoleAssignment
+	q := filter.apply(s.conn(ctx).NewSelect().Model(&assignments)).Where(activeAssignment)
+	err := dbkit.WithErr1(q.Scan(ctx), "FindAssignments").Err()
 	if err != nil {
 		return nil, err
 	}
@@ -42,12 +64,16 @@ func (s *Service) GetScopeMembersWithRole(ctx context.Context, role, scopeType,
 
 // GetChecker creates a Checker for a user.
 // This can be stored in context for efficient permission checking in handlers.
+// With Registry.EnableDelegation the Checker also honors the permissions
+// delegated to the user. If ctx comes from Impersonate and userID is the
+// impersonated subject, the Checker keeps the session's scope and read-only
+// limits.
 func (s *Service) GetChecker(ctx context.Context, userID string) (*Checker, error) {
 	roles, err := s.GetUserRoles(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	return NewChecker(userID, roles, s.registry, s), nil
+	return s.delegatingChecker(ctx, userID, roles, 0)
 }
 
 // GetCheckerFromContext creates a Checker using the user ID from context.
@@ -64,7 +90,8 @@ func (s *Service) GetCheckerFromContext(ctx context.Context) (*Checker, error) {
 // ============================================================================
 
 // SetScopeParent sets the parent scope for a scope instance.
-// This is used for hierarchical queries.
+// This is used for hierarchical queries. A scope has a single parent; use
+// MoveScope to change it.
 //
 // Example:
 //
@@ -78,19 +105,26 @@ func (s *Service) SetScopeParent(ctx context.Context, scopeType, scopeID, parent
 		ParentScopeID:   parentScopeID,
 	}
 
-	// Try to insert, ignore if it already exists
-	result, err := s.db.NewInsert().Model(hierarchy).Exec(ctx)
-	if err != nil {
-		// Check if it's a duplicate key error (PostgreSQL error code 23505)
-		if dbkit.IsDuplicate(err) {
-			// Already exists, just continue
-		} else {
-			return dbkit.WithErr(result, err, "SetScopeParent").Err()
+	// Try to insert, ignore if the same parent is already set
+	result, err := s.conn(ctx).NewInsert().Model(hierarchy).
+		On("CONFLICT (scope_type, scope_id) DO NOTHING").
+		Exec(ctx)
+	if err = dbkit.WithErr(result, err, "SetScopeParent").Err(); err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		existing, err := s.getParentScope(ctx, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		if existing != nil && (existing.ParentScopeType != parentScopeType || existing.ParentScopeID != parentScopeID) {
+			return NewError(ErrInvalidScope, "scope already has a different parent; use MoveScope").
+				WithScope(scopeType, scopeID)
 		}
 	}
 
 	// Update any existing role assignments with parent scope
-	result, err = s.db.NewUpdate().Table("role_assignments").Set("parent_scope_type = ?", parentScopeType).Set("parent_scope_id = ?", parentScopeID).Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).Exec(ctx)
+	result, err = s.conn(ctx).NewUpdate().Table("role_assignments").Set("parent_scope_type = ?", parentScopeType).Set("parent_scope_id = ?", parentScopeID).Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -107,7 +141,7 @@ func (s *Service) SetScopeParent(ctx context.Context, scopeType, scopeID, parent
 //	projectIDs, err := service.GetChildScopes(ctx, userID, "project", "organization", orgID)
 func (s *Service) GetChildScopes(ctx context.Context, userID, childScopeType, parentScopeType, parentScopeID string) ([]string, error) {
 	var scopeIDs []string
-	err := dbkit.WithErr1(s.db.NewRaw("SELECT DISTINCT scope_id FROM role_assignments WHERE user_id = ? AND scope_type = ? AND parent_scope_type = ? AND parent_scope_id = ?", userID, childScopeType, parentScopeType, parentScopeID).Scan(ctx, &scopeIDs), "GetChildScopes").Err()
+	err := dbkit.WithErr1(s.conn(ctx).NewRaw("SELECT DISTINCT scope_id FROM role_assignments WHERE user_id = ? AND scope_type = ? AND parent_scope_type = ? AND parent_scope_id = ? AND "+activeAssignment, userID, childScopeType, parentScopeType, parentScopeID).Scan(ctx, &scopeIDs), "GetChildScopes").Err()
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +155,7 @@ func (s *Service) GetChildScopes(ctx context.Context, userID, childScopeType, pa
 //	projectIDs, err := service.GetChildScopesWithRole(ctx, userID, "editor", "project", "organization", orgID)
 func (s *Service) GetChildScopesWithRole(ctx context.Context, userID, role, childScopeType, parentScopeType, parentScopeID string) ([]string, error) {
 	var scopeIDs []string
-	err := dbkit.WithErr1(s.db.NewRaw("SELECT DISTINCT scope_id FROM role_assignments WHERE user_id = ? AND role = ? AND scope_type = ? AND parent_scope_type = ? AND parent_scope_id = ?", userID, role, childScopeType, parentScopeType, parentScopeID).Scan(ctx, &scopeIDs), "GetChildScopesWithRole").Err()
+	err := dbkit.WithErr1(s.conn(ctx).NewRaw("SELECT DISTINCT scope_id FROM role_assignments WHERE user_id = ? AND role = ? AND scope_type = ? AND parent_scope_type = ? AND parent_scope_id = ? AND "+activeAssignment, userID, role, childScopeType, parentScopeType, parentScopeID).Scan(ctx, &scopeIDs), "GetChildScopesWithRole").Err()
 	if err != nil {
 		return nil, err
 	}
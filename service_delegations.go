@@ -0,0 +1,313 @@
+package rolekit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fernandezvara/dbkit"
+	"github.com/uptrace/bun"
+)
+
+// ============================================================================
+// PERMISSION DELEGATION
+// ============================================================================
+
+// maxDelegationDepth bounds how many re-delegation hops are followed when
+// checking that a delegator still holds a permission.
+const maxDelegationDepth = 4
+
+// EnableDelegation turns on permission delegation: Delegate is allowed and
+// checkers from GetChecker load the user's delegations. It is off by default,
+// so checkers of applications that do not delegate need no role_delegations
+// table and no extra queries.
+//
+// Example:
+//
+//	registry := rolekit.NewRegistry().EnableDelegation()
+func (r *Registry) EnableDelegation() *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delegation = true
+	return r
+}
+
+// IsDelegationEnabled reports whether permission delegation is enabled.
+func (r *Registry) IsDelegationEnabled() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.delegation
+}
+
+// DelegationOption configures a delegation.
+type DelegationOption func(*delegationOptions)
+
+type delegationOptions struct {
+	startsAt          time.Time
+	allowRedelegation bool
+}
+
+// DelegationStartsAt delays the start of a delegation (default: now).
+//
+// Example:
+//
+//	service.Delegate(ctx, deputyID, "organization", orgID, []string{"expenses.approve"},
+//	    vacationEnd, rolekit.DelegationStartsAt(vacationStart))
+func DelegationStartsAt(startsAt time.Time) DelegationOption {
+	return func(o *delegationOptions) {
+		o.startsAt = startsAt
+	}
+}
+
+// AllowRedelegation lets the delegate delegate the permissions further.
+func AllowRedelegation() DelegationOption {
+	return func(o *delegationOptions) {
+		o.allowRedelegation = true
+	}
+}
+
+// Delegate lends some of the actor's permissions in a scope to another user
+// until endsAt. The actor must hold every permission, through their roles or
+// through a delegation that allows re-delegation. Checkers built by the
+// service honor a delegation only while the delegator still holds the
+// permission, so losing a role also withdraws what was delegated from it.
+//
+// Example:
+//
+//	ctx = rolekit.WithActorID(ctx, managerID)
+//	delegation, err := service.Delegate(ctx, deputyID, "organization", orgID,
+//	    []string{"expenses.approve"}, time.Now().Add(14*24*time.Hour))
+func (s *Service) Delegate(ctx context.Context, delegateID, scopeType, scopeID string, permissions []string, endsAt time.Time, opts ...DelegationOption) (*Delegation, error) {
+	options := delegationOptions{startsAt: time.Now()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if !s.registry.IsDelegationEnabled() {
+		return nil, NewError(ErrCannotDelegate, "delegation is not enabled; see Registry.EnableDelegation")
+	}
+	if err := s.registry.ValidateScope(scopeType); err != nil {
+		return nil, err
+	}
+
+	delegatorID := GetActorID(ctx)
+	if delegatorID == "" {
+		return nil, NewError(ErrNoActorID, "actor ID required for delegation")
+	}
+	if delegateID == "" || delegateID == delegatorID {
+		return nil, NewError(ErrCannotDelegate, "delegate must be another user").
+			WithScope(scopeType, scopeID).
+			WithUser(delegateID).
+			WithActor(delegatorID)
+	}
+	if len(permissions) == 0 {
+		return nil, NewError(ErrCannotDelegate, "at least one permission is required").
+			WithScope(scopeType, scopeID).
+			WithActor(delegatorID)
+	}
+	matcher := NewPermissionMatcher()
+	for _, permission := range permissions {
+		if err := matcher.Validate(permission); err != nil {
+			return nil, err
+		}
+	}
+	if !endsAt.After(options.startsAt) || !endsAt.After(time.Now()) {
+		return nil, NewError(ErrCannotDelegate, "delegation must end in the future and after it starts").
+			WithScope(scopeType, scopeID).
+			WithActor(delegatorID)
+	}
+
+	delegation := &Delegation{
+		DelegatorID:       delegatorID,
+		DelegateID:        delegateID,
+		Permissions:       append([]string{}, permissions...),
+		ScopeType:         scopeType,
+		ScopeID:           scopeID,
+		AllowRedelegation: options.allowRedelegation,
+		StartsAt:          options.startsAt,
+		EndsAt:            endsAt,
+	}
+
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		delegatorRoles, err := s.GetUserRoles(ctx, delegatorID)
+		if err != nil {
+			return err
+		}
+
+		// Only delegations that allow re-delegation count towards what the actor can delegate
+		checker, err := s.delegatingChecker(ctx, delegatorID, delegatorRoles, 1)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			if !checker.HasPermission(permission, scopeType, scopeID) {
+				return NewError(ErrCannotDelegate, fmt.Sprintf("delegator does not hold %q or may not re-delegate it", permission)).
+					WithScope(scopeType, scopeID).
+					WithActor(delegatorID)
+			}
+		}
+
+		result, err := s.conn(ctx).NewInsert().Model(delegation).Returning("*").Exec(ctx)
+		if err = dbkit.WithErr(result, err, "CreateDelegation").Err(); err != nil {
+			return err
+		}
+
+		entry := s.delegationAudit(ctx, AuditActionDelegated, delegation, delegatorID)
+		entry.ActorRoles = delegatorRoles.GetRoles(scopeType, scopeID)
+		entry.Metadata = map[string]any{
+			"permissions":        delegation.Permissions,
+			"starts_at":          delegation.StartsAt,
+			"ends_at":            delegation.EndsAt,
+			"allow_redelegation": delegation.AllowRedelegation,
+		}
+		return s.logAudit(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delegation, nil
+}
+
+// RevokeDelegation ends a delegation immediately. The actor in the context
+// must be the delegator or the delegate.
+//
+// Example:
+//
+//	err := service.RevokeDelegation(ctx, delegationID)
+func (s *Service) RevokeDelegation(ctx context.Context, delegationID string) error {
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return NewError(ErrNoActorID, "actor ID required to revoke a delegation")
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		delegation := new(Delegation)
+		err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(delegation).
+			Where("id = ? AND revoked_at IS NULL", delegationID).
+			For("UPDATE").
+			Scan(ctx), "RevokeDelegation").Err()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewError(ErrDelegationNotFound, "delegation "+delegationID+" not found").WithActor(actorID)
+			}
+			return err
+		}
+
+		if actorID != delegation.DelegatorID && actorID != delegation.DelegateID {
+			return NewError(ErrUnauthorized, "only the delegator or the delegate can revoke a delegation").
+				WithScope(delegation.ScopeType, delegation.ScopeID).
+				WithActor(actorID)
+		}
+
+		now := time.Now()
+		delegation.RevokedAt = &now
+		delegation.RevokedBy = actorID
+		result, err := s.conn(ctx).NewUpdate().Model(delegation).Column("revoked_at", "revoked_by").WherePK().Exec(ctx)
+		if err = dbkit.WithErr(result, err, "RevokeDelegation").Err(); err != nil {
+			return err
+		}
+
+		entry := s.delegationAudit(ctx, AuditActionDelegationRevoked, delegation, actorID)
+		entry.Metadata = map[string]any{"permissions": delegation.Permissions}
+		return s.logAudit(ctx, entry)
+	})
+}
+
+// ListDelegationsFrom returns the delegations a user has given that have not
+// ended or been revoked.
+func (s *Service) ListDelegationsFrom(ctx context.Context, delegatorID string) ([]Delegation, error) {
+	return s.listDelegations(ctx, "delegator_id", delegatorID)
+}
+
+// ListDelegationsTo returns the delegations a user has received that have not
+// ended or been revoked.
+//
+// Example:
+//
+//	received, err := service.ListDelegationsTo(ctx, deputyID)
+func (s *Service) ListDelegationsTo(ctx context.Context, delegateID string) ([]Delegation, error) {
+	return s.listDelegations(ctx, "delegate_id", delegateID)
+}
+
+func (s *Service) listDelegations(ctx context.Context, column, userID string) ([]Delegation, error) {
+	var delegations []Delegation
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&delegations).
+		Where("? = ? AND revoked_at IS NULL AND ends_at > current_timestamp", bun.Ident(column), userID).
+		Order("starts_at ASC").
+		Scan(ctx), "ListDelegations").Err()
+	if err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+// delegatingChecker builds a Checker for the user that honors the delegations
+// the user received. Each delegation is reduced to the permissions its
+// delegator still holds. At depth 0 every active delegation counts; when
+// resolving a delegator (depth > 0) only delegations that allow re-delegation
+// do, so a plain delegation cannot be passed on. Without EnableDelegation no
+// delegations are loaded.
+func (s *Service) delegatingChecker(ctx context.Context, userID string, roles *UserRoles, depth int) (*Checker, error) {
+	checker := s.newChecker(ctx, userID, roles)
+	if depth >= maxDelegationDepth || !s.registry.IsDelegationEnabled() {
+		return checker, nil
+	}
+
+	var incoming []Delegation
+	q := s.conn(ctx).NewSelect().Model(&incoming).
+		Where("delegate_id = ? AND revoked_at IS NULL AND starts_at <= current_timestamp AND ends_at > current_timestamp", userID)
+	if depth > 0 {
+		q = q.Where("allow_redelegation")
+	}
+	if err := dbkit.WithErr1(q.Scan(ctx), "LoadDelegations").Err(); err != nil {
+		return nil, err
+	}
+
+	delegators := make(map[string]*Checker)
+	for _, d := range incoming {
+		delegator, ok := delegators[d.DelegatorID]
+		if !ok {
+			delegatorRoles, err := s.GetUserRoles(ctx, d.DelegatorID)
+			if err != nil {
+				return nil, err
+			}
+			delegator, err = s.delegatingChecker(ctx, d.DelegatorID, delegatorRoles, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			delegators[d.DelegatorID] = delegator
+		}
+
+		var held []string
+		for _, permission := range d.Permissions {
+			if delegator.HasPermission(permission, d.ScopeType, d.ScopeID) {
+				held = append(held, permission)
+			}
+		}
+		if len(held) > 0 {
+			d.Permissions = held
+			checker.delegations = append(checker.delegations, d)
+		}
+	}
+
+	return checker, nil
+}
+
+// delegationAudit builds the audit entry for a state change of a delegation.
+// The delegation ID is used as correlation ID so its history can be queried.
+func (s *Service) delegationAudit(ctx context.Context, action AuditAction, delegation *Delegation, actorID string) *AuditEntry {
+	audit := GetAuditContext(ctx)
+	return &AuditEntry{
+		ActorID:       actorID,
+		Action:        action,
+		TargetUserID:  delegation.DelegateID,
+		ScopeType:     delegation.ScopeType,
+		ScopeID:       delegation.ScopeID,
+		IPAddress:     audit.IPAddress,
+		UserAgent:     audit.UserAgent,
+		RequestID:     audit.RequestID,
+		CorrelationID: delegation.ID,
+	}
+}
@@ -0,0 +1,152 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDelegationIsActive tests the delegation time window
+func TestDelegationIsActive(t *testing.T) {
+	now := time.Now()
+	d := Delegation{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+
+	assert.True(t, d.IsActive(now))
+	assert.False(t, d.IsActive(now.Add(-2*time.Hour)))
+	assert.False(t, d.IsActive(now.Add(time.Hour)))
+
+	revoked := d
+	revoked.RevokedAt = &now
+	assert.False(t, revoked.IsActive(now))
+}
+
+// TestCheckerDelegatedPermissions tests that checkers honor delegated permissions
+func TestCheckerDelegatedPermissions(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("manager").Permissions("expenses.*").
+		Role("member").Permissions("expenses.submit")
+
+	roles := NewUserRoles("deputy", []RoleAssignment{
+		{UserID: "deputy", Role: "member", ScopeType: "organization", ScopeID: "org1"},
+	})
+	checker := NewChecker("deputy", roles, registry, nil)
+	checker.delegations = []Delegation{{
+		DelegatorID: "manager1",
+		DelegateID:  "deputy",
+		Permissions: []string{"expenses.approve"},
+		ScopeType:   "organization",
+		ScopeID:     "org1",
+		StartsAt:    time.Now().Add(-time.Hour),
+		EndsAt:      time.Now().Add(time.Hour),
+	}}
+
+	assert.True(t, checker.HasPermission("expenses.submit", "organization", "org1"))
+	assert.True(t, checker.HasPermission("expenses.approve", "organization", "org1"))
+	assert.False(t, checker.HasPermission("expenses.delete", "organization", "org1"))
+	assert.False(t, checker.HasPermission("expenses.approve", "organization", "org2"))
+
+	// Delegations are not roles: they grant no assignment authority
+	assert.False(t, checker.Can("manager", "organization", "org1"))
+
+	// Ended delegations no longer count
+	checker.delegations[0].EndsAt = time.Now().Add(-time.Minute)
+	assert.False(t, checker.HasPermission("expenses.approve", "organization", "org1"))
+}
+
+// TestServiceDelegateValidation tests argument validation before any database access
+func TestServiceDelegateValidation(t *testing.T) {
+	registry := NewRegistry().EnableDelegation()
+	registry.DefineScope("organization").
+		Role("manager").Permissions("expenses.*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "manager1")
+	endsAt := time.Now().Add(time.Hour)
+
+	t.Run("Delegation disabled", func(t *testing.T) {
+		disabled := &Service{registry: NewRegistry()}
+		_, err := disabled.Delegate(ctx, "deputy", "organization", "org1", []string{"expenses.approve"}, endsAt)
+		assert.True(t, IsCannotDelegate(err))
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		_, err := service.Delegate(ctx, "deputy", "unknown", "org1", []string{"expenses.approve"}, endsAt)
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, err := service.Delegate(context.Background(), "deputy", "organization", "org1", []string{"expenses.approve"}, endsAt)
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("Self delegation", func(t *testing.T) {
+		_, err := service.Delegate(ctx, "manager1", "organization", "org1", []string{"expenses.approve"}, endsAt)
+		assert.True(t, IsCannotDelegate(err))
+	})
+
+	t.Run("No permissions", func(t *testing.T) {
+		_, err := service.Delegate(ctx, "deputy", "organization", "org1", nil, endsAt)
+		assert.True(t, IsCannotDelegate(err))
+	})
+
+	t.Run("Invalid permission", func(t *testing.T) {
+		_, err := service.Delegate(ctx, "deputy", "organization", "org1", []string{"expenses"}, endsAt)
+		assert.ErrorIs(t, err, ErrInvalidPermission)
+	})
+
+	t.Run("Window in the past", func(t *testing.T) {
+		_, err := service.Delegate(ctx, "deputy", "organization", "org1", []string{"expenses.approve"}, time.Now().Add(-time.Hour))
+		assert.True(t, IsCannotDelegate(err))
+	})
+}
+
+// TestServiceDelegationDatabase tests delegating, re-delegating and revoking with real database
+func TestServiceDelegationDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	service.Registry().EnableDelegation()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	deputyID := helper.CreateTestUser("deputy")
+	otherID := helper.CreateTestUser("other")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+
+	adminCtx := WithActorID(helper.GetContext(), adminID)
+	deputyCtx := WithActorID(helper.GetContext(), deputyID)
+	endsAt := time.Now().Add(time.Hour)
+
+	delegation, err := service.Delegate(adminCtx, deputyID, "organization", orgID, []string{"members.read"}, endsAt)
+	require.NoError(t, err)
+	assert.True(t, service.HasPermission(helper.GetContext(), deputyID, "members.read", "organization", orgID))
+
+	received, err := service.ListDelegationsTo(helper.GetContext(), deputyID)
+	require.NoError(t, err)
+	assert.Len(t, received, 1)
+
+	// Plain delegations cannot be passed on
+	_, err = service.Delegate(deputyCtx, otherID, "organization", orgID, []string{"members.read"}, endsAt)
+	assert.True(t, IsCannotDelegate(err))
+
+	// The delegate only benefits while the delegator holds the permission
+	require.NoError(t, service.Revoke(adminCtx, adminID, "super_admin", "organization", orgID))
+	assert.False(t, service.HasPermission(helper.GetContext(), deputyID, "members.read", "organization", orgID))
+
+	require.NoError(t, service.RevokeDelegation(adminCtx, delegation.ID))
+	received, err = service.ListDelegationsTo(helper.GetContext(), deputyID)
+	require.NoError(t, err)
+	assert.Empty(t, received)
+
+	logs, err := service.GetAuditLog(adminCtx, NewAuditLogFilter().WithCorrelationID(delegation.ID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+}
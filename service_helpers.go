@@ -2,9 +2,12 @@ package rolekit
 
 import (
 	"context"
+	cryptorand "crypto/rand"
 	"database/sql"
 	"errors"
+	"fmt"
 	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/fernandezvara/dbkit"
@@ -14,9 +17,24 @@ import (
 // INTERNAL HELPERS
 // ============================================================================
 
+// activeAssignment is the SQL condition that excludes expired role assignments.
+const activeAssignment = "(expires_at IS NULL OR expires_at > current_timestamp)"
+
+// unarchivedScope is the SQL condition that excludes role assignments in archived scopes.
+const unarchivedScope = "(scope_type, scope_id) NOT IN (SELECT scope_type, scope_id FROM archived_scopes)"
+
+// conn returns the database handle for a call: the transaction carried by the
+// context when inside Service.Transaction, or the service's database otherwise.
+func (s *Service) conn(ctx context.Context) dbkit.IDB {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx
+	}
+	return s.db
+}
+
 func (s *Service) getUserRoleNames(ctx context.Context, userID, scopeType, scopeID string) ([]string, error) {
 	var roles []string
-	err := dbkit.WithErr1(s.db.NewRaw("SELECT role FROM role_assignments WHERE user_id = ? AND scope_type = ? AND (scope_id = ? OR scope_id = '*')", userID, scopeType, scopeID).Scan(ctx, &roles), "GetUserRoleNames").Err()
+	err := dbkit.WithErr1(s.conn(ctx).NewRaw("SELECT role FROM role_assignments WHERE user_id = ? AND scope_type = ? AND (scope_id = ? OR scope_id = '*') AND "+activeAssignment, userID, scopeType, scopeID).Scan(ctx, &roles), "GetUserRoleNames").Err()
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -28,7 +46,7 @@ func (s *Service) getUserRoleNames(ctx context.Context, userID, scopeType, scope
 
 func (s *Service) getParentScope(ctx context.Context, scopeType, scopeID string) (*ScopeHierarchy, error) {
 	var hierarchy ScopeHierarchy
-	err := dbkit.WithErr1(s.db.NewSelect().Model(&hierarchy).Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).Limit(1).Scan(ctx), "GetParentScope").Err()
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&hierarchy).Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).Limit(1).Scan(ctx), "GetParentScope").Err()
 	if err != nil {
 		if dbkit.IsNotFound(err) {
 			return nil, nil
@@ -38,8 +56,202 @@ func (s *Service) getParentScope(ctx context.Context, scopeType, scopeID string)
 	return &hierarchy, nil
 }
 
+// maxScopeDepth bounds hierarchy walks so a cycle in scope_hierarchy cannot loop forever.
+const maxScopeDepth = 16
+
+// getAncestorScopes walks scope_hierarchy upwards and returns the ancestors of a
+// scope instance, nearest parent first.
+func (s *Service) getAncestorScopes(ctx context.Context, scopeType, scopeID string) ([]Scope, error) {
+	var ancestors []Scope
+	current := NewScope(scopeType, scopeID)
+	for i := 0; i < maxScopeDepth; i++ {
+		parent, err := s.getParentScope(ctx, current.Type, current.ID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			break
+		}
+		current = NewScope(parent.ParentScopeType, parent.ParentScopeID)
+		ancestors = append(ancestors, current)
+	}
+	return ancestors, nil
+}
+
+// getDescendantScopes walks scope_hierarchy downwards and returns the
+// descendants of a scope instance, breadth first.
+func (s *Service) getDescendantScopes(ctx context.Context, scopeType, scopeID string) ([]Scope, error) {
+	root := NewScope(scopeType, scopeID)
+	seen := map[Scope]bool{root: true}
+	var descendants []Scope
+	level := []Scope{root}
+	for depth := 0; depth < maxScopeDepth && len(level) > 0; depth++ {
+		var next []Scope
+		for _, parent := range level {
+			var children []ScopeHierarchy
+			err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&children).
+				Where("parent_scope_type = ? AND parent_scope_id = ?", parent.Type, parent.ID).
+				Scan(ctx), "GetChildScopes").Err()
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				scope := NewScope(child.ScopeType, child.ScopeID)
+				if !seen[scope] {
+					seen[scope] = true
+					next = append(next, scope)
+				}
+			}
+		}
+		descendants = append(descendants, next...)
+		level = next
+	}
+	return descendants, nil
+}
+
+// checkEscalation rejects assigning a role that grants permissions the actor
+// does not hold, when the registry prevents escalation.
+func (s *Service) checkEscalation(ctx context.Context, actorChecker *Checker, role, scopeType, scopeID string) error {
+	if !s.registry.IsEscalationPrevented() {
+		return nil
+	}
+
+	missing, err := actorChecker.EscalatingPermissionsContext(ctx, role, scopeType, scopeID)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return NewError(ErrPrivilegeEscalation,
+			fmt.Sprintf("role %q grants permissions the actor does not hold: %s", role, strings.Join(missing, ", "))).
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithActor(actorChecker.UserID())
+	}
+	return nil
+}
+
+// insertAssignment stores a role assignment, denormalizing the parent scope from scope_hierarchy.
+func (s *Service) insertAssignment(ctx context.Context, userID, role, scopeType, scopeID string) error {
+	return s.insertExpiringAssignment(ctx, userID, role, scopeType, scopeID, nil)
+}
+
+// insertExpiringAssignment stores a role assignment that stops granting the role
+// at expiresAt. A nil expiresAt creates a permanent assignment.
+func (s *Service) insertExpiringAssignment(ctx context.Context, userID, role, scopeType, scopeID string, expiresAt *time.Time) error {
+	// Get parent scope if defined
+	var parentScopeType, parentScopeID string
+	scopeDef := s.registry.GetScope(scopeType)
+	if scopeDef != nil && scopeDef.GetParentScope() != "" {
+		// Look up parent from scope_hierarchy table
+		parent, err := s.getParentScope(ctx, scopeType, scopeID)
+		if err == nil && parent != nil {
+			parentScopeType = parent.ParentScopeType
+			parentScopeID = parent.ParentScopeID
+		}
+	}
+
+	assignment := &RoleAssignment{
+		UserID:          userID,
+		Role:            role,
+		ScopeType:       scopeType,
+		ScopeID:         scopeID,
+		ParentScopeType: parentScopeType,
+		ParentScopeID:   parentScopeID,
+		ExpiresAt:       expiresAt,
+	}
+
+	// An expired assignment of the same role is replaced in place
+	result, err := s.conn(ctx).NewInsert().Model(assignment).
+		On("CONFLICT (user_id, role, scope_type, scope_id) DO UPDATE").
+		Set("parent_scope_type = EXCLUDED.parent_scope_type").
+		Set("parent_scope_id = EXCLUDED.parent_scope_id").
+		Set("expires_at = EXCLUDED.expires_at").
+		Set("created_at = EXCLUDED.created_at").
+		Where("?TableAlias.expires_at IS NOT NULL AND ?TableAlias.expires_at <= current_timestamp").
+		Exec(ctx)
+	err = dbkit.WithErr(result, err, "CreateRoleAssignment").Err()
+	if err != nil {
+		return NewError(ErrDatabaseError, "failed to create role assignment").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithUser(userID)
+	}
+
+	// Nothing was written: a concurrent request assigned the role first
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return NewError(ErrRoleAlreadyAssigned, "user already has this role").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithUser(userID)
+	}
+	return nil
+}
+
+// deleteAssignment removes a role assignment and reports ErrRoleNotAssigned if no row matched.
+func (s *Service) deleteAssignment(ctx context.Context, userID, role, scopeType, scopeID string) error {
+	result, err := s.conn(ctx).NewDelete().Table("role_assignments").Where("user_id = ? AND role = ? AND scope_type = ? AND scope_id = ?", userID, role, scopeType, scopeID).Exec(ctx)
+	err = dbkit.WithErr(result, err, "DeleteRoleAssignment").Err()
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return NewError(ErrRoleNotAssigned, "user does not have this role").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithUser(userID)
+	}
+	return nil
+}
+
+// containsString reports whether a slice contains a value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString returns a copy of values without any occurrence of value.
+func removeString(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// newCorrelationID returns a random UUID used to link related audit entries.
+func newCorrelationID() string {
+	var b [16]byte
+	_, _ = cryptorand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 func (s *Service) logAudit(ctx context.Context, entry *AuditEntry) error {
-	_, err := s.db.NewInsert().Model(entry.ToModel()).Exec(ctx)
+	if imp := GetImpersonation(ctx); imp != nil && entry.ImpersonatedUserID == "" {
+		entry.ImpersonatedUserID = imp.SubjectID
+	}
+	if entry.CorrelationID == "" {
+		entry.CorrelationID = correlationIDFromContext(ctx)
+	}
+	if metadata := auditMetadataFromContext(ctx); len(metadata) > 0 {
+		merged := make(map[string]any, len(metadata)+len(entry.Metadata))
+		for k, v := range metadata {
+			merged[k] = v
+		}
+		for k, v := range entry.Metadata {
+			merged[k] = v
+		}
+		entry.Metadata = merged
+	}
+	_, err := s.conn(ctx).NewInsert().Model(entry.ToModel()).Exec(ctx)
 	return dbkit.WithErr1(err, "LogAudit").Err()
 }
 
@@ -48,6 +260,13 @@ func (s *Service) logAudit(ctx context.Context, entry *AuditEntry) error {
 // AssignDirect assigns a role to a user without pre-checks for better performance.
 // This method bypasses GetUserRoles calls and handles duplicate key constraints gracefully.
 func (s *Service) AssignDirect(ctx context.Context, userID, role, scopeType, scopeID string) error {
+	return s.withAssignmentLocks(ctx, scopeType, []string{role}, func(ctx context.Context) error {
+		return s.assignDirect(ctx, userID, role, scopeType, scopeID)
+	})
+}
+
+// assignDirect implements AssignDirect.
+func (s *Service) assignDirect(ctx context.Context, userID, role, scopeType, scopeID string) error {
 	// Validate role exists for scope
 	if err := s.registry.ValidateRole(role, scopeType); err != nil {
 		return err
@@ -59,6 +278,11 @@ func (s *Service) AssignDirect(ctx context.Context, userID, role, scopeType, sco
 		return NewError(ErrNoActorID, "actor ID required for role assignment")
 	}
 
+	// Enforce separation-of-duties constraints
+	if err := s.checkConstraints(ctx, userID, role, scopeType, scopeID, nil); err != nil {
+		return err
+	}
+
 	// Create assignment
 	assignment := &RoleAssignment{
 		UserID:    userID,
@@ -68,7 +292,7 @@ func (s *Service) AssignDirect(ctx context.Context, userID, role, scopeType, sco
 	}
 
 	// Direct assignment with conflict resolution
-	result, err := s.db.NewInsert().
+	result, err := s.conn(ctx).NewInsert().
 		Model(assignment).
 		On("CONFLICT (user_id, role, scope_type, scope_id) DO NOTHING").
 		Exec(ctx)
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fernandezvara/dbkit"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,6 +22,18 @@ func TestServiceGetUserRoleNames(t *testing.T) {
 	})
 }
 
+// TestServiceConn tests selecting the database handle for a call
+func TestServiceConn(t *testing.T) {
+	service := &Service{db: nil, registry: NewRegistry()}
+
+	assert.Nil(t, service.conn(context.Background()))
+
+	tx := &dbkit.Tx{}
+	assert.Same(t, tx, service.conn(withTx(context.Background(), tx)))
+	assert.Same(t, tx, service.activeTx(withTx(context.Background(), tx)))
+	assert.Nil(t, service.activeTx(context.Background()))
+}
+
 // TestServiceGetParentScope tests retrieving parent scope information
 func TestServiceGetParentScope(t *testing.T) {
 	registry := NewRegistry()
@@ -364,3 +377,23 @@ func TestServiceHelpersEdgeCases(t *testing.T) {
 		})
 	})
 }
+
+// TestStringSliceHelpers tests the internal slice helpers
+func TestStringSliceHelpers(t *testing.T) {
+	values := []string{"owner", "admin", "owner"}
+
+	assert.True(t, containsString(values, "admin"))
+	assert.False(t, containsString(values, "viewer"))
+	assert.False(t, containsString(nil, "admin"))
+
+	assert.Equal(t, []string{"admin"}, removeString(values, "owner"))
+	assert.Equal(t, values, removeString(values, "viewer"))
+	assert.Empty(t, removeString(nil, "owner"))
+}
+
+// TestNewCorrelationID tests correlation ID generation
+func TestNewCorrelationID(t *testing.T) {
+	id := newCorrelationID()
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.NotEqual(t, id, newCorrelationID())
+}
@@ -0,0 +1,211 @@
+package rolekit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// CHANGE IMPACT
+// ============================================================================
+
+// ImpactReport describes how replacing the registry would change the
+// effective permissions of the users holding roles.
+type ImpactReport struct {
+	Diff   *RegistryDiff
+	Scopes []ScopeImpact // Per scope type with affected users, sorted by scope type
+}
+
+// ScopeImpact counts the users whose effective permissions change in scopes
+// of one type. A user is counted once however many scopes of the type they are
+// affected in, and in both Gaining and Losing when they gain some permissions
+// and lose others.
+type ScopeImpact struct {
+	ScopeType     string
+	UsersAffected int
+	Gaining       int                // Users gaining permissions
+	Losing        int                // Users losing permissions
+	Changes       []PermissionChange // Per user and scope, with ListAffectedUsers
+}
+
+// PermissionChange is the change in a user's effective permissions in a scope.
+type PermissionChange struct {
+	UserID    string
+	ScopeType string
+	ScopeID   string
+	Gained    []string // New permission patterns not covered by the current ones
+	Lost      []string // Current permission patterns not covered by the new ones
+}
+
+// IsEmpty reports whether no user's effective permissions change.
+func (r *ImpactReport) IsEmpty() bool {
+	return len(r.Scopes) == 0
+}
+
+// String returns the registry diff followed by the impact per scope type, for
+// review in pull requests:
+//
+//	~ role project/editor: +files.delete -files.share
+//
+//	project: 12 users affected (12 gaining, 3 losing)
+//	  alice project/proj1: +files.delete -files.share
+func (r *ImpactReport) String() string {
+	var b strings.Builder
+	b.WriteString(r.Diff.String())
+	if r.IsEmpty() {
+		b.WriteString("\nno users affected\n")
+		return b.String()
+	}
+	for _, scope := range r.Scopes {
+		fmt.Fprintf(&b, "\n%s: %d users affected (%d gaining, %d losing)\n",
+			scope.ScopeType, scope.UsersAffected, scope.Gaining, scope.Losing)
+		for _, change := range scope.Changes {
+			fmt.Fprintf(&b, "  %s %s/%s:%s\n", change.UserID, change.ScopeType, change.ScopeID,
+				formatPermissionChanges(change.Gained, change.Lost))
+		}
+	}
+	return b.String()
+}
+
+// ImpactOption configures an impact report.
+type ImpactOption func(*impactOptions)
+
+type impactOptions struct {
+	listUsers bool
+}
+
+// ListAffectedUsers includes the change of every affected user and scope in
+// the report, not only the counts.
+func ListAffectedUsers() ImpactOption {
+	return func(o *impactOptions) {
+		o.listUsers = true
+	}
+}
+
+// ImpactOf reports the users whose effective permissions would change if the
+// service used newRegistry instead of its current registry. Only scope types
+// with changed or removed roles are read. A user's permissions in a scope are
+// the union of the unconditional patterns of their roles there, compared like
+// EscalationReport does: swapping "files.read" for "files.*" is a gain, while
+// removing "files.read" from a role that also holds "files.*" changes nothing.
+// Adding a condition to a pattern (see RoleDefinition.When) is a loss of that
+// pattern. Delegations and resource grants are not considered.
+//
+// Example:
+//
+//	report, err := service.ImpactOf(ctx, proposed, rolekit.ListAffectedUsers())
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(report)
+func (s *Service) ImpactOf(ctx context.Context, newRegistry *Registry, opts ...ImpactOption) (*ImpactReport, error) {
+	options := impactOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	diff := s.registry.Diff(newRegistry)
+	report := &ImpactReport{Diff: diff}
+
+	changed := make(map[string]bool)
+	for _, role := range diff.changedRoles() {
+		changed[role.ScopeType] = true
+	}
+
+	var assignments []RoleAssignment
+	for _, scopeType := range sortedStrings(mapKeys(changed)) {
+		found, err := s.findAssignments(ctx, AssignmentFilter{ScopeType: scopeType})
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, found...)
+	}
+
+	report.Scopes = permissionImpact(s.registry, newRegistry, assignments, options.listUsers)
+	return report, nil
+}
+
+// permissionImpact compares the effective permissions the assignments give
+// each user in each scope under two registries. Only unconditional patterns
+// count as held, so adding a condition to a pattern loses it and removing the
+// last one gains it.
+func permissionImpact(current, next *Registry, assignments []RoleAssignment, listUsers bool) []ScopeImpact {
+	type holder struct {
+		userID, scopeType, scopeID string
+	}
+	roles := make(map[holder][]string)
+	for _, a := range assignments {
+		h := holder{a.UserID, a.ScopeType, a.ScopeID}
+		roles[h] = append(roles[h], a.Role)
+	}
+
+	type userSets struct {
+		affected, gaining, losing map[string]bool
+		changes                   []PermissionChange
+	}
+	byScope := make(map[string]*userSets)
+	for h, held := range roles {
+		var before, after []string
+		for _, role := range held {
+			before = append(before, unconditionalRolePermissions(current, role, h.scopeType)...)
+			after = append(after, unconditionalRolePermissions(next, role, h.scopeType)...)
+		}
+		gained := subtractStrings(uncoveredPermissions(before, after), nil)
+		lost := subtractStrings(uncoveredPermissions(after, before), nil)
+		if len(gained) == 0 && len(lost) == 0 {
+			continue
+		}
+
+		sets := byScope[h.scopeType]
+		if sets == nil {
+			sets = &userSets{affected: map[string]bool{}, gaining: map[string]bool{}, losing: map[string]bool{}}
+			byScope[h.scopeType] = sets
+		}
+		sets.affected[h.userID] = true
+		if len(gained) > 0 {
+			sets.gaining[h.userID] = true
+		}
+		if len(lost) > 0 {
+			sets.losing[h.userID] = true
+		}
+		if listUsers {
+			sets.changes = append(sets.changes, PermissionChange{
+				UserID:    h.userID,
+				ScopeType: h.scopeType,
+				ScopeID:   h.scopeID,
+				Gained:    gained,
+				Lost:      lost,
+			})
+		}
+	}
+
+	impacts := make([]ScopeImpact, 0, len(byScope))
+	for _, scopeType := range sortedStrings(mapKeys(byScope)) {
+		sets := byScope[scopeType]
+		sort.Slice(sets.changes, func(i, j int) bool {
+			if sets.changes[i].UserID != sets.changes[j].UserID {
+				return sets.changes[i].UserID < sets.changes[j].UserID
+			}
+			return sets.changes[i].ScopeID < sets.changes[j].ScopeID
+		})
+		impacts = append(impacts, ScopeImpact{
+			ScopeType:     scopeType,
+			UsersAffected: len(sets.affected),
+			Gaining:       len(sets.gaining),
+			Losing:        len(sets.losing),
+			Changes:       sets.changes,
+		})
+	}
+	return impacts
+}
+
+// unconditionalRolePermissions returns the patterns a role grants in a scope
+// type on every check, or nil when the registry does not define the role.
+func unconditionalRolePermissions(registry *Registry, role, scopeType string) []string {
+	if roleDef := registry.GetRole(role, scopeType); roleDef != nil {
+		return roleDef.unconditionalPermissions()
+	}
+	return nil
+}
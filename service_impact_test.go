@@ -0,0 +1,129 @@
+package rolekit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionImpact(t *testing.T) {
+	current := NewRegistry()
+	current.DefineScope("project").
+		Role("admin").Permissions("files.*").
+		Role("editor").Permissions("files.read", "files.share").
+		Role("guest").Permissions("files.read")
+
+	proposed := NewRegistry()
+	proposed.DefineScope("project").
+		Role("admin").Permissions("files.*").
+		Role("editor").Permissions("files.read", "files.delete")
+
+	assignments := []RoleAssignment{
+		{UserID: "alice", Role: "editor", ScopeType: "project", ScopeID: "proj1"},
+		{UserID: "alice", Role: "editor", ScopeType: "project", ScopeID: "proj2"},
+		{UserID: "bob", Role: "editor", ScopeType: "project", ScopeID: "proj1"},
+		{UserID: "bob", Role: "admin", ScopeType: "project", ScopeID: "proj1"}, // files.* covers the change
+		{UserID: "carol", Role: "guest", ScopeType: "project", ScopeID: "proj1"},
+	}
+
+	t.Run("Counts", func(t *testing.T) {
+		impacts := permissionImpact(current, proposed, assignments, false)
+		require.Len(t, impacts, 1)
+		assert.Equal(t, "project", impacts[0].ScopeType)
+		assert.Equal(t, 2, impacts[0].UsersAffected) // alice (twice) and carol
+		assert.Equal(t, 1, impacts[0].Gaining)
+		assert.Equal(t, 2, impacts[0].Losing)
+		assert.Empty(t, impacts[0].Changes)
+	})
+
+	t.Run("Listed users", func(t *testing.T) {
+		impacts := permissionImpact(current, proposed, assignments, true)
+		require.Len(t, impacts, 1)
+		assert.Equal(t, []PermissionChange{
+			{UserID: "alice", ScopeType: "project", ScopeID: "proj1", Gained: []string{"files.delete"}, Lost: []string{"files.share"}},
+			{UserID: "alice", ScopeType: "project", ScopeID: "proj2", Gained: []string{"files.delete"}, Lost: []string{"files.share"}},
+			{UserID: "carol", ScopeType: "project", ScopeID: "proj1", Lost: []string{"files.read"}},
+		}, impacts[0].Changes)
+	})
+
+	t.Run("Broadened pattern is a gain only", func(t *testing.T) {
+		broadened := NewRegistry()
+		broadened.DefineScope("project").Role("guest").Permissions("files.*")
+		impacts := permissionImpact(current, broadened, assignments[4:], true)
+		require.Len(t, impacts, 1)
+		assert.Equal(t, []string{"files.*"}, impacts[0].Changes[0].Gained)
+		assert.Empty(t, impacts[0].Changes[0].Lost)
+	})
+
+	t.Run("Added condition is a loss", func(t *testing.T) {
+		conditional := NewRegistry()
+		conditional.DefineScope("project").
+			Role("editor").Permissions("files.read", "files.share").
+			When("files.share", "size < 1000")
+		impacts := permissionImpact(current, conditional, assignments[:1], true)
+		require.Len(t, impacts, 1)
+		assert.Equal(t, []string{"files.share"}, impacts[0].Changes[0].Lost)
+		assert.Empty(t, impacts[0].Changes[0].Gained)
+
+		// Removing the condition again is a gain
+		impacts = permissionImpact(conditional, current, assignments[:1], true)
+		require.Len(t, impacts, 1)
+		assert.Equal(t, []string{"files.share"}, impacts[0].Changes[0].Gained)
+	})
+
+	t.Run("Report string", func(t *testing.T) {
+		report := &ImpactReport{
+			Diff:   current.Diff(proposed),
+			Scopes: permissionImpact(current, proposed, assignments[:1], true),
+		}
+		assert.Equal(t, "- role project/guest\n"+
+			"~ role project/editor: +files.delete -files.share\n"+
+			"\nproject: 1 users affected (1 gaining, 1 losing)\n"+
+			"  alice project/proj1: +files.delete -files.share\n", report.String())
+
+		empty := &ImpactReport{Diff: current.Diff(current)}
+		assert.True(t, empty.IsEmpty())
+		assert.Equal(t, "\nno users affected\n", empty.String())
+	})
+}
+
+func TestServiceImpactOf(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	developerID := helper.CreateTestUser("developer")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	if err := helper.SetupDeveloper(developerID, orgID); err != nil {
+		t.Fatalf("Failed to setup developer: %v", err)
+	}
+
+	proposed := NewRegistry()
+	defineTestRoles(proposed)
+	proposed.GetScope("organization").Role("developer").Permissions("team.read", "task.read")
+
+	report, err := service.ImpactOf(helper.GetContext(), proposed, ListAffectedUsers())
+	require.NoError(t, err)
+	require.Len(t, report.Diff.ChangedRoles, 1)
+	require.Len(t, report.Scopes, 1)
+	assert.Equal(t, "organization", report.Scopes[0].ScopeType)
+
+	var change *PermissionChange
+	for i, c := range report.Scopes[0].Changes {
+		assert.NotEqual(t, adminID, c.UserID, "super_admin is unchanged")
+		if c.UserID == developerID && c.ScopeID == orgID {
+			change = &report.Scopes[0].Changes[i]
+		}
+	}
+	require.NotNil(t, change)
+	assert.Equal(t, []string{"task.*"}, change.Lost)
+	assert.Empty(t, change.Gained, "task.read was covered by task.*")
+}
@@ -0,0 +1,170 @@
+package rolekit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// IMPERSONATION
+// ============================================================================
+
+const (
+	// ImpersonatePermission lets an actor view a scope as another user.
+	ImpersonatePermission = "users.impersonate"
+
+	// ImpersonateWritePermission additionally lets the impersonated checker
+	// grant write permissions and assignment rights.
+	ImpersonateWritePermission = "users.impersonate_write"
+)
+
+// DefaultReadActions are the permission actions (last segment) an impersonated
+// checker grants when writes are not allowed.
+var DefaultReadActions = []string{"read", "list", "view"}
+
+// Impersonation describes an actor viewing a scope as another user.
+type Impersonation struct {
+	ActorID     string    // Support user doing the impersonation
+	SubjectID   string    // User being impersonated
+	Scope       Scope     // Scope the impersonation is limited to
+	AllowWrites bool      // Whether write permissions are granted
+	ReadActions []string  // Actions treated as reads when writes are not allowed
+	StartedAt   time.Time // When the session started
+}
+
+// ImpersonationOption configures an impersonation session.
+type ImpersonationOption func(*Impersonation)
+
+// AllowImpersonatedWrites grants the subject's write permissions too. The
+// actor must also hold ImpersonateWritePermission in the scope.
+func AllowImpersonatedWrites() ImpersonationOption {
+	return func(imp *Impersonation) {
+		imp.AllowWrites = true
+	}
+}
+
+// WithReadActions overrides DefaultReadActions for the session.
+//
+// Example:
+//
+//	service.Impersonate(ctx, customerID, "organization", orgID,
+//	    rolekit.WithReadActions("read", "list", "export"))
+func WithReadActions(actions ...string) ImpersonationOption {
+	return func(imp *Impersonation) {
+		imp.ReadActions = actions
+	}
+}
+
+// Impersonate lets the actor in the context "view as" another user in a scope.
+// The actor must hold ImpersonatePermission in the scope. It returns a context
+// carrying the session (user ID set to the subject, actor ID to the real actor)
+// and a Checker for the subject that only answers for the impersonated scope
+// and, unless AllowImpersonatedWrites is used, only grants read permissions and
+// no assignment rights. Every audit entry written with the returned context
+// records the impersonated subject.
+//
+// Example:
+//
+//	ctx = rolekit.WithActorID(ctx, supportAgentID)
+//	ctx, checker, err := service.Impersonate(ctx, customerID, "organization", orgID)
+//	if err != nil {
+//	    return err
+//	}
+//	canSee := checker.HasPermission("invoices.read", "organization", orgID) // subject's view
+//	checker.HasPermission("invoices.delete", "organization", orgID)         // false: writes blocked
+func (s *Service) Impersonate(ctx context.Context, subjectID, scopeType, scopeID string, opts ...ImpersonationOption) (context.Context, *Checker, error) {
+	if err := s.registry.ValidateScope(scopeType); err != nil {
+		return nil, nil, err
+	}
+
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return nil, nil, NewError(ErrNoActorID, "actor ID required for impersonation")
+	}
+	if subjectID == "" || subjectID == actorID {
+		return nil, nil, NewError(ErrUnauthorized, "impersonation requires another user").
+			WithScope(scopeType, scopeID).
+			WithUser(subjectID).
+			WithActor(actorID)
+	}
+
+	imp := &Impersonation{
+		ActorID:     actorID,
+		SubjectID:   subjectID,
+		Scope:       NewScope(scopeType, scopeID),
+		ReadActions: DefaultReadActions,
+		StartedAt:   time.Now(),
+	}
+	for _, opt := range opts {
+		opt(imp)
+	}
+
+	actorChecker, err := s.GetChecker(ctx, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	required := []string{ImpersonatePermission}
+	if imp.AllowWrites {
+		required = append(required, ImpersonateWritePermission)
+	}
+	for _, permission := range required {
+		if !actorChecker.HasPermission(permission, scopeType, scopeID) {
+			return nil, nil, NewError(ErrUnauthorized, "actor lacks "+permission).
+				WithScope(scopeType, scopeID).
+				WithUser(subjectID).
+				WithActor(actorID)
+		}
+	}
+
+	checker, err := s.GetChecker(ctx, subjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	checker.impersonation = imp
+
+	ctx = WithUserID(WithActorID(ctx, actorID), subjectID)
+	ctx = withImpersonation(WithChecker(ctx, checker), imp)
+
+	audit := GetAuditContext(ctx)
+	err = s.logAudit(ctx, &AuditEntry{
+		ActorID:      actorID,
+		Action:       AuditActionImpersonationStarted,
+		TargetUserID: subjectID,
+		ScopeType:    scopeType,
+		ScopeID:      scopeID,
+		ActorRoles:   actorChecker.GetRoles(scopeType, scopeID),
+		IPAddress:    audit.IPAddress,
+		UserAgent:    audit.UserAgent,
+		RequestID:    audit.RequestID,
+		Metadata:     map[string]any{"allow_writes": imp.AllowWrites},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ctx, checker, nil
+}
+
+// Impersonation returns the impersonation session this checker was built for,
+// or nil for a regular checker.
+func (c *Checker) Impersonation() *Impersonation {
+	return c.impersonation
+}
+
+// covers reports whether a scope instance is within the impersonated scope.
+func (imp *Impersonation) covers(scopeType, scopeID string) bool {
+	return imp.Scope.Type == scopeType && (imp.Scope.ID == scopeID || imp.Scope.IsWildcard())
+}
+
+// permits reports whether the impersonated checker may grant a permission.
+func (imp *Impersonation) permits(permission, scopeType, scopeID string) bool {
+	if !imp.covers(scopeType, scopeID) {
+		return false
+	}
+	if imp.AllowWrites {
+		return true
+	}
+	action := permission[strings.LastIndex(permission, ".")+1:]
+	return containsString(imp.ReadActions, action)
+}
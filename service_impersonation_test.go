@@ -0,0 +1,138 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckerImpersonation tests the restrictions of an impersonated checker
+func TestCheckerImpersonation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("admin").Permissions("invoices.*", "members.*").CanAssign("*").
+		Role("member").Permissions("invoices.read")
+
+	roles := NewUserRoles("customer1", []RoleAssignment{
+		{UserID: "customer1", Role: "admin", ScopeType: "organization", ScopeID: "org1"},
+		{UserID: "customer1", Role: "admin", ScopeType: "organization", ScopeID: "org2"},
+	})
+
+	t.Run("Read only", func(t *testing.T) {
+		checker := NewChecker("customer1", roles, registry, nil)
+		checker.impersonation = &Impersonation{
+			SubjectID:   "customer1",
+			Scope:       NewScope("organization", "org1"),
+			ReadActions: DefaultReadActions,
+		}
+
+		assert.True(t, checker.HasPermission("invoices.read", "organization", "org1"))
+		assert.True(t, checker.HasPermission("members.list", "organization", "org1"))
+		assert.False(t, checker.HasPermission("invoices.delete", "organization", "org1"))
+		assert.False(t, checker.CanAssignRole("member", "organization", "org1"))
+		assert.Nil(t, checker.GetAssignableRoles("organization", "org1"))
+
+		// Other scopes are outside the impersonation
+		assert.False(t, checker.HasPermission("invoices.read", "organization", "org2"))
+		assert.NotNil(t, checker.Impersonation())
+	})
+
+	t.Run("Writes allowed", func(t *testing.T) {
+		checker := NewChecker("customer1", roles, registry, nil)
+		checker.impersonation = &Impersonation{
+			SubjectID:   "customer1",
+			Scope:       NewScope("organization", "org1"),
+			AllowWrites: true,
+		}
+
+		assert.True(t, checker.HasPermission("invoices.delete", "organization", "org1"))
+		assert.True(t, checker.CanAssignRole("member", "organization", "org1"))
+		assert.False(t, checker.CanAssignRole("member", "organization", "org2"))
+	})
+
+	t.Run("Regular checker", func(t *testing.T) {
+		checker := NewChecker("customer1", roles, registry, nil)
+		assert.Nil(t, checker.Impersonation())
+		assert.True(t, checker.HasPermission("invoices.delete", "organization", "org2"))
+	})
+	t.Run("Checkers built from an impersonated context", func(t *testing.T) {
+		service := &Service{registry: registry}
+		imp := &Impersonation{
+			SubjectID:   "customer1",
+			Scope:       NewScope("organization", "org1"),
+			ReadActions: DefaultReadActions,
+		}
+		ctx := withImpersonation(context.Background(), imp)
+
+		subject := service.newChecker(ctx, "customer1", roles)
+		assert.Same(t, imp, subject.Impersonation())
+		assert.False(t, subject.HasPermission("invoices.delete", "organization", "org1"))
+		assert.False(t, subject.HasPermission("invoices.read", "organization", "org2"))
+
+		// The session only bounds the impersonated subject
+		assert.Nil(t, service.newChecker(ctx, "support1", roles).Impersonation())
+	})
+}
+
+// TestServiceImpersonateValidation tests argument validation before any database access
+func TestServiceImpersonateValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("support").Permissions(ImpersonatePermission)
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "support1")
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		_, _, err := service.Impersonate(ctx, "customer1", "unknown", "org1")
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, _, err := service.Impersonate(context.Background(), "customer1", "organization", "org1")
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		_, _, err := service.Impersonate(ctx, "support1", "organization", "org1")
+		assert.True(t, IsUnauthorized(err))
+	})
+}
+
+// TestServiceImpersonateDatabase tests impersonation with real database
+func TestServiceImpersonateDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	supportID := helper.CreateTestUser("support")
+	customerID := helper.CreateTestUser("customer")
+	if err := helper.SetupAdminUser(supportID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	if err := helper.SetupViewer(customerID, orgID); err != nil {
+		t.Fatalf("Failed to setup viewer: %v", err)
+	}
+
+	// The customer holds no impersonate permission
+	_, _, err := service.Impersonate(WithActorID(helper.GetContext(), customerID), supportID, "organization", orgID)
+	assert.True(t, IsUnauthorized(err))
+
+	ctx, checker, err := service.Impersonate(WithActorID(helper.GetContext(), supportID), customerID, "organization", orgID)
+	require.NoError(t, err)
+	assert.Equal(t, customerID, GetUserID(ctx))
+	assert.Equal(t, supportID, GetActorID(ctx))
+	assert.Same(t, checker, GetChecker(ctx))
+	assert.False(t, checker.CanAssignRole("viewer", "organization", orgID))
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithImpersonatedUser(customerID))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, supportID, logs[0].ActorID)
+	assert.Equal(t, string(AuditActionImpersonationStarted), logs[0].Action)
+}
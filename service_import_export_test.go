@@ -0,0 +1,178 @@
+package rolekit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecordEncodingRoundTrip tests writing and reading export records in both formats
+func TestRecordEncodingRoundTrip(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []ExportRecord{
+		{Kind: RecordAssignment, UserID: "user1", Role: "admin", ScopeType: "organization", ScopeID: "org1"},
+		{Kind: RecordAssignment, UserID: "user2", Role: "viewer", ScopeType: "project", ScopeID: "proj1", ExpiresAt: &expiresAt},
+		{Kind: RecordScopeParent, ScopeType: "project", ScopeID: "proj1", ParentScopeType: "organization", ParentScopeID: "org1"},
+	}
+
+	for _, format := range []ExportFormat{FormatCSV, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := newRecordEncoder(&buf, format)
+			require.NoError(t, err)
+			for _, record := range records {
+				require.NoError(t, enc.encode(record))
+			}
+			require.NoError(t, enc.flush())
+
+			dec, err := newRecordDecoder(&buf, format)
+			require.NoError(t, err)
+			var decoded []ExportRecord
+			var lines []int
+			for {
+				record, line, err := dec.decode()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				decoded = append(decoded, record)
+				lines = append(lines, line)
+			}
+			require.Len(t, decoded, len(records))
+			assert.Equal(t, records[0], decoded[0])
+			require.NotNil(t, decoded[1].ExpiresAt)
+			assert.True(t, expiresAt.Equal(*decoded[1].ExpiresAt))
+			assert.Equal(t, records[2], decoded[2])
+
+			if format == FormatCSV {
+				assert.Equal(t, []int{2, 3, 4}, lines)
+			} else {
+				assert.Equal(t, []int{1, 2, 3}, lines)
+			}
+		})
+	}
+
+	t.Run("Unknown format", func(t *testing.T) {
+		_, err := newRecordEncoder(&bytes.Buffer{}, "xml")
+		assert.True(t, IsInvalidImport(err))
+	})
+
+	t.Run("Missing CSV column", func(t *testing.T) {
+		_, err := newRecordDecoder(strings.NewReader("kind,user_id\n"), FormatCSV)
+		assert.True(t, IsInvalidImport(err))
+	})
+}
+
+// TestServiceImportValidation tests that invalid records are reported per line before any database access
+func TestServiceImportValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("admin").Permissions("*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "admin1")
+
+	input := strings.Join([]string{
+		"kind,user_id,role,scope_type,scope_id,parent_scope_type,parent_scope_id,expires_at",
+		"assignment,user1,admin,organization,org1,,,",
+		"assignment,user2,owner,organization,org1,,,",
+		"assignment,user3,admin,team,team1,,,",
+		"scope_parent,,,organization,org1,tenant,t1,",
+		"group,,,organization,org1,,,",
+	}, "\n")
+
+	report, err := service.ImportAssignments(ctx, strings.NewReader(input), FormatCSV)
+	assert.True(t, IsInvalidImport(err))
+	require.NotNil(t, report)
+	assert.Equal(t, 5, report.Records)
+	require.Len(t, report.Errors, 4)
+	assert.Equal(t, 3, report.Errors[0].Line)
+	assert.ErrorIs(t, report.Errors[0], ErrInvalidRole)
+	assert.Equal(t, 4, report.Errors[1].Line)
+	assert.ErrorIs(t, report.Errors[1], ErrInvalidScope)
+	assert.ErrorIs(t, report.Errors[2], ErrInvalidScope)
+	assert.ErrorIs(t, report.Errors[3], ErrInvalidImport)
+	assert.Equal(t, 0, report.Assignments)
+
+	_, err = service.ImportAssignments(ctx, strings.NewReader(input), FormatCSV, OnConflict("merge"))
+	assert.True(t, IsInvalidImport(err))
+
+	_, err = service.ImportAssignments(context.Background(), strings.NewReader(input), FormatCSV)
+	assert.ErrorIs(t, err, ErrNoActorID)
+}
+
+// TestServiceImportExportDatabase tests exporting and re-importing assignments with real database
+func TestServiceImportExportDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	devID := helper.CreateTestUser("dev")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), adminID)
+	require.NoError(t, service.Assign(ctx, devID, "developer", "organization", orgID))
+
+	var buf bytes.Buffer
+	require.NoError(t, service.ExportAssignments(ctx, &buf, FormatJSON, AssignmentFilter{ScopeType: "organization", ScopeID: orgID}))
+	exported := buf.String()
+	assert.Contains(t, exported, devID)
+
+	require.NoError(t, service.Revoke(ctx, devID, "developer", "organization", orgID))
+
+	report, err := service.ImportAssignments(ctx, strings.NewReader(exported), FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Assignments)
+	assert.GreaterOrEqual(t, report.Skipped, 1)
+	helper.AssertRoleAssigned(devID, "developer", "organization", orgID)
+
+	_, err = service.ImportAssignments(ctx, strings.NewReader(exported), FormatJSON, OnConflict(ConflictFail))
+	assert.ErrorIs(t, err, ErrRoleAlreadyAssigned)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID(report.ImportID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	// Importing requires the right to assign each role, unless explicitly skipped
+	require.NoError(t, service.Revoke(ctx, devID, "developer", "organization", orgID))
+	otherCtx := WithActorID(helper.GetContext(), helper.CreateTestUser("other"))
+	_, err = service.ImportAssignments(otherCtx, strings.NewReader(exported), FormatJSON)
+	assert.True(t, IsCannotAssign(err))
+	helper.AssertRoleNotAssigned(devID, "developer", "organization", orgID)
+
+	_, err = service.ImportAssignments(otherCtx, strings.NewReader(exported), FormatJSON, SkipAssignmentChecks())
+	require.NoError(t, err)
+	helper.AssertRoleAssigned(devID, "developer", "organization", orgID)
+
+	// Overwrites are audited
+	report, err = service.ImportAssignments(ctx, strings.NewReader(exported), FormatJSON, OnConflict(ConflictOverwrite))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Overwritten)
+	logs, err = service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID(report.ImportID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	// Expired rows count as absent: the import assigns the role again
+	_, err = service.conn(ctx).NewUpdate().Model((*RoleAssignment)(nil)).
+		Set("expires_at = current_timestamp - interval '1 hour'").
+		Where("user_id = ?", devID).
+		Exec(ctx)
+	require.NoError(t, err)
+	helper.AssertRoleNotAssigned(devID, "developer", "organization", orgID)
+
+	report, err = service.ImportAssignments(ctx, strings.NewReader(exported), FormatJSON, OnConflict(ConflictFail))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Assignments)
+	helper.AssertRoleAssigned(devID, "developer", "organization", orgID)
+}
@@ -0,0 +1,316 @@
+package rolekit
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/fernandezvara/dbkit"
+)
+
+// ============================================================================
+// INVITATIONS
+// ============================================================================
+
+// DefaultInvitationTTL is how long an invitation stays valid when no TTL is given.
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+// CreateInvitation invites someone who has no user ID yet (identified by e.g.
+// their email address) to a role in a scope. The inviter is the actor in the
+// context and must be able to assign the role. The returned token is the only
+// way to accept the invitation: send it to the invitee, it is not stored.
+//
+// Example:
+//
+//	ctx = rolekit.WithActorID(ctx, adminID)
+//	invitation, token, err := service.CreateInvitation(ctx, "jane@example.com",
+//	    "developer", "project", projectID, 72*time.Hour)
+//	sendEmail("jane@example.com", "https://app.example.com/invite/"+token)
+func (s *Service) CreateInvitation(ctx context.Context, invitee, role, scopeType, scopeID string, ttl time.Duration) (*Invitation, string, error) {
+	// Validate role exists for scope
+	if err := s.registry.ValidateRole(role, scopeType); err != nil {
+		return nil, "", err
+	}
+
+	inviterID := GetActorID(ctx)
+	if inviterID == "" {
+		return nil, "", NewError(ErrNoActorID, "actor ID required for invitation")
+	}
+	if invitee == "" {
+		return nil, "", NewError(ErrInvalidInvitation, "invitee is required").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithActor(inviterID)
+	}
+	if ttl <= 0 {
+		ttl = DefaultInvitationTTL
+	}
+
+	inviterRoles, err := s.authorizeInviter(ctx, inviterID, role, scopeType, scopeID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	invitation := &Invitation{
+		Invitee:   invitee,
+		Role:      role,
+		ScopeType: scopeType,
+		ScopeID:   scopeID,
+		InviterID: inviterID,
+		TokenHash: hashInvitationToken(token),
+		Status:    InvitationPending,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		result, err := s.conn(ctx).NewInsert().Model(invitation).Returning("*").Exec(ctx)
+		if err = dbkit.WithErr(result, err, "CreateInvitation").Err(); err != nil {
+			return err
+		}
+
+		entry := s.invitationAudit(ctx, AuditActionInvited, invitation, inviterID, invitee)
+		entry.ActorRoles = inviterRoles.GetRoles(scopeType, scopeID)
+		entry.Metadata = map[string]any{"expires_at": invitation.ExpiresAt}
+		return s.logAudit(ctx, entry)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return invitation, token, nil
+}
+
+// AcceptInvitation redeems an invitation token for a user and assigns the
+// invited role. The assignment is made under the inviter's authority, which is
+// checked again at acceptance time: if the inviter lost the right to assign the
+// role, the invitation can no longer be accepted. Tokens are single-use.
+//
+// Example:
+//
+//	err := service.AcceptInvitation(ctx, tokenFromLink, newUserID)
+func (s *Service) AcceptInvitation(ctx context.Context, token, userID string) error {
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		invitation := new(Invitation)
+		err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(invitation).
+			Where("token_hash = ?", hashInvitationToken(token)).
+			For("UPDATE").
+			Scan(ctx), "AcceptInvitation").Err()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewError(ErrInvitationNotFound, "invitation token not found").WithUser(userID)
+			}
+			return err
+		}
+
+		if err := invitation.validate(time.Now()); err != nil {
+			return err
+		}
+
+		inviterRoles, err := s.authorizeInviter(ctx, invitation.InviterID, invitation.Role, invitation.ScopeType, invitation.ScopeID)
+		if err != nil {
+			return err
+		}
+
+		previousRoles, err := s.getUserRoleNames(ctx, userID, invitation.ScopeType, invitation.ScopeID)
+		if err != nil {
+			return err
+		}
+		if containsString(previousRoles, invitation.Role) {
+			return NewError(ErrRoleAlreadyAssigned, "user already has this role").
+				WithScope(invitation.ScopeType, invitation.ScopeID).
+				WithRole(invitation.Role).
+				WithUser(userID)
+		}
+
+		if err := s.checkConstraints(ctx, userID, invitation.Role, invitation.ScopeType, invitation.ScopeID, nil); err != nil {
+			return err
+		}
+		if err := s.checkCardinality(ctx, invitation.Role, invitation.ScopeType, invitation.ScopeID, 1); err != nil {
+			return err
+		}
+		if err := s.insertAssignment(ctx, userID, invitation.Role, invitation.ScopeType, invitation.ScopeID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		invitation.Status = InvitationAccepted
+		invitation.AcceptedBy = userID
+		invitation.AcceptedAt = &now
+		result, err := s.conn(ctx).NewUpdate().Model(invitation).
+			Column("status", "accepted_by", "accepted_at").
+			WherePK().
+			Exec(ctx)
+		if err = dbkit.WithErr(result, err, "AcceptInvitation").Err(); err != nil {
+			return err
+		}
+
+		entry := s.invitationAudit(ctx, AuditActionInvitationAccepted, invitation, invitation.InviterID, userID)
+		entry.ActorRoles = inviterRoles.GetRoles(invitation.ScopeType, invitation.ScopeID)
+		entry.PreviousRoles = previousRoles
+		entry.NewRoles = append(append([]string{}, previousRoles...), invitation.Role)
+		entry.Metadata = map[string]any{"invitee": invitation.Invitee}
+		return s.logAudit(ctx, entry)
+	})
+}
+
+// ListInvitations returns the open (pending and unexpired) invitations for a scope.
+//
+// Example:
+//
+//	invitations, err := service.ListInvitations(ctx, "project", projectID)
+func (s *Service) ListInvitations(ctx context.Context, scopeType, scopeID string) ([]Invitation, error) {
+	var invitations []Invitation
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&invitations).
+		Where("scope_type = ? AND scope_id = ? AND status = ? AND expires_at > current_timestamp",
+			scopeType, scopeID, InvitationPending).
+		Order("created_at ASC").
+		Scan(ctx), "ListInvitations").Err()
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation cancels a pending invitation. The actor in the context must
+// be the inviter or be able to assign the invited role.
+//
+// Example:
+//
+//	err := service.RevokeInvitation(ctx, invitationID)
+func (s *Service) RevokeInvitation(ctx context.Context, invitationID string) error {
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return NewError(ErrNoActorID, "actor ID required to revoke an invitation")
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		invitation := new(Invitation)
+		err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(invitation).
+			Where("id = ?", invitationID).
+			For("UPDATE").
+			Scan(ctx), "RevokeInvitation").Err()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewError(ErrInvitationNotFound, "invitation "+invitationID+" not found").WithActor(actorID)
+			}
+			return err
+		}
+
+		if invitation.Status != InvitationPending {
+			return NewError(ErrInvalidInvitation, "invitation is already "+string(invitation.Status)).
+				WithScope(invitation.ScopeType, invitation.ScopeID).
+				WithRole(invitation.Role).
+				WithActor(actorID)
+		}
+
+		actorRoles, err := s.GetUserRoles(ctx, actorID)
+		if err != nil {
+			return err
+		}
+		canAssign := actorID == invitation.InviterID
+		if !canAssign {
+			canAssign, err = s.newChecker(ctx, actorID, actorRoles).
+				CanAssignRoleContext(ctx, invitation.Role, invitation.ScopeType, invitation.ScopeID)
+			if err != nil {
+				return err
+			}
+		}
+		if !canAssign {
+			return NewError(ErrCannotAssign, "actor cannot revoke this invitation").
+				WithScope(invitation.ScopeType, invitation.ScopeID).
+				WithRole(invitation.Role).
+				WithActor(actorID)
+		}
+
+		invitation.Status = InvitationRevoked
+		result, err := s.conn(ctx).NewUpdate().Model(invitation).Column("status").WherePK().Exec(ctx)
+		if err = dbkit.WithErr(result, err, "RevokeInvitation").Err(); err != nil {
+			return err
+		}
+
+		entry := s.invitationAudit(ctx, AuditActionInvitationRevoked, invitation, actorID, invitation.Invitee)
+		entry.ActorRoles = actorRoles.GetRoles(invitation.ScopeType, invitation.ScopeID)
+		return s.logAudit(ctx, entry)
+	})
+}
+
+// validate reports whether the invitation can still be accepted.
+func (inv *Invitation) validate(now time.Time) error {
+	if inv.Status != InvitationPending {
+		return NewError(ErrInvalidInvitation, "invitation is already "+string(inv.Status)).
+			WithScope(inv.ScopeType, inv.ScopeID).
+			WithRole(inv.Role)
+	}
+	if !inv.ExpiresAt.After(now) {
+		return NewError(ErrInvalidInvitation, "invitation has expired").
+			WithScope(inv.ScopeType, inv.ScopeID).
+			WithRole(inv.Role)
+	}
+	return nil
+}
+
+// authorizeInviter verifies that the inviter can assign the role in the scope.
+func (s *Service) authorizeInviter(ctx context.Context, inviterID, role, scopeType, scopeID string) (*UserRoles, error) {
+	inviterRoles, err := s.GetUserRoles(ctx, inviterID)
+	if err != nil {
+		return nil, err
+	}
+
+	inviterChecker := s.newChecker(ctx, inviterID, inviterRoles)
+	canAssign, err := inviterChecker.CanAssignRoleContext(ctx, role, scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAssign {
+		return nil, NewError(ErrCannotAssign, "inviter cannot assign this role").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithActor(inviterID)
+	}
+	if err := s.checkEscalation(ctx, inviterChecker, role, scopeType, scopeID); err != nil {
+		return nil, err
+	}
+	return inviterRoles, nil
+}
+
+// invitationAudit builds the audit entry for a state change of an invitation.
+// The invitation ID is used as correlation ID so its history can be queried.
+func (s *Service) invitationAudit(ctx context.Context, action AuditAction, invitation *Invitation, actorID, targetID string) *AuditEntry {
+	audit := GetAuditContext(ctx)
+	return &AuditEntry{
+		ActorID:       actorID,
+		Action:        action,
+		TargetUserID:  targetID,
+		Role:          invitation.Role,
+		ScopeType:     invitation.ScopeType,
+		ScopeID:       invitation.ScopeID,
+		IPAddress:     audit.IPAddress,
+		UserAgent:     audit.UserAgent,
+		RequestID:     audit.RequestID,
+		CorrelationID: invitation.ID,
+	}
+}
+
+// newInvitationToken returns a random URL-safe token.
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashInvitationToken returns the stored form of a token.
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
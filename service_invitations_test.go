@@ -0,0 +1,117 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceCreateInvitationValidation tests argument validation before any database access
+func TestServiceCreateInvitationValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("project").
+		Role("admin").Permissions("*").CanAssign("*").
+		Role("developer").Permissions("code.*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "admin1")
+
+	t.Run("Invalid role", func(t *testing.T) {
+		_, _, err := service.CreateInvitation(ctx, "jane@example.com", "unknown", "project", "proj1", time.Hour)
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, _, err := service.CreateInvitation(context.Background(), "jane@example.com", "developer", "project", "proj1", time.Hour)
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("Missing invitee", func(t *testing.T) {
+		_, _, err := service.CreateInvitation(ctx, "", "developer", "project", "proj1", time.Hour)
+		assert.True(t, IsInvalidInvitation(err))
+	})
+}
+
+// TestInvitationValidate tests which invitations can still be accepted
+func TestInvitationValidate(t *testing.T) {
+	now := time.Now()
+
+	pending := &Invitation{Status: InvitationPending, ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, pending.validate(now))
+
+	expired := &Invitation{Status: InvitationPending, ExpiresAt: now.Add(-time.Hour)}
+	assert.True(t, IsInvalidInvitation(expired.validate(now)))
+
+	accepted := &Invitation{Status: InvitationAccepted, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, IsInvalidInvitation(accepted.validate(now)))
+
+	revoked := &Invitation{Status: InvitationRevoked, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, IsInvalidInvitation(revoked.validate(now)))
+}
+
+// TestInvitationToken tests token generation and hashing
+func TestInvitationToken(t *testing.T) {
+	first, err := newInvitationToken()
+	require.NoError(t, err)
+	second, err := newInvitationToken()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43) // 32 bytes, unpadded base64
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, hashInvitationToken(first), hashInvitationToken(first))
+	assert.NotEqual(t, first, hashInvitationToken(first))
+}
+
+// TestServiceInvitationDatabase tests the invitation lifecycle with real database
+func TestServiceInvitationDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	newUserID := helper.CreateTestUser("new-user")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), adminID)
+
+	invitation, token, err := service.CreateInvitation(ctx, "jane@example.com", "developer", "organization", orgID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, InvitationPending, invitation.Status)
+	assert.WithinDuration(t, time.Now().Add(DefaultInvitationTTL), invitation.ExpiresAt, time.Minute)
+
+	open, err := service.ListInvitations(ctx, "organization", orgID)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+
+	err = service.AcceptInvitation(helper.GetContext(), "not-a-token", newUserID)
+	assert.True(t, IsInvitationNotFound(err))
+
+	require.NoError(t, service.AcceptInvitation(helper.GetContext(), token, newUserID))
+	helper.AssertRoleAssigned(newUserID, "developer", "organization", orgID)
+
+	// Tokens are single-use
+	err = service.AcceptInvitation(helper.GetContext(), token, helper.CreateTestUser("other"))
+	assert.True(t, IsInvalidInvitation(err))
+
+	open, err = service.ListInvitations(ctx, "organization", orgID)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID(invitation.ID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	// Revoked invitations cannot be accepted
+	revoked, revokedToken, err := service.CreateInvitation(ctx, "bob@example.com", "viewer", "organization", orgID, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeInvitation(ctx, revoked.ID))
+	err = service.AcceptInvitation(helper.GetContext(), revokedToken, helper.CreateTestUser("bob"))
+	assert.True(t, IsInvalidInvitation(err))
+}
@@ -55,5 +55,241 @@ func (s *Service) Migrations() []dbkit.Migration {
                     updated_at TIMESTAMPTZ DEFAULT current_timestamp
                 )`,
 		},
+		{
+			ID:          "rolekit-004",
+			Description: "Add context and correlation columns to role_audit_log",
+			SQL: `
+                ALTER TABLE role_audit_log
+                    ADD COLUMN IF NOT EXISTS actor_roles TEXT[],
+                    ADD COLUMN IF NOT EXISTS previous_roles TEXT[],
+                    ADD COLUMN IF NOT EXISTS new_roles TEXT[],
+                    ADD COLUMN IF NOT EXISTS metadata JSONB,
+                    ADD COLUMN IF NOT EXISTS correlation_id TEXT`,
+		},
+		{
+			ID:          "rolekit-005",
+			Description: "Index role_audit_log by correlation_id",
+			SQL: `
+                CREATE INDEX IF NOT EXISTS idx_role_audit_log_correlation_id
+                    ON role_audit_log (correlation_id)`,
+		},
+		{
+			ID:          "rolekit-006",
+			Description: "Add expires_at to role_assignments",
+			SQL: `
+                ALTER TABLE role_assignments
+                    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ`,
+		},
+		{
+			ID:          "rolekit-007",
+			Description: "Create role_access_requests table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS role_access_requests (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    user_id TEXT NOT NULL,
+                    role TEXT NOT NULL,
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT NOT NULL,
+                    reason TEXT NOT NULL,
+                    duration BIGINT NOT NULL,
+                    status TEXT NOT NULL,
+                    decided_by TEXT,
+                    decision_reason TEXT,
+                    requested_at TIMESTAMPTZ DEFAULT current_timestamp,
+                    decided_at TIMESTAMPTZ,
+                    expires_at TIMESTAMPTZ
+                )`,
+		},
+		{
+			ID:          "rolekit-008",
+			Description: "Add severity to role_audit_log",
+			SQL: `
+                ALTER TABLE role_audit_log
+                    ADD COLUMN IF NOT EXISTS severity TEXT`,
+		},
+		{
+			ID:          "rolekit-009",
+			Description: "Create role_invitations table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS role_invitations (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    invitee TEXT NOT NULL,
+                    role TEXT NOT NULL,
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT NOT NULL,
+                    inviter_id TEXT NOT NULL,
+                    token_hash TEXT NOT NULL UNIQUE,
+                    status TEXT NOT NULL,
+                    accepted_by TEXT,
+                    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+                    expires_at TIMESTAMPTZ NOT NULL,
+                    accepted_at TIMESTAMPTZ
+                )`,
+		},
+		{
+			ID:          "rolekit-010",
+			Description: "Create role_delegations table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS role_delegations (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    delegator_id TEXT NOT NULL,
+                    delegate_id TEXT NOT NULL,
+                    permissions TEXT[] NOT NULL,
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT NOT NULL,
+                    allow_redelegation BOOLEAN NOT NULL DEFAULT FALSE,
+                    starts_at TIMESTAMPTZ NOT NULL,
+                    ends_at TIMESTAMPTZ NOT NULL,
+                    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+                    revoked_at TIMESTAMPTZ,
+                    revoked_by TEXT
+                )`,
+		},
+		{
+			ID:          "rolekit-011",
+			Description: "Add impersonated_user_id to role_audit_log",
+			SQL: `
+                ALTER TABLE role_audit_log
+                    ADD COLUMN IF NOT EXISTS impersonated_user_id TEXT`,
+		},
+		{
+			ID:          "rolekit-012",
+			Description: "Create role_reviews table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS role_reviews (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    name TEXT NOT NULL,
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT,
+                    roles TEXT[],
+                    created_by TEXT NOT NULL,
+                    auto_revoke BOOLEAN NOT NULL DEFAULT FALSE,
+                    status TEXT NOT NULL,
+                    deadline TIMESTAMPTZ NOT NULL,
+                    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+                    closed_at TIMESTAMPTZ
+                )`,
+		},
+		{
+			ID:          "rolekit-013",
+			Description: "Create role_review_items table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS role_review_items (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    review_id UUID NOT NULL REFERENCES role_reviews (id) ON DELETE CASCADE,
+                    assignment_id UUID NOT NULL,
+                    user_id TEXT NOT NULL,
+                    role TEXT NOT NULL,
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT NOT NULL,
+                    decision TEXT NOT NULL,
+                    reviewer_id TEXT,
+                    comment TEXT,
+                    decided_at TIMESTAMPTZ,
+                    applied_at TIMESTAMPTZ
+                )`,
+		},
+		{
+			ID:          "rolekit-014",
+			Description: "Remove duplicate scope_hierarchy rows",
+			SQL: `
+                DELETE FROM scope_hierarchy a
+                    USING scope_hierarchy b
+                    WHERE a.scope_type = b.scope_type
+                      AND a.scope_id = b.scope_id
+                      AND (a.created_at, a.id) < (b.created_at, b.id)`,
+		},
+		{
+			ID:          "rolekit-015",
+			Description: "Allow one parent per scope",
+			SQL: `
+                CREATE UNIQUE INDEX IF NOT EXISTS idx_scope_hierarchy_scope
+                    ON scope_hierarchy (scope_type, scope_id)`,
+		},
+		{
+			ID:          "rolekit-016",
+			Description: "Remove duplicate role_assignments rows, keeping the longest-lived one",
+			// b outranks a when it never expires, then when it expires later,
+			// then when it was created first
+			SQL: `
+                DELETE FROM role_assignments a
+                    USING role_assignments b
+                    WHERE a.user_id = b.user_id
+                      AND a.role = b.role
+                      AND a.scope_type = b.scope_type
+                      AND a.scope_id = b.scope_id
+                      AND (b.expires_at IS NULL, COALESCE(b.expires_at, 'infinity'), a.created_at, a.id)
+                        > (a.expires_at IS NULL, COALESCE(a.expires_at, 'infinity'), b.created_at, b.id)`,
+		},
+		{
+			ID:          "rolekit-017",
+			Description: "Make role assignments unique",
+			SQL: `
+                CREATE UNIQUE INDEX IF NOT EXISTS idx_role_assignments_unique
+                    ON role_assignments (user_id, role, scope_type, scope_id)`,
+		},
+		{
+			ID:          "rolekit-018",
+			Description: "Create archived_scopes table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS archived_scopes (
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT NOT NULL,
+                    archived_by TEXT NOT NULL,
+                    archived_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+                    PRIMARY KEY (scope_type, scope_id)
+                )`,
+		},
+		{
+			ID:          "rolekit-019",
+			Description: "Create resource_scopes table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS resource_scopes (
+                    resource_type TEXT NOT NULL,
+                    resource_id TEXT NOT NULL,
+                    scope_type TEXT NOT NULL,
+                    scope_id TEXT NOT NULL,
+                    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+                    PRIMARY KEY (resource_type, resource_id)
+                )`,
+		},
+		{
+			ID:          "rolekit-020",
+			Description: "Create resource_grants table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS resource_grants (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    resource_type TEXT NOT NULL,
+                    resource_id TEXT NOT NULL,
+                    principal_id TEXT NOT NULL,
+                    permissions TEXT[] NOT NULL,
+                    granted_by TEXT NOT NULL,
+                    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+                    UNIQUE (resource_type, resource_id, principal_id)
+                )`,
+		},
+		{
+			ID:          "rolekit-021",
+			Description: "Index resource_grants by principal_id",
+			SQL: `
+                CREATE INDEX IF NOT EXISTS idx_resource_grants_principal_id
+                    ON resource_grants (principal_id)`,
+		},
+		{
+			ID:          "rolekit-022",
+			Description: "Create relation_tuples table",
+			SQL: `
+                CREATE TABLE IF NOT EXISTS relation_tuples (
+                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                    namespace TEXT NOT NULL,
+                    object_id TEXT NOT NULL,
+                    relation TEXT NOT NULL,
+                    subject_namespace TEXT NOT NULL,
+                    subject_id TEXT NOT NULL,
+                    subject_relation TEXT NOT NULL DEFAULT '',
+                    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+                    UNIQUE (namespace, object_id, relation, subject_namespace, subject_id, subject_relation)
+                )`,
+		},
 	}
 }
@@ -0,0 +1,230 @@
+package rolekit
+
+import (
+	"context"
+	"time"
+
+	"github.com/fernandezvara/dbkit"
+)
+
+// ============================================================================
+// USER OFFBOARDING
+// ============================================================================
+
+// OffboardOption configures a user offboarding.
+type OffboardOption func(*offboardOptions)
+
+type offboardOptions struct {
+	reassignTo       string
+	subjectNamespace string
+}
+
+// DefaultSubjectNamespace is the relation-tuple namespace OffboardUser looks
+// up the user in (as in "group:eng#member@user:alice").
+const DefaultSubjectNamespace = "user"
+
+// ReassignTo hands roles the leaving user cannot give up (such as the last
+// owner of an organization) to another user instead of failing.
+//
+// Example:
+//
+//	report, err := service.OffboardUser(ctx, leaverID, rolekit.ReassignTo(managerID))
+func ReassignTo(userID string) OffboardOption {
+	return func(o *offboardOptions) {
+		o.reassignTo = userID
+	}
+}
+
+// SubjectNamespace sets the relation-tuple namespace users are subjects in,
+// when it is not DefaultSubjectNamespace.
+//
+// Example:
+//
+//	report, err := service.OffboardUser(ctx, leaverID, rolekit.SubjectNamespace("employee"))
+func SubjectNamespace(namespace string) OffboardOption {
+	return func(o *offboardOptions) {
+		o.subjectNamespace = namespace
+	}
+}
+
+// OffboardReport describes what OffboardUser removed.
+type OffboardReport struct {
+	UserID             string
+	CorrelationID      string           // Shared by the audit entries of the offboarding
+	Revoked            []RoleAssignment // Assignments removed from the user
+	Reassigned         []RoleAssignment // Assignments created for the ReassignTo user
+	InvitationsRevoked int              // Pending invitations sent by the user
+	DelegationsRevoked int              // Active delegations from or to the user
+	GrantsRevoked      int              // Resource grants to the user
+	TuplesDeleted      int              // Relation tuples with the user as subject
+}
+
+// OffboardUser removes a user from every scope in one transaction: all role
+// assignments (expired ones included), the pending invitations they sent, the
+// delegations they gave or received, their resource grants and the relation
+// tuples with the user as subject (see SubjectNamespace). Revocations are
+// checked like Revoke, so the actor in the context must be able to revoke each
+// role and member minimums hold; roles the actor holds in archived scopes count
+// as well, since the user's assignments there are removed too. A role the user
+// cannot give up is handed to the ReassignTo user first, or the whole
+// offboarding fails. Each removed assignment gets its own audit entry, sharing
+// the report's correlation ID.
+//
+// Example:
+//
+//	report, err := service.OffboardUser(ctx, leaverID, rolekit.ReassignTo(managerID))
+//	log.Printf("revoked %d assignments, reassigned %d", len(report.Revoked), len(report.Reassigned))
+func (s *Service) OffboardUser(ctx context.Context, userID string, opts ...OffboardOption) (*OffboardReport, error) {
+	options := offboardOptions{subjectNamespace: DefaultSubjectNamespace}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return nil, NewError(ErrNoActorID, "actor ID required to offboard a user")
+	}
+	if userID == "" {
+		return nil, NewError(ErrNoUserID, "user ID required to offboard a user")
+	}
+	if options.reassignTo == userID {
+		return nil, NewError(ErrCannotAssign, "cannot reassign roles to the user being offboarded").
+			WithUser(userID)
+	}
+
+	report := &OffboardReport{UserID: userID, CorrelationID: newCorrelationID()}
+	ctx = withCorrelationID(ctx, report.CorrelationID)
+
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		// The rows include archived scopes, so the actor's authority does too
+		actorRoles, err := s.loadUserRoles(ctx, actorID, true)
+		if err != nil {
+			return err
+		}
+		actorChecker := s.newChecker(ctx, actorID, actorRoles)
+
+		var assignments []RoleAssignment
+		err = dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&assignments).Where("user_id = ?", userID).Scan(ctx), "OffboardUser").Err()
+		if err != nil {
+			return err
+		}
+		sortAssignments(assignments)
+
+		now := time.Now()
+		for _, a := range assignments {
+			if a.IsExpired(now) {
+				if err := s.removeExpiredAssignment(ctx, actorID, a); err != nil {
+					return err
+				}
+				report.Revoked = append(report.Revoked, a)
+				continue
+			}
+
+			err := s.offboardAssignment(ctx, actorChecker, a)
+			if IsCardinalityViolation(err) && options.reassignTo != "" {
+				if err := s.assign(ctx, options.reassignTo, a.Role, a.ScopeType, a.ScopeID); err != nil {
+					return err
+				}
+				report.Reassigned = append(report.Reassigned, RoleAssignment{
+					UserID: options.reassignTo, Role: a.Role, ScopeType: a.ScopeType, ScopeID: a.ScopeID,
+				})
+				err = s.offboardAssignment(ctx, actorChecker, a)
+			}
+			if err != nil {
+				return err
+			}
+			report.Revoked = append(report.Revoked, a)
+		}
+
+		result, err := s.conn(ctx).NewUpdate().Model((*Invitation)(nil)).
+			Set("status = ?", InvitationRevoked).
+			Where("inviter_id = ? AND status = ?", userID, InvitationPending).
+			Exec(ctx)
+		if err = dbkit.WithErr(result, err, "OffboardInvitations").Err(); err != nil {
+			return err
+		}
+		if rows, err := result.RowsAffected(); err == nil {
+			report.InvitationsRevoked = int(rows)
+		}
+
+		result, err = s.conn(ctx).NewUpdate().Model((*Delegation)(nil)).
+			Set("revoked_at = ?", now).
+			Set("revoked_by = ?", actorID).
+			Where("(delegator_id = ? OR delegate_id = ?) AND revoked_at IS NULL", userID, userID).
+			Exec(ctx)
+		if err = dbkit.WithErr(result, err, "OffboardDelegations").Err(); err != nil {
+			return err
+		}
+		if rows, err := result.RowsAffected(); err == nil {
+			report.DelegationsRevoked = int(rows)
+		}
+
+		var grants []ResourceGrant
+		err = dbkit.WithErr1(s.conn(ctx).NewRaw("DELETE FROM resource_grants WHERE principal_id = ? RETURNING *", userID).Scan(ctx, &grants), "OffboardResourceGrants").Err()
+		if err != nil && !dbkit.IsNotFound(err) {
+			return err
+		}
+		for i := range grants {
+			if err := s.logAudit(ctx, s.resourceGrantAudit(ctx, AuditActionResourceRevoked, &grants[i], actorID)); err != nil {
+				return err
+			}
+		}
+		report.GrantsRevoked = len(grants)
+
+		result, err = s.conn(ctx).NewDelete().Model((*TupleRecord)(nil)).
+			Where("subject_namespace = ? AND subject_id = ? AND subject_relation = ''", options.subjectNamespace, userID).
+			Exec(ctx)
+		if err = dbkit.WithErr(result, err, "OffboardTuples").Err(); err != nil {
+			return err
+		}
+		if rows, err := result.RowsAffected(); err == nil {
+			report.TuplesDeleted = int(rows)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// offboardAssignment revokes an active assignment of the offboarded user like
+// Revoke, authorizing the actor with roles held in archived scopes too.
+func (s *Service) offboardAssignment(ctx context.Context, actorChecker *Checker, a RoleAssignment) error {
+	if actorChecker.userID != a.UserID {
+		canAssign, err := actorChecker.CanAssignRoleContext(ctx, a.Role, a.ScopeType, a.ScopeID)
+		if err != nil {
+			return err
+		}
+		if !canAssign {
+			return NewError(ErrCannotAssign, "actor cannot revoke this role").
+				WithScope(a.ScopeType, a.ScopeID).
+				WithRole(a.Role).
+				WithActor(actorChecker.userID)
+		}
+	}
+	return s.removeRole(ctx, actorChecker.userID, actorChecker.roles, a.UserID, a.Role, a.ScopeType, a.ScopeID)
+}
+
+// removeExpiredAssignment deletes an assignment that no longer grants anything
+// and records it as expired.
+func (s *Service) removeExpiredAssignment(ctx context.Context, actorID string, a RoleAssignment) error {
+	if err := s.deleteAssignment(ctx, a.UserID, a.Role, a.ScopeType, a.ScopeID); err != nil {
+		return err
+	}
+
+	audit := GetAuditContext(ctx)
+	return s.logAudit(ctx, &AuditEntry{
+		ActorID:       actorID,
+		Action:        AuditActionExpired,
+		TargetUserID:  a.UserID,
+		Role:          a.Role,
+		ScopeType:     a.ScopeType,
+		ScopeID:       a.ScopeID,
+		PreviousRoles: []string{a.Role},
+		IPAddress:     audit.IPAddress,
+		UserAgent:     audit.UserAgent,
+		RequestID:     audit.RequestID,
+		Metadata:      map[string]any{"expires_at": a.ExpiresAt},
+	})
+}
@@ -0,0 +1,109 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceOffboardUserValidation tests argument validation before any database access
+func TestServiceOffboardUserValidation(t *testing.T) {
+	service := &Service{registry: NewRegistry()}
+	ctx := WithActorID(context.Background(), "hr1")
+
+	t.Run("No actor", func(t *testing.T) {
+		_, err := service.OffboardUser(context.Background(), "user1")
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("No user", func(t *testing.T) {
+		_, err := service.OffboardUser(ctx, "")
+		assert.ErrorIs(t, err, ErrNoUserID)
+	})
+
+	t.Run("Reassign to the leaving user", func(t *testing.T) {
+		_, err := service.OffboardUser(ctx, "user1", ReassignTo("user1"))
+		assert.True(t, IsCannotAssign(err))
+	})
+}
+
+// TestServiceOffboardUserDatabase tests removing a user from every scope with real database
+func TestServiceOffboardUserDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	service.Registry().GetRole("admin", "organization").MinMembers(1)
+	service.Registry().DefineNamespace("group").Relation("member")
+
+	orgID := helper.CreateTestOrg("org")
+	otherOrgID := helper.CreateTestOrg("other")
+	hrID := helper.CreateTestUser("hr")
+	leaverID := helper.CreateTestUser("leaver")
+	managerID := helper.CreateTestUser("manager")
+	require.NoError(t, helper.SetupAdminUser(hrID, orgID))
+	require.NoError(t, helper.SetupAdminUser(hrID, otherOrgID))
+
+	ctx := WithActorID(helper.GetContext(), hrID)
+	require.NoError(t, service.Assign(ctx, leaverID, "admin", "organization", orgID))
+	require.NoError(t, service.Assign(ctx, leaverID, "developer", "organization", otherOrgID))
+
+	leaverCtx := WithActorID(helper.GetContext(), leaverID)
+	_, _, err := service.CreateInvitation(leaverCtx, "new@example.com", "project_manager", "organization", orgID, DefaultInvitationTTL)
+	require.NoError(t, err)
+	groupID := helper.CreateTestOrg("group")
+	require.NoError(t, service.WriteTuples(ctx,
+		MustParseTuple("group:"+groupID+"#member@user:"+leaverID),
+		MustParseTuple("group:"+groupID+"#member@user:"+managerID)))
+
+	t.Run("Last admin without reassignment", func(t *testing.T) {
+		_, err := service.OffboardUser(ctx, leaverID)
+		assert.True(t, IsCardinalityViolation(err))
+		helper.AssertRoleAssigned(leaverID, "developer", "organization", otherOrgID)
+	})
+
+	t.Run("With reassignment", func(t *testing.T) {
+		report, err := service.OffboardUser(ctx, leaverID, ReassignTo(managerID))
+		require.NoError(t, err)
+		assert.Len(t, report.Revoked, 2)
+		require.Len(t, report.Reassigned, 1)
+		assert.Equal(t, "admin", report.Reassigned[0].Role)
+		assert.Equal(t, 1, report.InvitationsRevoked)
+		assert.Equal(t, 1, report.TuplesDeleted)
+
+		members, err := service.ReadTuples(ctx, NewObjectRef("group", groupID), "member")
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, managerID, members[0].Subject.ID)
+
+		helper.AssertRoleNotAssigned(leaverID, "admin", "organization", orgID)
+		helper.AssertRoleNotAssigned(leaverID, "developer", "organization", otherOrgID)
+		helper.AssertRoleAssigned(managerID, "admin", "organization", orgID)
+
+		logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID(report.CorrelationID).WithAction(AuditActionRevoked))
+		require.NoError(t, err)
+		assert.Len(t, logs, 2)
+	})
+
+	t.Run("Archived scope", func(t *testing.T) {
+		archivedID := helper.CreateTestOrg("archived")
+		archivedLeaverID := helper.CreateTestUser("archived-leaver")
+		require.NoError(t, helper.SetupAdminUser(hrID, archivedID))
+		require.NoError(t, service.Assign(ctx, archivedLeaverID, "developer", "organization", archivedID))
+		require.NoError(t, service.ArchiveScope(ctx, "organization", archivedID))
+
+		// The actor's authority comes from a role in the archived scope
+		report, err := service.OffboardUser(ctx, archivedLeaverID)
+		require.NoError(t, err)
+		assert.Len(t, report.Revoked, 1)
+
+		count, err := service.conn(ctx).NewSelect().Model((*RoleAssignment)(nil)).Where("user_id = ?", archivedLeaverID).Count(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
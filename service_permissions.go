@@ -29,11 +29,10 @@ func (s *Service) Can(ctx context.Context, userID, role, scopeType, scopeID stri
 //	    // User can upload files
 //	}
 func (s *Service) HasPermission(ctx context.Context, userID, permission, scopeType, scopeID string) bool {
-	roles, err := s.GetUserRoles(ctx, userID)
+	checker, err := s.GetChecker(ctx, userID)
 	if err != nil {
 		return false
 	}
-	checker := NewChecker(userID, roles, s.registry, s)
 	return checker.HasPermission(permission, scopeType, scopeID)
 }
 
@@ -43,7 +42,7 @@ func (s *Service) HasAnyRole(ctx context.Context, userID string, roles []string,
 	if err != nil {
 		return false
 	}
-	checker := NewChecker(userID, userRoles, s.registry, s)
+	checker := s.newChecker(ctx, userID, userRoles)
 	return checker.HasAnyRole(roles, scopeType, scopeID)
 }
 
@@ -53,6 +52,7 @@ func (s *Service) CanAssignRole(ctx context.Context, userID, targetRole, scopeTy
 	if err != nil {
 		return false
 	}
-	checker := NewChecker(userID, roles, s.registry, s)
-	return checker.CanAssignRole(targetRole, scopeType, scopeID)
+	checker := s.newChecker(ctx, userID, roles)
+	canAssign, err := checker.CanAssignRoleContext(ctx, targetRole, scopeType, scopeID)
+	return err == nil && canAssign
 }
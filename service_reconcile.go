@@ -0,0 +1,178 @@
+package rolekit
+
+import (
+	"context"
+	"sort"
+)
+
+// ============================================================================
+// RECONCILIATION (DESIRED-STATE SYNC)
+// ============================================================================
+
+// ReconcilePlan lists the changes that bring the assignments selected by a
+// filter to the desired state.
+type ReconcilePlan struct {
+	SyncID  string           // Correlation ID of the audit entries written when applied
+	Add     []RoleAssignment // Desired assignments that do not exist yet
+	Remove  []RoleAssignment // Existing assignments that are not desired
+	Applied bool             // False for dry runs
+}
+
+// IsEmpty reports whether the current state already matches the desired state.
+func (p *ReconcilePlan) IsEmpty() bool {
+	return len(p.Add) == 0 && len(p.Remove) == 0
+}
+
+// ReconcileOption configures a reconciliation.
+type ReconcileOption func(*reconcileOptions)
+
+type reconcileOptions struct {
+	dryRun bool
+	syncID string
+}
+
+// DryRun computes the plan without changing anything.
+func DryRun() ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithSyncID sets the ID used to tag the audit entries (default: a new random ID),
+// e.g. the Git commit the desired state was read from.
+func WithSyncID(syncID string) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.syncID = syncID
+	}
+}
+
+// Reconcile converges the assignments selected by the filter to the desired
+// list: missing assignments are added with Assign and extra ones removed with
+// Revoke, as the actor in the context and in a single transaction. Additions
+// run before removals so a role with a minimum member count can change hands.
+// Assignments outside the filter are never touched, and desired assignments
+// outside it are rejected. Every audit entry is tagged with the plan's SyncID.
+//
+// Example:
+//
+//	desired := loadMembershipFromGit()
+//	filter := rolekit.AssignmentFilter{ScopeType: "team"}
+//
+//	plan, err := service.Reconcile(ctx, desired, filter, rolekit.DryRun())
+//	log.Printf("would add %d, remove %d", len(plan.Add), len(plan.Remove))
+//
+//	plan, err = service.Reconcile(ctx, desired, filter, rolekit.WithSyncID(commitSHA))
+func (s *Service) Reconcile(ctx context.Context, desired []RoleAssignment, filter AssignmentFilter, opts ...ReconcileOption) (*ReconcilePlan, error) {
+	options := reconcileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.syncID == "" {
+		options.syncID = newCorrelationID()
+	}
+
+	if err := filter.validate(s.registry); err != nil {
+		return nil, err
+	}
+	for _, a := range desired {
+		if !filter.Matches(a) {
+			return nil, NewError(ErrInvalidScope, "desired assignment is outside the reconcile filter").
+				WithScope(a.ScopeType, a.ScopeID).
+				WithRole(a.Role).
+				WithUser(a.UserID)
+		}
+		if err := s.registry.ValidateRole(a.Role, a.ScopeType); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &ReconcilePlan{SyncID: options.syncID}
+
+	if options.dryRun {
+		current, err := s.findAssignments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		plan.Add, plan.Remove = diffAssignments(current, desired)
+		return plan, nil
+	}
+
+	if GetActorID(ctx) == "" {
+		return nil, NewError(ErrNoActorID, "actor ID required for reconciliation")
+	}
+
+	err := s.Transaction(withCorrelationID(ctx, plan.SyncID), func(ctx context.Context) error {
+		current, err := s.findAssignments(ctx, filter)
+		if err != nil {
+			return err
+		}
+		plan.Add, plan.Remove = diffAssignments(current, desired)
+
+		for _, a := range plan.Add {
+			if err := s.Assign(ctx, a.UserID, a.Role, a.ScopeType, a.ScopeID); err != nil {
+				return err
+			}
+		}
+		for _, a := range plan.Remove {
+			if err := s.Revoke(ctx, a.UserID, a.Role, a.ScopeType, a.ScopeID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Applied = true
+	return plan, nil
+}
+
+// diffAssignments returns the desired assignments missing from current and the
+// current assignments not desired, both in a stable order.
+func diffAssignments(current, desired []RoleAssignment) (add, remove []RoleAssignment) {
+	key := func(a RoleAssignment) string {
+		return a.UserID + "\x00" + a.Role + "\x00" + a.ScopeType + "\x00" + a.ScopeID
+	}
+
+	existing := make(map[string]bool, len(current))
+	for _, a := range current {
+		existing[key(a)] = true
+	}
+	wanted := make(map[string]bool, len(desired))
+	for _, a := range desired {
+		k := key(a)
+		if !existing[k] && !wanted[k] {
+			add = append(add, RoleAssignment{UserID: a.UserID, Role: a.Role, ScopeType: a.ScopeType, ScopeID: a.ScopeID})
+		}
+		wanted[k] = true
+	}
+	for _, a := range current {
+		k := key(a)
+		if !wanted[k] {
+			remove = append(remove, a)
+			wanted[k] = true // Report duplicate rows once
+		}
+	}
+
+	sortAssignments(add)
+	sortAssignments(remove)
+	return add, remove
+}
+
+// sortAssignments orders assignments by scope, role and user.
+func sortAssignments(assignments []RoleAssignment) {
+	sort.Slice(assignments, func(i, j int) bool {
+		a, b := assignments[i], assignments[j]
+		if a.ScopeType != b.ScopeType {
+			return a.ScopeType < b.ScopeType
+		}
+		if a.ScopeID != b.ScopeID {
+			return a.ScopeID < b.ScopeID
+		}
+		if a.Role != b.Role {
+			return a.Role < b.Role
+		}
+		return a.UserID < b.UserID
+	})
+}
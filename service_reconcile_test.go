@@ -0,0 +1,119 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDiffAssignments tests computing a reconcile plan
+func TestDiffAssignments(t *testing.T) {
+	current := []RoleAssignment{
+		{ID: "1", UserID: "alice", Role: "member", ScopeType: "team", ScopeID: "team1"},
+		{ID: "2", UserID: "bob", Role: "member", ScopeType: "team", ScopeID: "team1"},
+		{ID: "3", UserID: "bob", Role: "member", ScopeType: "team", ScopeID: "team1"}, // duplicate row
+	}
+	desired := []RoleAssignment{
+		{UserID: "alice", Role: "member", ScopeType: "team", ScopeID: "team1"},
+		{UserID: "carol", Role: "member", ScopeType: "team", ScopeID: "team1"},
+		{UserID: "carol", Role: "member", ScopeType: "team", ScopeID: "team1"},
+		{UserID: "alice", Role: "lead", ScopeType: "team", ScopeID: "team1"},
+	}
+
+	add, remove := diffAssignments(current, desired)
+
+	require.Len(t, add, 2)
+	assert.Equal(t, "alice", add[0].UserID)
+	assert.Equal(t, "lead", add[0].Role)
+	assert.Equal(t, "carol", add[1].UserID)
+
+	require.Len(t, remove, 1)
+	assert.Equal(t, "bob", remove[0].UserID)
+
+	add, remove = diffAssignments(current[:1], desired[:1])
+	assert.Empty(t, add)
+	assert.Empty(t, remove)
+}
+
+// TestServiceReconcileValidation tests argument validation before any database access
+func TestServiceReconcileValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("team").
+		Role("lead").Permissions("*").CanAssign("*").
+		Role("member").Permissions("*.read")
+	registry.DefineScope("project").
+		Role("viewer").Permissions("*.read")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "sync-bot")
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		_, err := service.Reconcile(ctx, nil, AssignmentFilter{ScopeType: "unknown"})
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("Desired assignment outside filter", func(t *testing.T) {
+		desired := []RoleAssignment{{UserID: "alice", Role: "viewer", ScopeType: "project", ScopeID: "proj1"}}
+		_, err := service.Reconcile(ctx, desired, AssignmentFilter{ScopeType: "team"})
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("Invalid desired role", func(t *testing.T) {
+		desired := []RoleAssignment{{UserID: "alice", Role: "unknown", ScopeType: "team", ScopeID: "team1"}}
+		_, err := service.Reconcile(ctx, desired, AssignmentFilter{ScopeType: "team"})
+		assert.True(t, IsInvalidRole(err))
+	})
+}
+
+// TestServiceReconcileDatabase tests dry-run and applied reconciliation with real database
+func TestServiceReconcileDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	adminID := helper.CreateTestUser("admin")
+	stayingID := helper.CreateTestUser("staying")
+	leavingID := helper.CreateTestUser("leaving")
+	joiningID := helper.CreateTestUser("joining")
+	if err := helper.SetupAdminUser(adminID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), adminID)
+	require.NoError(t, service.Assign(ctx, stayingID, "developer", "organization", orgID))
+	require.NoError(t, service.Assign(ctx, leavingID, "developer", "organization", orgID))
+
+	filter := AssignmentFilter{ScopeType: "organization", ScopeID: orgID, Roles: []string{"developer"}}
+	desired := []RoleAssignment{
+		{UserID: stayingID, Role: "developer", ScopeType: "organization", ScopeID: orgID},
+		{UserID: joiningID, Role: "developer", ScopeType: "organization", ScopeID: orgID},
+	}
+
+	plan, err := service.Reconcile(ctx, desired, filter, DryRun())
+	require.NoError(t, err)
+	assert.False(t, plan.Applied)
+	require.Len(t, plan.Add, 1)
+	require.Len(t, plan.Remove, 1)
+	helper.AssertRoleAssigned(leavingID, "developer", "organization", orgID)
+
+	plan, err = service.Reconcile(ctx, desired, filter, WithSyncID("sync-"+orgID))
+	require.NoError(t, err)
+	assert.True(t, plan.Applied)
+	helper.AssertRoleAssigned(joiningID, "developer", "organization", orgID)
+	helper.AssertRoleNotAssigned(leavingID, "developer", "organization", orgID)
+
+	// Assignments outside the filter are untouched
+	helper.AssertRoleAssigned(adminID, "super_admin", "organization", orgID)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID("sync-"+orgID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	plan, err = service.Reconcile(ctx, desired, filter)
+	require.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+}
@@ -0,0 +1,133 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckerCanOnResourceWithoutDatabase tests that resource checks fail closed without a database
+func TestCheckerCanOnResourceWithoutDatabase(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("project").
+		Role("owner").Permissions("*")
+	roles := NewUserRoles("user1", []RoleAssignment{
+		{UserID: "user1", Role: "owner", ScopeType: "project", ScopeID: "proj1"},
+	})
+
+	checker := NewChecker("user1", roles, registry, nil)
+	ok, err := checker.CanOnResource(context.Background(), "documents.read", "document", "doc1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	checker = NewChecker("user1", roles, registry, &Service{registry: registry})
+	ok, err = checker.CanOnResource(context.Background(), "documents.read", "document", "doc1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// TestServiceResourceGrantValidation tests argument validation before any database access
+func TestServiceResourceGrantValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("project").
+		Role("owner").Permissions("*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "owner1")
+
+	t.Run("Invalid containing scope", func(t *testing.T) {
+		err := service.SetResourceScope(ctx, "document", "doc1", "unknown", "x")
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		err := service.SetResourceScope(context.Background(), "document", "doc1", "project", "proj1")
+		assert.ErrorIs(t, err, ErrNoActorID)
+
+		_, err = service.GrantResource(context.Background(), "user1", "document", "doc1", "documents.read")
+		assert.ErrorIs(t, err, ErrNoActorID)
+
+		err = service.RevokeResourceGrant(context.Background(), "user1", "document", "doc1")
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("No principal", func(t *testing.T) {
+		_, err := service.GrantResource(ctx, "", "document", "doc1", "documents.read")
+		assert.ErrorIs(t, err, ErrNoUserID)
+	})
+
+	t.Run("No permissions", func(t *testing.T) {
+		_, err := service.GrantResource(ctx, "user1", "document", "doc1")
+		assert.ErrorIs(t, err, ErrInvalidPermission)
+	})
+
+	t.Run("Invalid permission", func(t *testing.T) {
+		_, err := service.GrantResource(ctx, "user1", "document", "doc1", "")
+		assert.Error(t, err)
+	})
+}
+
+// TestServiceResourceGrantsDatabase tests resource grants combined with scope roles with real database
+func TestServiceResourceGrantsDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	docID := helper.CreateTestUser("doc")
+	adminID := helper.CreateTestUser("admin")
+	viewerID := helper.CreateTestUser("viewer")
+	externalID := helper.CreateTestUser("external")
+	require.NoError(t, helper.SetupAdminUser(adminID, orgID))
+	require.NoError(t, helper.SetupViewer(viewerID, orgID))
+
+	ctx := WithActorID(helper.GetContext(), adminID)
+	require.NoError(t, service.SetResourceScope(ctx, "document", docID, "organization", orgID))
+
+	// Moving a resource requires managing its current scope
+	err := service.SetResourceScope(WithActorID(helper.GetContext(), viewerID), "document", docID, "organization", helper.CreateTestOrg("other"))
+	assert.True(t, IsCannotAssign(err))
+
+	checkerFor := func(userID string) *Checker {
+		checker, err := service.GetChecker(ctx, userID)
+		require.NoError(t, err)
+		return checker
+	}
+	canOnDocument := func(userID, permission string) bool {
+		ok, err := checkerFor(userID).CanOnResource(ctx, permission, "document", docID)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// Roles in the containing scope apply to the resource
+	assert.True(t, canOnDocument(viewerID, "task.read"))
+	assert.False(t, canOnDocument(externalID, "task.read"))
+
+	_, err = service.GrantResource(ctx, externalID, "document", docID, "documents.read")
+	require.NoError(t, err)
+	assert.True(t, canOnDocument(externalID, "documents.read"))
+	assert.False(t, canOnDocument(externalID, "documents.write"))
+
+	// The grant does not extend to the rest of the scope
+	assert.False(t, checkerFor(externalID).HasPermission("documents.read", "organization", orgID))
+
+	// Grantees cannot share more than they hold
+	_, err = service.GrantResource(WithActorID(helper.GetContext(), externalID), viewerID, "document", docID, "documents.write")
+	assert.True(t, IsUnauthorized(err))
+
+	grants, err := service.ListResourceGrants(ctx, "document", docID)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.Equal(t, adminID, grants[0].GrantedBy)
+
+	// Grantees can give up their access
+	require.NoError(t, service.RevokeResourceGrant(WithActorID(helper.GetContext(), externalID), externalID, "document", docID))
+	assert.False(t, canOnDocument(externalID, "documents.read"))
+
+	err = service.RevokeResourceGrant(ctx, externalID, "document", docID)
+	assert.ErrorIs(t, err, ErrResourceGrantNotFound)
+}
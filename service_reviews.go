@@ -0,0 +1,406 @@
+package rolekit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fernandezvara/dbkit"
+)
+
+// ============================================================================
+// ACCESS REVIEWS
+// ============================================================================
+
+// ReviewFilter selects the assignments an access review covers: ScopeType is
+// required, ScopeID and Roles optionally narrow it to a scope instance and to
+// some roles.
+type ReviewFilter = AssignmentFilter
+
+// ReviewOption configures an access review.
+type ReviewOption func(*AccessReview)
+
+// AutoRevokeAtDeadline revokes the items nobody reviewed once the deadline
+// has passed. The creator must be able to assign every role in the review when
+// creating it; the revocations then run under the review's authority, so they
+// still happen if the creator loses their roles before the deadline.
+func AutoRevokeAtDeadline() ReviewOption {
+	return func(r *AccessReview) {
+		r.AutoRevoke = true
+	}
+}
+
+// CreateReview starts an access review campaign: the current assignments
+// matching the filter are snapshotted into review items that reviewers mark
+// keep or revoke until the deadline. The creator is the actor in the context.
+//
+// Example:
+//
+//	review, err := service.CreateReview(ctx, "Q3 admin recertification",
+//	    rolekit.ReviewFilter{ScopeType: "organization", Roles: []string{"admin", "owner"}},
+//	    time.Now().Add(14*24*time.Hour), rolekit.AutoRevokeAtDeadline())
+func (s *Service) CreateReview(ctx context.Context, name string, filter ReviewFilter, deadline time.Time, opts ...ReviewOption) (*AccessReview, error) {
+	if err := filter.validate(s.registry); err != nil {
+		return nil, err
+	}
+
+	creatorID := GetActorID(ctx)
+	if creatorID == "" {
+		return nil, NewError(ErrNoActorID, "actor ID required for access review")
+	}
+	if name == "" {
+		return nil, NewError(ErrInvalidReview, "review name is required").WithActor(creatorID)
+	}
+	if !deadline.After(time.Now()) {
+		return nil, NewError(ErrInvalidReview, "review deadline must be in the future").WithActor(creatorID)
+	}
+
+	review := &AccessReview{
+		Name:      name,
+		ScopeType: filter.ScopeType,
+		ScopeID:   filter.ScopeID,
+		Roles:     filter.Roles,
+		CreatedBy: creatorID,
+		Status:    AccessReviewOpen,
+		Deadline:  deadline,
+	}
+	for _, opt := range opts {
+		opt(review)
+	}
+
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		result, err := s.conn(ctx).NewInsert().Model(review).Returning("*").Exec(ctx)
+		if err = dbkit.WithErr(result, err, "CreateReview").Err(); err != nil {
+			return err
+		}
+
+		assignments, err := s.findAssignments(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+		if review.AutoRevoke {
+			if err := s.authorizeAutoRevoke(ctx, creatorID, assignments); err != nil {
+				return err
+			}
+		}
+
+		items := make([]AccessReviewItem, 0, len(assignments))
+		for _, a := range assignments {
+			items = append(items, AccessReviewItem{
+				ReviewID:     review.ID,
+				AssignmentID: a.ID,
+				UserID:       a.UserID,
+				Role:         a.Role,
+				ScopeType:    a.ScopeType,
+				ScopeID:      a.ScopeID,
+				Decision:     ReviewPending,
+			})
+		}
+		result, err = s.conn(ctx).NewInsert().Model(&items).Exec(ctx)
+		return dbkit.WithErr(result, err, "CreateReviewItems").Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetReview retrieves an access review by ID.
+func (s *Service) GetReview(ctx context.Context, reviewID string) (*AccessReview, error) {
+	review := new(AccessReview)
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(review).Where("id = ?", reviewID).Scan(ctx), "GetReview").Err()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, NewError(ErrReviewNotFound, "access review "+reviewID+" not found")
+		}
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListReviewItems returns the items of an access review.
+//
+// Example:
+//
+//	items, err := service.ListReviewItems(ctx, reviewID)
+//	for _, item := range items {
+//	    fmt.Println(item.UserID, item.Role, item.Decision)
+//	}
+func (s *Service) ListReviewItems(ctx context.Context, reviewID string) ([]AccessReviewItem, error) {
+	var items []AccessReviewItem
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&items).
+		Where("review_id = ?", reviewID).
+		Order("scope_type", "scope_id", "role", "user_id").
+		Scan(ctx), "ListReviewItems").Err()
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// DecideReviewItem records a reviewer's keep or revoke decision. The reviewer
+// is the actor in the context and must be able to assign the item's role;
+// users cannot review their own assignments. Decisions can be changed until
+// they are applied.
+//
+// Example:
+//
+//	err := service.DecideReviewItem(ctx, itemID, rolekit.ReviewRevoke, "left the team")
+func (s *Service) DecideReviewItem(ctx context.Context, itemID string, decision ReviewDecision, comment string) error {
+	if decision != ReviewKeep && decision != ReviewRevoke {
+		return NewError(ErrInvalidReview, "decision must be keep or revoke")
+	}
+
+	reviewerID := GetActorID(ctx)
+	if reviewerID == "" {
+		return NewError(ErrNoActorID, "actor ID required to review access")
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		item := new(AccessReviewItem)
+		err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(item).Where("id = ?", itemID).For("UPDATE").Scan(ctx), "DecideReviewItem").Err()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewError(ErrReviewNotFound, "review item "+itemID+" not found").WithActor(reviewerID)
+			}
+			return err
+		}
+
+		review, err := s.GetReview(ctx, item.ReviewID)
+		if err != nil {
+			return err
+		}
+		if review.Status != AccessReviewOpen || item.AppliedAt != nil {
+			return NewError(ErrInvalidReview, "review item can no longer be changed").
+				WithScope(item.ScopeType, item.ScopeID).
+				WithRole(item.Role).
+				WithActor(reviewerID)
+		}
+
+		if item.UserID == reviewerID {
+			return NewError(ErrCannotAssign, "users cannot review their own access").
+				WithScope(item.ScopeType, item.ScopeID).
+				WithRole(item.Role).
+				WithActor(reviewerID)
+		}
+		reviewerRoles, err := s.GetUserRoles(ctx, reviewerID)
+		if err != nil {
+			return err
+		}
+		canAssign, err := s.newChecker(ctx, reviewerID, reviewerRoles).CanAssignRoleContext(ctx, item.Role, item.ScopeType, item.ScopeID)
+		if err != nil {
+			return err
+		}
+		if !canAssign {
+			return NewError(ErrCannotAssign, "actor cannot review this role").
+				WithScope(item.ScopeType, item.ScopeID).
+				WithRole(item.Role).
+				WithActor(reviewerID)
+		}
+
+		now := time.Now()
+		item.Decision = decision
+		item.ReviewerID = reviewerID
+		item.Comment = comment
+		item.DecidedAt = &now
+		result, err := s.conn(ctx).NewUpdate().Model(item).
+			Column("decision", "reviewer_id", "comment", "decided_at").
+			WherePK().
+			Exec(ctx)
+		return dbkit.WithErr(result, err, "DecideReviewItem").Err()
+	})
+}
+
+// authorizeAutoRevoke verifies that the creator of an auto-revoking review can
+// assign (and thus revoke) every role in it.
+func (s *Service) authorizeAutoRevoke(ctx context.Context, creatorID string, assignments []RoleAssignment) error {
+	creatorRoles, err := s.GetUserRoles(ctx, creatorID)
+	if err != nil {
+		return err
+	}
+	checker := s.newChecker(ctx, creatorID, creatorRoles)
+	for _, a := range assignments {
+		canAssign, err := checker.CanAssignRoleContext(ctx, a.Role, a.ScopeType, a.ScopeID)
+		if err != nil {
+			return err
+		}
+		if !canAssign {
+			return NewError(ErrCannotAssign, "actor cannot auto-revoke this role").
+				WithScope(a.ScopeType, a.ScopeID).
+				WithRole(a.Role).
+				WithActor(creatorID)
+		}
+	}
+	return nil
+}
+
+// ReviewApplyReport summarizes an ApplyReview run.
+type ReviewApplyReport struct {
+	ReviewID string
+	Revoked  int                 // Assignments revoked in this run
+	Closed   bool                // The deadline had passed and the review was closed
+	Failed   []ReviewItemFailure // Revoke decisions that could not be carried out
+}
+
+// ReviewItemFailure is a review item whose revocation failed, for example
+// because the reviewer can no longer revoke the role or MinMembers blocks it.
+// The item stays unapplied.
+type ReviewItemFailure struct {
+	Item AccessReviewItem
+	Err  error
+}
+
+// ApplyReview carries out the revoke decisions of an access review in one
+// transaction. Decided items are revoked through Revoke, as the reviewer who
+// made each decision. Once the deadline has passed, unreviewed items are
+// revoked under the review's authority (if AutoRevokeAtDeadline was set) and
+// the review is closed. The revocations' audit entries use the review ID as
+// correlation ID. An item that cannot be revoked is reported in Failed and
+// does not stop the others or the closing of the review. It can be called
+// repeatedly until the review is closed.
+//
+// Example:
+//
+//	report, err := service.ApplyReview(ctx, reviewID)
+//	for _, failure := range report.Failed {
+//	    log.Printf("%s %s: %v", failure.Item.UserID, failure.Item.Role, failure.Err)
+//	}
+func (s *Service) ApplyReview(ctx context.Context, reviewID string) (*ReviewApplyReport, error) {
+	report := &ReviewApplyReport{ReviewID: reviewID}
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		review := new(AccessReview)
+		err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(review).Where("id = ?", reviewID).For("UPDATE").Scan(ctx), "ApplyReview").Err()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return NewError(ErrReviewNotFound, "access review "+reviewID+" not found")
+			}
+			return err
+		}
+		if review.Status != AccessReviewOpen {
+			return NewError(ErrInvalidReview, "access review is closed")
+		}
+
+		items, err := s.ListReviewItems(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		pastDeadline := !review.Deadline.After(now)
+		for i := range items {
+			item := items[i]
+			if item.AppliedAt != nil {
+				continue
+			}
+			autoRevoke := item.Decision == ReviewPending
+			if autoRevoke && (!pastDeadline || !review.AutoRevoke) {
+				continue
+			}
+			if !autoRevoke && item.Decision != ReviewRevoke {
+				continue
+			}
+
+			// Each item runs in its own savepoint so a failure only undoes that item
+			revoked := false
+			err := s.Transaction(ctx, func(ctx context.Context) error {
+				var err error
+				revoked, err = s.applyReviewItem(ctx, review, &item, autoRevoke, now)
+				return err
+			})
+			if err != nil {
+				report.Failed = append(report.Failed, ReviewItemFailure{Item: items[i], Err: err})
+				continue
+			}
+			if revoked {
+				report.Revoked++
+			}
+		}
+
+		if pastDeadline {
+			review.Status = AccessReviewClosed
+			review.ClosedAt = &now
+			result, err := s.conn(ctx).NewUpdate().Model(review).Column("status", "closed_at").WherePK().Exec(ctx)
+			if err = dbkit.WithErr(result, err, "CloseReview").Err(); err != nil {
+				return err
+			}
+			report.Closed = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// applyReviewItem revokes the assignment of a review item and marks the item
+// applied. Unreviewed items are revoked under the review's authority, with
+// the creator recorded as actor. It reports whether an assignment was removed.
+func (s *Service) applyReviewItem(ctx context.Context, review *AccessReview, item *AccessReviewItem, autoRevoke bool, now time.Time) (bool, error) {
+	var err error
+	if autoRevoke {
+		item.Decision = ReviewRevoke
+		item.ReviewerID = review.CreatedBy
+		item.Comment = "not reviewed before deadline"
+		item.DecidedAt = &now
+
+		revokeCtx := withCorrelationID(ctx, review.ID)
+		var creatorRoles *UserRoles
+		creatorRoles, err = s.GetUserRoles(revokeCtx, review.CreatedBy)
+		if err != nil {
+			return false, err
+		}
+		err = s.removeRole(revokeCtx, review.CreatedBy, creatorRoles, item.UserID, item.Role, item.ScopeType, item.ScopeID)
+	} else {
+		revokeCtx := withCorrelationID(WithActorID(ctx, item.ReviewerID), review.ID)
+		err = s.Revoke(revokeCtx, item.UserID, item.Role, item.ScopeType, item.ScopeID)
+	}
+	revoked := err == nil
+	if err != nil && !errors.Is(err, ErrRoleNotAssigned) {
+		return false, err
+	}
+
+	item.AppliedAt = &now
+	result, err := s.conn(ctx).NewUpdate().Model(item).
+		Column("decision", "reviewer_id", "comment", "decided_at", "applied_at").
+		WherePK().
+		Exec(ctx)
+	if err = dbkit.WithErr(result, err, "ApplyReview").Err(); err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// ProcessReviewDeadlines applies and closes every open review whose deadline
+// has passed. Run it periodically (e.g. daily) when using AutoRevokeAtDeadline.
+// A review that cannot be applied does not stop the others; its error is
+// joined into the returned error.
+//
+// Example:
+//
+//	reports, err := service.ProcessReviewDeadlines(ctx)
+func (s *Service) ProcessReviewDeadlines(ctx context.Context) ([]*ReviewApplyReport, error) {
+	var reviews []AccessReview
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&reviews).
+		Where("status = ? AND deadline <= current_timestamp", AccessReviewOpen).
+		Scan(ctx), "ProcessReviewDeadlines").Err()
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*ReviewApplyReport
+	var errs []error
+	for _, review := range reviews {
+		report, err := s.ApplyReview(ctx, review.ID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports, errors.Join(errs...)
+}
@@ -0,0 +1,126 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceCreateReviewValidation tests argument validation before any database access
+func TestServiceCreateReviewValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("owner").Permissions("*").CanAssign("*").
+		Role("admin").Permissions("members.*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "auditor1")
+	filter := ReviewFilter{ScopeType: "organization", Roles: []string{"admin", "owner"}}
+	deadline := time.Now().Add(24 * time.Hour)
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		_, err := service.CreateReview(ctx, "Q3", ReviewFilter{ScopeType: "unknown"}, deadline)
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		_, err := service.CreateReview(ctx, "Q3", ReviewFilter{ScopeType: "organization", Roles: []string{"unknown"}}, deadline)
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, err := service.CreateReview(context.Background(), "Q3", filter, deadline)
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+
+	t.Run("Missing name", func(t *testing.T) {
+		_, err := service.CreateReview(ctx, "", filter, deadline)
+		assert.True(t, IsInvalidReview(err))
+	})
+
+	t.Run("Deadline in the past", func(t *testing.T) {
+		_, err := service.CreateReview(ctx, "Q3", filter, time.Now().Add(-time.Hour))
+		assert.True(t, IsInvalidReview(err))
+	})
+
+	t.Run("Invalid decision", func(t *testing.T) {
+		err := service.DecideReviewItem(ctx, "item1", ReviewPending, "")
+		assert.True(t, IsInvalidReview(err))
+	})
+}
+
+// TestServiceAccessReviewDatabase tests a review campaign with real database
+func TestServiceAccessReviewDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	orgID := helper.CreateTestOrg("org")
+	ownerID := helper.CreateTestUser("owner")
+	keptID := helper.CreateTestUser("kept")
+	revokedID := helper.CreateTestUser("revoked")
+	forgottenID := helper.CreateTestUser("forgotten")
+	if err := helper.SetupAdminUser(ownerID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), ownerID)
+	for _, userID := range []string{keptID, revokedID, forgottenID} {
+		require.NoError(t, service.Assign(ctx, userID, "admin", "organization", orgID))
+	}
+
+	review, err := service.CreateReview(ctx, "Q3 admins",
+		ReviewFilter{ScopeType: "organization", ScopeID: orgID, Roles: []string{"admin"}},
+		time.Now().Add(time.Hour), AutoRevokeAtDeadline())
+	require.NoError(t, err)
+
+	items, err := service.ListReviewItems(ctx, review.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	byUser := make(map[string]AccessReviewItem)
+	for _, item := range items {
+		byUser[item.UserID] = item
+	}
+	require.NoError(t, service.DecideReviewItem(ctx, byUser[keptID].ID, ReviewKeep, "still on the team"))
+	require.NoError(t, service.DecideReviewItem(ctx, byUser[revokedID].ID, ReviewRevoke, "left the team"))
+
+	// Users cannot certify their own access
+	err = service.DecideReviewItem(WithActorID(helper.GetContext(), keptID), byUser[keptID].ID, ReviewKeep, "")
+	assert.True(t, IsCannotAssign(err))
+
+	report, err := service.ApplyReview(ctx, review.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Revoked)
+	assert.Empty(t, report.Failed)
+	assert.False(t, report.Closed)
+	helper.AssertRoleNotAssigned(revokedID, "admin", "organization", orgID)
+	helper.AssertRoleAssigned(forgottenID, "admin", "organization", orgID)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID(review.ID))
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	// Past the deadline, unreviewed items are revoked and the review closes,
+	// even though the creator no longer holds any role
+	require.NoError(t, service.RevokeAll(ctx, ownerID, "organization", orgID))
+	_, err = service.conn(ctx).NewUpdate().Model((*AccessReview)(nil)).
+		Set("deadline = ?", time.Now().Add(-time.Minute)).
+		Where("id = ?", review.ID).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	reports, err := service.ProcessReviewDeadlines(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(reports), 1)
+	helper.AssertRoleNotAssigned(forgottenID, "admin", "organization", orgID)
+	helper.AssertRoleAssigned(keptID, "admin", "organization", orgID)
+
+	review, err = service.GetReview(ctx, review.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AccessReviewClosed, review.Status)
+}
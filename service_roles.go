@@ -2,6 +2,8 @@ package rolekit
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/uptrace/bun"
 
@@ -19,6 +21,14 @@ import (
 //
 //	err := service.Assign(ctx, targetUserID, "editor", "project", projectID)
 func (s *Service) Assign(ctx context.Context, userID, role, scopeType, scopeID string) error {
+	return s.withAssignmentLocks(ctx, scopeType, []string{role}, func(ctx context.Context) error {
+		return s.assign(ctx, userID, role, scopeType, scopeID)
+	})
+}
+
+// assign implements Assign; it runs inside a transaction when the role has
+// member limits or separation-of-duties constraints.
+func (s *Service) assign(ctx context.Context, userID, role, scopeType, scopeID string) error {
 	// Validate role exists for scope
 	if err := s.registry.ValidateRole(role, scopeType); err != nil {
 		return err
@@ -37,9 +47,13 @@ func (s *Service) Assign(ctx context.Context, userID, role, scopeType, scopeID s
 	}
 
 	// Check if actor can assign this role (skip if actor is assigning to self during bootstrap)
+	actorChecker := s.newChecker(ctx, actorID, actorRoles)
 	if actorID != userID {
-		actorChecker := NewChecker(actorID, actorRoles, s.registry, s)
-		if !actorChecker.CanAssignRole(role, scopeType, scopeID) {
+		canAssign, err := actorChecker.CanAssignRoleContext(ctx, role, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		if !canAssign {
 			return NewError(ErrCannotAssign, "actor cannot assign this role").
 				WithScope(scopeType, scopeID).
 				WithRole(role).
@@ -47,6 +61,11 @@ func (s *Service) Assign(ctx context.Context, userID, role, scopeType, scopeID s
 		}
 	}
 
+	// Self-assignment is the most direct escalation, so the policy applies to it too
+	if err := s.checkEscalation(ctx, actorChecker, role, scopeType, scopeID); err != nil {
+		return err
+	}
+
 	// Get target user's current roles for audit
 	previousRoles, err := s.getUserRoleNames(ctx, userID, scopeType, scopeID)
 	if err != nil {
@@ -63,35 +82,19 @@ func (s *Service) Assign(ctx context.Context, userID, role, scopeType, scopeID s
 		}
 	}
 
-	// Get parent scope if defined
-	var parentScopeType, parentScopeID string
-	scopeDef := s.registry.GetScope(scopeType)
-	if scopeDef != nil && scopeDef.GetParentScope() != "" {
-		// Look up parent from scope_hierarchy table
-		parent, err := s.getParentScope(ctx, scopeType, scopeID)
-		if err == nil && parent != nil {
-			parentScopeType = parent.ParentScopeType
-			parentScopeID = parent.ParentScopeID
-		}
+	// Enforce separation-of-duties constraints
+	if err := s.checkConstraints(ctx, userID, role, scopeType, scopeID, nil); err != nil {
+		return err
 	}
 
-	// Create assignment
-	assignment := &RoleAssignment{
-		UserID:          userID,
-		Role:            role,
-		ScopeType:       scopeType,
-		ScopeID:         scopeID,
-		ParentScopeType: parentScopeType,
-		ParentScopeID:   parentScopeID,
+	// Enforce the role's maximum number of members
+	if err := s.checkCardinality(ctx, role, scopeType, scopeID, 1); err != nil {
+		return err
 	}
 
-	result, err := s.db.NewInsert().Model(assignment).Exec(ctx)
-	err = dbkit.WithErr(result, err, "CreateRoleAssignment").Err()
-	if err != nil {
-		return NewError(ErrDatabaseError, "failed to create role assignment").
-			WithScope(scopeType, scopeID).
-			WithRole(role).
-			WithUser(userID)
+	// Create assignment
+	if err := s.insertAssignment(ctx, userID, role, scopeType, scopeID); err != nil {
+		return err
 	}
 
 	// Calculate new roles after assignment
@@ -125,6 +128,14 @@ func (s *Service) Assign(ctx context.Context, userID, role, scopeType, scopeID s
 //
 //	err := service.Revoke(ctx, targetUserID, "editor", "project", projectID)
 func (s *Service) Revoke(ctx context.Context, userID, role, scopeType, scopeID string) error {
+	return s.withAssignmentLocks(ctx, scopeType, []string{role}, func(ctx context.Context) error {
+		return s.revoke(ctx, userID, role, scopeType, scopeID)
+	})
+}
+
+// revoke implements Revoke; it runs inside a transaction when the role has
+// member limits or separation-of-duties constraints.
+func (s *Service) revoke(ctx context.Context, userID, role, scopeType, scopeID string) error {
 	// Validate role exists for scope
 	if err := s.registry.ValidateRole(role, scopeType); err != nil {
 		return err
@@ -142,8 +153,12 @@ func (s *Service) Revoke(ctx context.Context, userID, role, scopeType, scopeID s
 	}
 
 	if actorID != userID {
-		actorChecker := NewChecker(actorID, actorRoles, s.registry, s)
-		if !actorChecker.CanAssignRole(role, scopeType, scopeID) {
+		actorChecker := s.newChecker(ctx, actorID, actorRoles)
+		canAssign, err := actorChecker.CanAssignRoleContext(ctx, role, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		if !canAssign {
 			return NewError(ErrCannotAssign, "actor cannot revoke this role").
 				WithScope(scopeType, scopeID).
 				WithRole(role).
@@ -151,6 +166,12 @@ func (s *Service) Revoke(ctx context.Context, userID, role, scopeType, scopeID s
 		}
 	}
 
+	return s.removeRole(ctx, actorID, actorRoles, userID, role, scopeType, scopeID)
+}
+
+// removeRole deletes an assignment the actor is already authorized to revoke,
+// enforcing MinMembers, and records the audit entry.
+func (s *Service) removeRole(ctx context.Context, actorID string, actorRoles *UserRoles, userID, role, scopeType, scopeID string) error {
 	// Get current roles for audit
 	previousRoles, err := s.getUserRoleNames(ctx, userID, scopeType, scopeID)
 	if err != nil {
@@ -172,18 +193,14 @@ func (s *Service) Revoke(ctx context.Context, userID, role, scopeType, scopeID s
 			WithUser(userID)
 	}
 
-	// Delete assignment
-	result, err := s.db.NewDelete().Table("role_assignments").Where("user_id = ? AND role = ? AND scope_type = ? AND scope_id = ?", userID, role, scopeType, scopeID).Exec(ctx)
-	err = dbkit.WithErr(result, err, "DeleteRoleAssignment").Err()
-	if err != nil {
+	// Enforce the role's minimum number of members (e.g. the last owner)
+	if err := s.checkCardinality(ctx, role, scopeType, scopeID, -1); err != nil {
 		return err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return NewError(ErrRoleNotAssigned, "user does not have this role").
-			WithScope(scopeType, scopeID).
-			WithRole(role).
-			WithUser(userID)
+
+	// Delete assignment
+	if err := s.deleteAssignment(ctx, userID, role, scopeType, scopeID); err != nil {
+		return err
 	}
 
 	// Calculate new roles after revocation
@@ -216,27 +233,80 @@ func (s *Service) Revoke(ctx context.Context, userID, role, scopeType, scopeID s
 	return nil
 }
 
-// RevokeAll removes all roles from a user in a scope.
+// RevokeAll removes all roles from a user in a scope. The revocations run in
+// one transaction: if any of them fails, none is applied. Use OffboardUser to
+// remove a user from every scope.
 //
 // Example:
 //
 //	err := service.RevokeAll(ctx, targetUserID, "project", projectID)
 func (s *Service) RevokeAll(ctx context.Context, userID, scopeType, scopeID string) error {
-	// Get current roles
-	currentRoles, err := s.getUserRoleNames(ctx, userID, scopeType, scopeID)
-	if err != nil {
-		return err
-	}
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		var currentRoles []string
+		err := dbkit.WithErr1(s.conn(ctx).NewRaw("SELECT role FROM role_assignments WHERE user_id = ? AND scope_type = ? AND scope_id = ? AND "+activeAssignment, userID, scopeType, scopeID).Scan(ctx, &currentRoles), "RevokeAll").Err()
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
 
-	// Revoke each role individually (for proper audit logging)
-	for _, role := range currentRoles {
-		if err := s.Revoke(ctx, userID, role, scopeType, scopeID); err != nil {
-			// Continue revoking other roles even if one fails
-			continue
+		// Revoke each role individually (for proper audit logging)
+		for _, role := range currentRoles {
+			if err := s.revoke(ctx, userID, role, scopeType, scopeID); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
+
+// SystemActorID is recorded as the actor of audit entries written by
+// maintenance operations (such as RevokeExpired) when the context has no actor.
+const SystemActorID = "system"
+
+// RevokeExpired deletes every role assignment whose expiry has passed and
+// records an "expired" audit entry for each. Expired assignments already grant
+// nothing; call this periodically to keep the table and audit trail current.
+//
+// Example:
+//
+//	expired, err := service.RevokeExpired(ctx)
+//	log.Printf("revoked %d expired assignments", len(expired))
+func (s *Service) RevokeExpired(ctx context.Context) ([]RoleAssignment, error) {
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		actorID = SystemActorID
 	}
 
-	return nil
+	var expired []RoleAssignment
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		err := dbkit.WithErr1(s.conn(ctx).NewRaw("DELETE FROM role_assignments WHERE expires_at <= current_timestamp RETURNING *").Scan(ctx, &expired), "RevokeExpired").Err()
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		audit := GetAuditContext(ctx)
+		for _, a := range expired {
+			err := s.logAudit(ctx, &AuditEntry{
+				ActorID:      actorID,
+				Action:       AuditActionExpired,
+				TargetUserID: a.UserID,
+				Role:         a.Role,
+				ScopeType:    a.ScopeType,
+				ScopeID:      a.ScopeID,
+				IPAddress:    audit.IPAddress,
+				UserAgent:    audit.UserAgent,
+				RequestID:    audit.RequestID,
+				Metadata:     map[string]any{"expires_at": a.ExpiresAt},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
 }
 
 // RoleRevocation represents a role revocation operation for bulk operations.
@@ -259,13 +329,33 @@ type RoleRevocation struct {
 //	err := service.AssignMultiple(ctx, assignments)
 func (s *Service) AssignMultiple(ctx context.Context, assignments []RoleAssignment) error {
 	return s.Transaction(ctx, func(ctx context.Context) error {
+		// Enforce separation-of-duties constraints, including conflicts within the batch
+		for i, assignment := range assignments {
+			if err := s.checkConstraints(ctx, assignment.UserID, assignment.Role,
+				assignment.ScopeType, assignment.ScopeID, assignments[:i]); err != nil {
+				return err
+			}
+		}
+
+		// Enforce member limits for every role and scope instance in the batch
+		type slot struct{ role, scopeType, scopeID string }
+		added := make(map[slot]int)
+		for _, assignment := range assignments {
+			added[slot{assignment.Role, assignment.ScopeType, assignment.ScopeID}]++
+		}
+		for key, n := range added {
+			if err := s.checkCardinality(ctx, key.role, key.scopeType, key.scopeID, n); err != nil {
+				return err
+			}
+		}
+
 		// Use batch insert for better performance
 		assignmentModels := make([]*RoleAssignment, len(assignments))
 		for i, assignment := range assignments {
 			assignmentModels[i] = &assignment
 		}
 
-		_, err := dbkit.BatchInsert(ctx, s.db, assignmentModels, dbkit.BatchSize)
+		_, err := dbkit.BatchInsert(ctx, s.conn(ctx), assignmentModels, dbkit.BatchSize)
 		err = dbkit.WithErr1(err, "AssignMultiple").Err()
 		if err != nil {
 			return NewError(ErrDatabaseError, "failed to batch assign roles").
@@ -315,8 +405,13 @@ func (s *Service) RevokeMultiple(ctx context.Context, revocations []RoleRevocati
 				continue // Skip if user doesn't have this role
 			}
 
+			// Enforce the role's minimum number of members
+			if err := s.checkCardinality(ctx, revocation.Role, revocation.ScopeType, revocation.ScopeID, -1); err != nil {
+				return err
+			}
+
 			// Delete the assignment
-			result, err := s.db.NewDelete().Table("role_assignments").
+			result, err := s.conn(ctx).NewDelete().Table("role_assignments").
 				Where("user_id = ? AND role = ? AND scope_type = ? AND scope_id = ?",
 					revocation.UserID, revocation.Role, revocation.ScopeType, revocation.ScopeID).Exec(ctx)
 			err = dbkit.WithErr(result, err, "RevokeMultiple").Err()
@@ -354,8 +449,8 @@ func (s *Service) RevokeMultiple(ctx context.Context, revocations []RoleRevocati
 //	    log.Println("User is admin")
 //	}
 func (s *Service) CheckExists(ctx context.Context, userID, role, scopeType, scopeID string) bool {
-	exists, err := dbkit.Exists[RoleAssignment](ctx, s.db, func(q *bun.SelectQuery) *bun.SelectQuery {
-		return q.Where("user_id = ? AND role = ? AND scope_type = ? AND scope_id = ?",
+	exists, err := dbkit.Exists[RoleAssignment](ctx, s.conn(ctx), func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("user_id = ? AND role = ? AND scope_type = ? AND scope_id = ? AND "+activeAssignment+" AND "+unarchivedScope,
 			userID, role, scopeType, scopeID)
 	})
 
@@ -374,8 +469,8 @@ func (s *Service) CheckExists(ctx context.Context, userID, role, scopeType, scop
 //	count := service.CountRoles(ctx, "user1", "organization", "org1")
 //	log.Printf("User has %d roles in org1", count)
 func (s *Service) CountRoles(ctx context.Context, userID, scopeType, scopeID string) (int, error) {
-	return dbkit.Count[RoleAssignment](ctx, s.db, func(q *bun.SelectQuery) *bun.SelectQuery {
-		return q.Where("user_id = ? AND scope_type = ? AND (scope_id = ? OR scope_id = '*')",
+	return dbkit.Count[RoleAssignment](ctx, s.conn(ctx), func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("user_id = ? AND scope_type = ? AND (scope_id = ? OR scope_id = '*') AND "+activeAssignment,
 			userID, scopeType, scopeID)
 	})
 }
@@ -388,7 +483,7 @@ func (s *Service) CountRoles(ctx context.Context, userID, scopeType, scopeID str
 //	total := service.CountAllRoles(ctx)
 //	log.Printf("Total role assignments: %d", total)
 func (s *Service) CountAllRoles(ctx context.Context) (int, error) {
-	return dbkit.Count[RoleAssignment](ctx, s.db, func(q *bun.SelectQuery) *bun.SelectQuery {
+	return dbkit.Count[RoleAssignment](ctx, s.conn(ctx), func(q *bun.SelectQuery) *bun.SelectQuery {
 		return q
 	})
 }
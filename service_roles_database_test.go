@@ -415,3 +415,84 @@ func TestServiceRevokeAllDatabase(t *testing.T) {
 		}
 	})
 }
+
+// TestServiceCrossScopeAssignDatabase tests assigning project roles from an organization role
+func TestServiceCrossScopeAssignDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	ctx := helper.GetContext()
+	service.Registry().GetRole("admin", "organization").CanAssignIn("project", "developer", "viewer")
+
+	orgID := helper.CreateTestOrg("org")
+	projectID := helper.CreateTestProject("project")
+	otherProjectID := helper.CreateTestProject("project")
+	userID := helper.CreateTestUser("user")
+
+	bootstrapID := helper.CreateTestUser("bootstrap")
+	if err := helper.SetupAdminUser(bootstrapID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	adminID := helper.CreateTestUser("admin")
+	if err := service.Assign(WithActorID(ctx, bootstrapID), adminID, "admin", "organization", orgID); err != nil {
+		t.Fatalf("Failed to assign org admin: %v", err)
+	}
+	if err := service.SetScopeParent(ctx, "project", projectID, "organization", orgID); err != nil {
+		t.Fatalf("Failed to set scope parent: %v", err)
+	}
+
+	actorCtx := WithActorID(ctx, adminID)
+
+	t.Run("Assign in child scope", func(t *testing.T) {
+		if err := service.Assign(actorCtx, userID, "developer", "project", projectID); err != nil {
+			t.Errorf("Org admin should assign developer in child project: %v", err)
+		}
+		helper.AssertRoleAssigned(userID, "developer", "project", projectID)
+	})
+
+	t.Run("Role not granted by CanAssignIn", func(t *testing.T) {
+		err := service.Assign(actorCtx, userID, "project_manager", "project", projectID)
+		if !IsCannotAssign(err) {
+			t.Errorf("Expected cannot assign error, got %v", err)
+		}
+	})
+
+	t.Run("Project outside the organization", func(t *testing.T) {
+		err := service.Assign(actorCtx, userID, "developer", "project", otherProjectID)
+		if !IsCannotAssign(err) {
+			t.Errorf("Expected cannot assign error, got %v", err)
+		}
+	})
+
+	t.Run("Revoke in child scope", func(t *testing.T) {
+		if err := service.Revoke(actorCtx, userID, "developer", "project", projectID); err != nil {
+			t.Errorf("Org admin should revoke developer in child project: %v", err)
+		}
+	})
+
+	t.Run("Assignable roles", func(t *testing.T) {
+		checker, err := service.GetChecker(ctx, adminID)
+		if err != nil {
+			t.Fatalf("Failed to get checker: %v", err)
+		}
+		assignable, err := checker.GetAssignableRolesContext(ctx, "project", projectID)
+		if err != nil {
+			t.Fatalf("Failed to get assignable roles: %v", err)
+		}
+		if len(assignable) != 2 {
+			t.Errorf("Expected 2 assignable roles, got %v", assignable)
+		}
+
+		// The methods without a context honor CanAssignIn too
+		if got := checker.GetAssignableRoles("project", projectID); len(got) != 2 {
+			t.Errorf("Expected 2 assignable roles without a context, got %v", got)
+		}
+		if !checker.CanAssignRole("developer", "project", projectID) {
+			t.Error("Org admin should be able to assign developer in child project")
+		}
+	})
+}
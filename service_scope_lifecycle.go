@@ -0,0 +1,360 @@
+package rolekit
+
+import (
+	"context"
+	"time"
+
+	"github.com/fernandezvara/dbkit"
+	"github.com/uptrace/bun"
+)
+
+// ============================================================================
+// SCOPE LIFECYCLE
+// ============================================================================
+
+// DeleteScope deletes a scope instance and all its descendants from RoleKit's
+// tables in one transaction: role assignments, hierarchy links, archive
+// markers and resource links are removed, delegations are revoked and pending
+// invitations are cancelled. The actor in the context must be able to assign
+// every role held in the affected scopes. Each removed assignment is audited as a revocation;
+// all entries share a correlation ID. It returns the number of removed
+// assignments.
+//
+// Example:
+//
+//	// Project deleted by the application: clean up its roles
+//	removed, err := service.DeleteScope(ctx, "project", projectID)
+func (s *Service) DeleteScope(ctx context.Context, scopeType, scopeID string) (int, error) {
+	if err := s.registry.ValidateScope(scopeType); err != nil {
+		return 0, err
+	}
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return 0, NewError(ErrNoActorID, "actor ID required to delete a scope")
+	}
+
+	removed := 0
+	ctx = withCorrelationID(ctx, newCorrelationID())
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		descendants, err := s.getDescendantScopes(ctx, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		scopes := append([]Scope{NewScope(scopeType, scopeID)}, descendants...)
+		if err := s.authorizeScopeChange(ctx, actorID, scopes...); err != nil {
+			return err
+		}
+
+		audit := GetAuditContext(ctx)
+		for _, scope := range scopes {
+			var deleted []RoleAssignment
+			err := dbkit.WithErr1(s.conn(ctx).NewRaw(
+				"DELETE FROM role_assignments WHERE scope_type = ? AND scope_id = ? RETURNING *",
+				scope.Type, scope.ID).Scan(ctx, &deleted), "DeleteScopeAssignments").Err()
+			if err != nil && !dbkit.IsNotFound(err) {
+				return err
+			}
+			for _, a := range deleted {
+				entry := &AuditEntry{
+					ActorID:       actorID,
+					Action:        AuditActionRevoked,
+					TargetUserID:  a.UserID,
+					Role:          a.Role,
+					ScopeType:     a.ScopeType,
+					ScopeID:       a.ScopeID,
+					PreviousRoles: []string{a.Role},
+					IPAddress:     audit.IPAddress,
+					UserAgent:     audit.UserAgent,
+					RequestID:     audit.RequestID,
+					Metadata:      map[string]any{"reason": "scope_deleted"},
+				}
+				if err := s.logAudit(ctx, entry); err != nil {
+					return err
+				}
+			}
+			removed += len(deleted)
+
+			if err := s.deleteScopeRecords(ctx, actorID, scope); err != nil {
+				return err
+			}
+		}
+
+		return s.logScopeEvent(ctx, AuditActionScopeDeleted, scopeType, scopeID, map[string]any{
+			"descendants":         len(descendants),
+			"removed_assignments": removed,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// deleteScopeRecords removes everything but the role assignments that refers to a deleted scope.
+func (s *Service) deleteScopeRecords(ctx context.Context, actorID string, scope Scope) error {
+	db := s.conn(ctx)
+
+	result, err := db.NewDelete().Model((*ScopeHierarchy)(nil)).
+		WhereOr("scope_type = ? AND scope_id = ?", scope.Type, scope.ID).
+		WhereOr("parent_scope_type = ? AND parent_scope_id = ?", scope.Type, scope.ID).
+		Exec(ctx)
+	if err = dbkit.WithErr(result, err, "DeleteScopeHierarchy").Err(); err != nil {
+		return err
+	}
+
+	result, err = db.NewDelete().Model((*ArchivedScope)(nil)).
+		Where("scope_type = ? AND scope_id = ?", scope.Type, scope.ID).
+		Exec(ctx)
+	if err = dbkit.WithErr(result, err, "DeleteArchivedScope").Err(); err != nil {
+		return err
+	}
+
+	result, err = db.NewDelete().Model((*ResourceScope)(nil)).
+		Where("scope_type = ? AND scope_id = ?", scope.Type, scope.ID).
+		Exec(ctx)
+	if err = dbkit.WithErr(result, err, "DeleteResourceScopes").Err(); err != nil {
+		return err
+	}
+
+	result, err = db.NewUpdate().Model((*Delegation)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Set("revoked_by = ?", actorID).
+		Where("scope_type = ? AND scope_id = ? AND revoked_at IS NULL", scope.Type, scope.ID).
+		Exec(ctx)
+	if err = dbkit.WithErr(result, err, "RevokeScopeDelegations").Err(); err != nil {
+		return err
+	}
+
+	result, err = db.NewUpdate().Model((*Invitation)(nil)).
+		Set("status = ?", InvitationRevoked).
+		Where("scope_type = ? AND scope_id = ? AND status = ?", scope.Type, scope.ID, InvitationPending).
+		Exec(ctx)
+	return dbkit.WithErr(result, err, "RevokeScopeInvitations").Err()
+}
+
+// ArchiveScope archives a scope instance: its role assignments are kept but
+// ignored by GetUserRoles, checkers and CheckExists until RestoreScope is
+// called. Descendant scopes are not archived. The actor in the context must be
+// able to assign every role held in the scope.
+//
+// Example:
+//
+//	err := service.ArchiveScope(ctx, "project", projectID)
+func (s *Service) ArchiveScope(ctx context.Context, scopeType, scopeID string) error {
+	if err := s.registry.ValidateScope(scopeType); err != nil {
+		return err
+	}
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return NewError(ErrNoActorID, "actor ID required to archive a scope")
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.authorizeScopeChange(ctx, actorID, NewScope(scopeType, scopeID)); err != nil {
+			return err
+		}
+
+		archived := &ArchivedScope{ScopeType: scopeType, ScopeID: scopeID, ArchivedBy: actorID}
+		result, err := s.conn(ctx).NewInsert().Model(archived).On("CONFLICT DO NOTHING").Exec(ctx)
+		if err = dbkit.WithErr(result, err, "ArchiveScope").Err(); err != nil {
+			return err
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return NewError(ErrInvalidScope, "scope is already archived").
+				WithScope(scopeType, scopeID).
+				WithActor(actorID)
+		}
+
+		return s.logScopeEvent(ctx, AuditActionScopeArchived, scopeType, scopeID, nil)
+	})
+}
+
+// RestoreScope reverses ArchiveScope. Roles held in the archived scope itself
+// count towards the actor's authority.
+//
+// Example:
+//
+//	err := service.RestoreScope(ctx, "project", projectID)
+func (s *Service) RestoreScope(ctx context.Context, scopeType, scopeID string) error {
+	if err := s.registry.ValidateScope(scopeType); err != nil {
+		return err
+	}
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return NewError(ErrNoActorID, "actor ID required to restore a scope")
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.authorizeScopeChange(ctx, actorID, NewScope(scopeType, scopeID)); err != nil {
+			return err
+		}
+
+		result, err := s.conn(ctx).NewDelete().Model((*ArchivedScope)(nil)).
+			Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).
+			Exec(ctx)
+		if err = dbkit.WithErr(result, err, "RestoreScope").Err(); err != nil {
+			return err
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return NewError(ErrInvalidScope, "scope is not archived").
+				WithScope(scopeType, scopeID).
+				WithActor(actorID)
+		}
+
+		return s.logScopeEvent(ctx, AuditActionScopeRestored, scopeType, scopeID, nil)
+	})
+}
+
+// IsScopeArchived reports whether a scope instance is archived.
+func (s *Service) IsScopeArchived(ctx context.Context, scopeType, scopeID string) (bool, error) {
+	return dbkit.Exists[ArchivedScope](ctx, s.conn(ctx), func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("scope_type = ? AND scope_id = ?", scopeType, scopeID)
+	})
+}
+
+// MoveScope gives a scope instance a new parent, rewriting scope_hierarchy and
+// the parent_scope_* columns of its role assignments atomically. The new parent
+// must match the scope's parent type in the registry and cannot be the scope or
+// one of its descendants. The actor in the context must be able to assign
+// every role held in the scope and some role in the new parent.
+//
+// Example:
+//
+//	// Project transferred to another organization
+//	err := service.MoveScope(ctx, "project", projectID, "organization", newOrgID)
+func (s *Service) MoveScope(ctx context.Context, scopeType, scopeID, newParentType, newParentID string) error {
+	if err := s.registry.ValidateScope(scopeType); err != nil {
+		return err
+	}
+	if err := s.registry.ValidateScope(newParentType); err != nil {
+		return err
+	}
+	if parentType := s.registry.GetScope(scopeType).GetParentScope(); parentType != "" && parentType != newParentType {
+		return NewError(ErrInvalidScope, "scope type "+scopeType+" must have a "+parentType+" parent").
+			WithScope(newParentType, newParentID)
+	}
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return NewError(ErrNoActorID, "actor ID required to move a scope")
+	}
+
+	newParent := NewScope(newParentType, newParentID)
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		descendants, err := s.getDescendantScopes(ctx, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		if newParent == NewScope(scopeType, scopeID) || containsScope(descendants, newParent) {
+			return NewError(ErrInvalidScope, "cannot move a scope under itself or its descendants").
+				WithScope(scopeType, scopeID).
+				WithActor(actorID)
+		}
+
+		if err := s.authorizeScopeChange(ctx, actorID, NewScope(scopeType, scopeID)); err != nil {
+			return err
+		}
+		actorRoles, err := s.GetUserRoles(ctx, actorID)
+		if err != nil {
+			return err
+		}
+		assignable, err := s.newChecker(ctx, actorID, actorRoles).GetAssignableRolesContext(ctx, newParentType, newParentID)
+		if err != nil {
+			return err
+		}
+		if len(assignable) == 0 {
+			return NewError(ErrCannotAssign, "actor cannot manage the new parent scope").
+				WithScope(newParentType, newParentID).
+				WithActor(actorID)
+		}
+
+		previous, err := s.getParentScope(ctx, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		result, err := s.conn(ctx).NewDelete().Model((*ScopeHierarchy)(nil)).
+			Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).
+			Exec(ctx)
+		if err = dbkit.WithErr(result, err, "MoveScope").Err(); err != nil {
+			return err
+		}
+		if err := s.SetScopeParent(ctx, scopeType, scopeID, newParentType, newParentID); err != nil {
+			return err
+		}
+
+		metadata := map[string]any{"new_parent_scope_type": newParentType, "new_parent_scope_id": newParentID}
+		if previous != nil {
+			metadata["previous_parent_scope_type"] = previous.ParentScopeType
+			metadata["previous_parent_scope_id"] = previous.ParentScopeID
+		}
+		return s.logScopeEvent(ctx, AuditActionScopeMoved, scopeType, scopeID, metadata)
+	})
+}
+
+// authorizeScopeChange checks that the actor can assign some role in each
+// scope and every role currently held there. Roles held in archived scopes
+// count, so archived scopes can be restored or deleted.
+func (s *Service) authorizeScopeChange(ctx context.Context, actorID string, scopes ...Scope) error {
+	actorRoles, err := s.loadUserRoles(ctx, actorID, true)
+	if err != nil {
+		return err
+	}
+	checker := s.newChecker(ctx, actorID, actorRoles)
+
+	for _, scope := range scopes {
+		assignable, err := checker.GetAssignableRolesContext(ctx, scope.Type, scope.ID)
+		if err != nil {
+			return err
+		}
+		if len(assignable) == 0 {
+			return NewError(ErrCannotAssign, "actor cannot manage this scope").
+				WithScope(scope.Type, scope.ID).
+				WithActor(actorID)
+		}
+
+		var roles []string
+		err = dbkit.WithErr1(s.conn(ctx).NewRaw(
+			"SELECT DISTINCT role FROM role_assignments WHERE scope_type = ? AND scope_id = ? AND "+activeAssignment,
+			scope.Type, scope.ID).Scan(ctx, &roles), "GetScopeRoles").Err()
+		if err != nil && !dbkit.IsNotFound(err) {
+			return err
+		}
+		for _, role := range roles {
+			canAssign, err := checker.CanAssignRoleContext(ctx, role, scope.Type, scope.ID)
+			if err != nil {
+				return err
+			}
+			if !canAssign {
+				return NewError(ErrCannotAssign, "actor cannot revoke a role held in this scope").
+					WithScope(scope.Type, scope.ID).
+					WithRole(role).
+					WithActor(actorID)
+			}
+		}
+	}
+	return nil
+}
+
+// logScopeEvent writes an audit entry about a scope instance itself.
+func (s *Service) logScopeEvent(ctx context.Context, action AuditAction, scopeType, scopeID string, metadata map[string]any) error {
+	audit := GetAuditContext(ctx)
+	return s.logAudit(ctx, &AuditEntry{
+		ActorID:   GetActorID(ctx),
+		Action:    action,
+		ScopeType: scopeType,
+		ScopeID:   scopeID,
+		IPAddress: audit.IPAddress,
+		UserAgent: audit.UserAgent,
+		RequestID: audit.RequestID,
+		Metadata:  metadata,
+	})
+}
+
+// containsScope reports whether a scope is in the list.
+func containsScope(scopes []Scope, scope Scope) bool {
+	for _, sc := range scopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
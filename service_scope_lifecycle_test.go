@@ -0,0 +1,120 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceScopeLifecycleValidation tests argument validation before any database access
+func TestServiceScopeLifecycleValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("admin").Permissions("*").CanAssign("*")
+	registry.DefineScope("project").ParentScope("organization").
+		Role("manager").Permissions("*").CanAssign("*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "admin1")
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		_, err := service.DeleteScope(ctx, "unknown", "x")
+		assert.True(t, IsInvalidScope(err))
+		assert.True(t, IsInvalidScope(service.ArchiveScope(ctx, "unknown", "x")))
+		assert.True(t, IsInvalidScope(service.RestoreScope(ctx, "unknown", "x")))
+		assert.True(t, IsInvalidScope(service.MoveScope(ctx, "unknown", "x", "organization", "org1")))
+	})
+
+	t.Run("Wrong parent type", func(t *testing.T) {
+		err := service.MoveScope(ctx, "project", "proj1", "project", "proj2")
+		assert.True(t, IsInvalidScope(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		_, err := service.DeleteScope(context.Background(), "project", "proj1")
+		assert.ErrorIs(t, err, ErrNoActorID)
+		assert.ErrorIs(t, service.ArchiveScope(context.Background(), "project", "proj1"), ErrNoActorID)
+		assert.ErrorIs(t, service.RestoreScope(context.Background(), "project", "proj1"), ErrNoActorID)
+		assert.ErrorIs(t, service.MoveScope(context.Background(), "project", "proj1", "organization", "org2"), ErrNoActorID)
+	})
+}
+
+// TestContainsScope tests scope list membership
+func TestContainsScope(t *testing.T) {
+	scopes := []Scope{NewScope("project", "proj1"), NewScope("team", "team1")}
+	assert.True(t, containsScope(scopes, NewScope("team", "team1")))
+	assert.False(t, containsScope(scopes, NewScope("team", "proj1")))
+	assert.False(t, containsScope(nil, NewScope("team", "team1")))
+}
+
+// TestServiceScopeLifecycleDatabase tests archiving, moving and deleting scopes with real database
+func TestServiceScopeLifecycleDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	parentID := helper.CreateTestOrg("parent")
+	childID := helper.CreateTestOrg("child")
+	otherID := helper.CreateTestOrg("other")
+	adminID := helper.CreateTestUser("admin")
+	devID := helper.CreateTestUser("dev")
+	for _, orgID := range []string{parentID, childID, otherID} {
+		require.NoError(t, helper.SetupAdminUser(adminID, orgID))
+	}
+	ctx := WithActorID(helper.GetContext(), adminID)
+	require.NoError(t, service.SetScopeParent(ctx, "organization", childID, "organization", parentID))
+	require.NoError(t, service.Assign(ctx, devID, "developer", "organization", childID))
+
+	// A second parent is rejected instead of silently duplicated
+	err := service.SetScopeParent(ctx, "organization", childID, "organization", otherID)
+	assert.True(t, IsInvalidScope(err))
+	require.NoError(t, service.SetScopeParent(ctx, "organization", childID, "organization", parentID))
+
+	t.Run("Archive and restore", func(t *testing.T) {
+		require.NoError(t, service.ArchiveScope(ctx, "organization", childID))
+		assert.True(t, IsInvalidScope(service.ArchiveScope(ctx, "organization", childID)))
+
+		archived, err := service.IsScopeArchived(ctx, "organization", childID)
+		require.NoError(t, err)
+		assert.True(t, archived)
+		assert.False(t, service.CheckExists(ctx, devID, "developer", "organization", childID))
+		helper.AssertPermissionDenied(devID, "task.read", "organization", childID)
+
+		require.NoError(t, service.RestoreScope(ctx, "organization", childID))
+		assert.True(t, service.CheckExists(ctx, devID, "developer", "organization", childID))
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		err := service.MoveScope(ctx, "organization", parentID, "organization", childID)
+		assert.True(t, IsInvalidScope(err))
+
+		require.NoError(t, service.MoveScope(ctx, "organization", childID, "organization", otherID))
+		parent, err := service.getParentScope(ctx, "organization", childID)
+		require.NoError(t, err)
+		require.NotNil(t, parent)
+		assert.Equal(t, otherID, parent.ParentScopeID)
+
+		scopeIDs, err := service.GetChildScopes(ctx, devID, "organization", "organization", otherID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{childID}, scopeIDs)
+	})
+
+	t.Run("Delete cascades to descendants", func(t *testing.T) {
+		removed, err := service.DeleteScope(ctx, "organization", otherID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, removed)
+		helper.AssertRoleNotAssigned(devID, "developer", "organization", childID)
+
+		parent, err := service.getParentScope(ctx, "organization", childID)
+		require.NoError(t, err)
+		assert.Nil(t, parent)
+
+		logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithScope("organization", otherID).WithAction(AuditActionScopeDeleted))
+		require.NoError(t, err)
+		assert.Len(t, logs, 1)
+	})
+}
@@ -26,19 +26,19 @@ func (s *Service) Transaction(ctx context.Context, fn func(ctx context.Context)
 	start := time.Now()
 	var err error
 
-	// Check if we're already in a transaction by casting to dbkit.Tx
-	if tx, ok := s.db.(*dbkit.Tx); ok {
+	// Check if we're already in a transaction (carried by the context or the service itself)
+	if tx := s.activeTx(ctx); tx != nil {
 		// We're already in a transaction, use savepoint
 		err = tx.Transaction(ctx, func(tx *dbkit.Tx) error {
-			// Use the transaction directly for operations within this scope
-			return fn(ctx)
+			// Run operations within this scope on the savepoint
+			return fn(withTx(ctx, tx))
 		})
 	} else {
 		// We're not in a transaction, start a new one
 		if db, ok := s.db.(*dbkit.DBKit); ok {
 			err = db.Transaction(ctx, func(tx *dbkit.Tx) error {
-				// Use the transaction directly for operations within this scope
-				return fn(ctx)
+				// Run operations within this scope on the transaction
+				return fn(withTx(ctx, tx))
 			})
 		} else {
 			// If we can't determine the type, try to use the generic interface
@@ -64,22 +64,18 @@ func (s *Service) Transaction(ctx context.Context, fn func(ctx context.Context)
 //	    return service.Assign(ctx, "user1", "admin", "organization", "org1")
 //	})
 func (s *Service) TransactionWithOptions(ctx context.Context, opts dbkit.TxOptions, fn func(ctx context.Context) error) error {
-	// Check if we're already in a transaction by casting to dbkit.Tx
-	if tx, ok := s.db.(*dbkit.Tx); ok {
+	// Check if we're already in a transaction (carried by the context or the service itself)
+	if tx := s.activeTx(ctx); tx != nil {
 		// We're already in a transaction, use savepoint (no options support in nested transactions)
 		return tx.Transaction(ctx, func(tx *dbkit.Tx) error {
-			// Create a new service that uses the transaction
-			s.db = tx
-			return fn(ctx)
+			return fn(withTx(ctx, tx))
 		})
 	}
 
 	// We're not in a transaction, start a new one
 	if db, ok := s.db.(*dbkit.DBKit); ok {
 		return db.TransactionWithOptions(ctx, opts, func(tx *dbkit.Tx) error {
-			// Create a new service that uses the transaction
-			s.db = tx
-			return fn(ctx)
+			return fn(withTx(ctx, tx))
 		})
 	}
 
@@ -103,3 +99,14 @@ func (s *Service) TransactionWithOptions(ctx context.Context, opts dbkit.TxOptio
 func (s *Service) ReadOnlyTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
 	return s.TransactionWithOptions(ctx, dbkit.ReadOnlyTxOptions(), fn)
 }
+
+// activeTx returns the transaction the call is already part of, if any.
+func (s *Service) activeTx(ctx context.Context) *dbkit.Tx {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx
+	}
+	if tx, ok := s.db.(*dbkit.Tx); ok {
+		return tx
+	}
+	return nil
+}
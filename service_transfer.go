@@ -0,0 +1,200 @@
+package rolekit
+
+import (
+	"context"
+)
+
+// ============================================================================
+// ROLE TRANSFER
+// ============================================================================
+
+// TransferOption configures a role transfer.
+type TransferOption func(*transferOptions)
+
+type transferOptions struct {
+	fallbackRole string
+}
+
+// WithFallbackRole demotes the previous holder to the given role in the same
+// scope instead of leaving them without a role.
+//
+// Example:
+//
+//	service.TransferRole(ctx, oldOwner, newOwner, "owner", "organization", orgID,
+//	    rolekit.WithFallbackRole("admin"))
+func WithFallbackRole(role string) TransferOption {
+	return func(o *transferOptions) {
+		o.fallbackRole = role
+	}
+}
+
+// TransferRole atomically moves a role from one user to another in a scope.
+// The actor must be able to assign the role (and the fallback role, if any).
+// Separation-of-duties constraints and member limits are enforced, and the
+// two sides of the transfer are written to the audit log as a pair of entries
+// sharing a correlation ID.
+//
+// Example:
+//
+//	err := service.TransferRole(ctx, currentOwnerID, newOwnerID, "owner", "organization", orgID)
+func (s *Service) TransferRole(ctx context.Context, fromUserID, toUserID, role, scopeType, scopeID string, opts ...TransferOption) error {
+	options := transferOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Validate roles exist for scope
+	if err := s.registry.ValidateRole(role, scopeType); err != nil {
+		return err
+	}
+	if options.fallbackRole != "" {
+		if err := s.registry.ValidateRole(options.fallbackRole, scopeType); err != nil {
+			return err
+		}
+	}
+
+	if fromUserID == toUserID {
+		return NewError(ErrCannotAssign, "cannot transfer a role to the same user").
+			WithScope(scopeType, scopeID).
+			WithRole(role).
+			WithUser(toUserID)
+	}
+
+	actorID := GetActorID(ctx)
+	if actorID == "" {
+		return NewError(ErrNoActorID, "actor ID required for role transfer")
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		actorRoles, err := s.GetUserRoles(ctx, actorID)
+		if err != nil {
+			return err
+		}
+
+		// The actor needs assignment authority over every role the transfer hands out
+		actorChecker := s.newChecker(ctx, actorID, actorRoles)
+		for _, r := range []string{role, options.fallbackRole} {
+			if r == "" {
+				continue
+			}
+			canAssign, err := actorChecker.CanAssignRoleContext(ctx, r, scopeType, scopeID)
+			if err != nil {
+				return err
+			}
+			if !canAssign {
+				return NewError(ErrCannotAssign, "actor cannot transfer this role").
+					WithScope(scopeType, scopeID).
+					WithRole(r).
+					WithActor(actorID)
+			}
+			if err := s.checkEscalation(ctx, actorChecker, r, scopeType, scopeID); err != nil {
+				return err
+			}
+		}
+
+		fromPrevious, err := s.getUserRoleNames(ctx, fromUserID, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+		toPrevious, err := s.getUserRoleNames(ctx, toUserID, scopeType, scopeID)
+		if err != nil {
+			return err
+		}
+
+		if !containsString(fromPrevious, role) {
+			return NewError(ErrRoleNotAssigned, "user does not have this role").
+				WithScope(scopeType, scopeID).
+				WithRole(role).
+				WithUser(fromUserID)
+		}
+		if containsString(toPrevious, role) {
+			return NewError(ErrRoleAlreadyAssigned, "user already has this role").
+				WithScope(scopeType, scopeID).
+				WithRole(role).
+				WithUser(toUserID)
+		}
+
+		if err := s.checkConstraints(ctx, toUserID, role, scopeType, scopeID, nil); err != nil {
+			return err
+		}
+
+		// The number of holders does not change, but lock them so a concurrent
+		// revocation cannot drop the role below its minimum mid-transfer
+		if err := s.checkCardinality(ctx, role, scopeType, scopeID, 0); err != nil {
+			return err
+		}
+
+		if err := s.deleteAssignment(ctx, fromUserID, role, scopeType, scopeID); err != nil {
+			return err
+		}
+		if err := s.insertAssignment(ctx, toUserID, role, scopeType, scopeID); err != nil {
+			return err
+		}
+
+		fromNew := removeString(fromPrevious, role)
+		demoted := options.fallbackRole != "" && !containsString(fromNew, options.fallbackRole)
+		if demoted {
+			if err := s.checkConstraints(ctx, fromUserID, options.fallbackRole, scopeType, scopeID, nil); err != nil {
+				return err
+			}
+			if err := s.checkCardinality(ctx, options.fallbackRole, scopeType, scopeID, 1); err != nil {
+				return err
+			}
+			if err := s.insertAssignment(ctx, fromUserID, options.fallbackRole, scopeType, scopeID); err != nil {
+				return err
+			}
+			fromNew = append(fromNew, options.fallbackRole)
+		}
+
+		// Write both sides of the transfer as one linked pair
+		correlationID := newCorrelationID()
+		audit := GetAuditContext(ctx)
+		outMetadata := map[string]any{"transferred_to": toUserID}
+		if demoted {
+			outMetadata["fallback_role"] = options.fallbackRole
+		}
+
+		entries := []*AuditEntry{
+			{
+				ActorID:       actorID,
+				Action:        AuditActionTransferOut,
+				TargetUserID:  fromUserID,
+				Role:          role,
+				ScopeType:     scopeType,
+				ScopeID:       scopeID,
+				ActorRoles:    actorRoles.GetRoles(scopeType, scopeID),
+				PreviousRoles: fromPrevious,
+				NewRoles:      fromNew,
+				IPAddress:     audit.IPAddress,
+				UserAgent:     audit.UserAgent,
+				RequestID:     audit.RequestID,
+				CorrelationID: correlationID,
+				Metadata:      outMetadata,
+			},
+			{
+				ActorID:       actorID,
+				Action:        AuditActionTransferIn,
+				TargetUserID:  toUserID,
+				Role:          role,
+				ScopeType:     scopeType,
+				ScopeID:       scopeID,
+				ActorRoles:    actorRoles.GetRoles(scopeType, scopeID),
+				PreviousRoles: toPrevious,
+				NewRoles:      append(append([]string{}, toPrevious...), role),
+				IPAddress:     audit.IPAddress,
+				UserAgent:     audit.UserAgent,
+				RequestID:     audit.RequestID,
+				CorrelationID: correlationID,
+				Metadata:      map[string]any{"transferred_from": fromUserID},
+			},
+		}
+		for _, entry := range entries {
+			// Inside the transaction a failed insert aborts it, so surface the error
+			if err := s.logAudit(ctx, entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+package rolekit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServiceTransferRoleValidation tests argument validation before any database access
+func TestServiceTransferRoleValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.DefineScope("organization").
+		Role("owner").Permissions("*").CanAssign("*").
+		Role("admin").Permissions("members.*")
+	service := &Service{registry: registry}
+	ctx := WithActorID(context.Background(), "owner1")
+
+	t.Run("Invalid role", func(t *testing.T) {
+		err := service.TransferRole(ctx, "owner1", "user2", "unknown", "organization", "org1")
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("Invalid fallback role", func(t *testing.T) {
+		err := service.TransferRole(ctx, "owner1", "user2", "owner", "organization", "org1", WithFallbackRole("unknown"))
+		assert.True(t, IsInvalidRole(err))
+	})
+
+	t.Run("Same user", func(t *testing.T) {
+		err := service.TransferRole(ctx, "owner1", "owner1", "owner", "organization", "org1")
+		assert.True(t, IsCannotAssign(err))
+	})
+
+	t.Run("No actor", func(t *testing.T) {
+		err := service.TransferRole(context.Background(), "owner1", "user2", "owner", "organization", "org1")
+		assert.ErrorIs(t, err, ErrNoActorID)
+	})
+}
+
+// TestServiceTransferRoleDatabase tests transferring a role with real database
+func TestServiceTransferRoleDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	service.Registry().GetRole("super_admin", "organization").MinMembers(1)
+
+	orgID := helper.CreateTestOrg("org")
+	ownerID := helper.CreateTestUser("owner")
+	newOwnerID := helper.CreateTestUser("new-owner")
+	if err := helper.SetupAdminUser(ownerID, orgID); err != nil {
+		t.Fatalf("Failed to setup admin: %v", err)
+	}
+	ctx := WithActorID(helper.GetContext(), ownerID)
+
+	err := service.TransferRole(ctx, ownerID, newOwnerID, "super_admin", "organization", orgID,
+		WithFallbackRole("admin"))
+	require.NoError(t, err)
+
+	helper.AssertRoleAssigned(newOwnerID, "super_admin", "organization", orgID)
+	helper.AssertRoleNotAssigned(ownerID, "super_admin", "organization", orgID)
+	helper.AssertRoleAssigned(ownerID, "admin", "organization", orgID)
+
+	logs, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithScope("organization", orgID).WithAction(AuditActionTransferIn))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.NotEmpty(t, logs[0].CorrelationID)
+
+	pair, err := service.GetAuditLog(ctx, NewAuditLogFilter().WithCorrelationID(logs[0].CorrelationID))
+	require.NoError(t, err)
+	assert.Len(t, pair, 2)
+
+	// The previous owner no longer holds the role and cannot transfer it again
+	err = service.TransferRole(WithActorID(helper.GetContext(), newOwnerID), ownerID, newOwnerID, "super_admin", "organization", orgID)
+	assert.Error(t, err)
+}
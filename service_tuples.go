@@ -0,0 +1,323 @@
+package rolekit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fernandezvara/dbkit"
+)
+
+// ============================================================================
+// RELATION TUPLES
+// ============================================================================
+
+// MaxTupleDepth is the deepest chain of usersets Check and Expand follow
+// before giving up with ErrTupleDepthExceeded.
+const MaxTupleDepth = 32
+
+// WriteTuples stores relation tuples in one transaction. Tuples that already
+// exist are left untouched. Every tuple is validated against the registry's
+// namespaces before anything is written; callers are responsible for deciding
+// who may write them.
+//
+// Example:
+//
+//	err := service.WriteTuples(ctx,
+//	    rolekit.MustParseTuple("group:eng#member@user:alice"),
+//	    rolekit.MustParseTuple("doc:readme#viewer@group:eng#member"))
+func (s *Service) WriteTuples(ctx context.Context, tuples ...RelationTuple) error {
+	records, err := s.tupleRecords(tuples)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		result, err := s.conn(ctx).NewInsert().Model(&records).
+			On("CONFLICT (namespace, object_id, relation, subject_namespace, subject_id, subject_relation) DO NOTHING").
+			Exec(ctx)
+		return dbkit.WithErr(result, err, "WriteTuples").Err()
+	})
+}
+
+// DeleteTuples removes relation tuples in one transaction. Tuples that do not
+// exist are ignored.
+//
+// Example:
+//
+//	err := service.DeleteTuples(ctx, rolekit.MustParseTuple("group:eng#member@user:alice"))
+func (s *Service) DeleteTuples(ctx context.Context, tuples ...RelationTuple) error {
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		for _, t := range tuples {
+			result, err := s.conn(ctx).NewDelete().Model((*TupleRecord)(nil)).
+				Where("namespace = ? AND object_id = ? AND relation = ?", t.Object.Namespace, t.Object.ID, t.Relation).
+				Where("subject_namespace = ? AND subject_id = ? AND subject_relation = ?", t.Subject.Namespace, t.Subject.ID, t.Subject.Relation).
+				Exec(ctx)
+			if err = dbkit.WithErr(result, err, "DeleteTuples").Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReadTuples returns the tuples stored for a relation on an object, without
+// evaluating any rewrite.
+//
+// Example:
+//
+//	tuples, err := service.ReadTuples(ctx, rolekit.NewObjectRef("doc", "readme"), "viewer")
+func (s *Service) ReadTuples(ctx context.Context, object ObjectRef, relation string) ([]RelationTuple, error) {
+	var records []TupleRecord
+	err := dbkit.WithErr1(s.conn(ctx).NewSelect().Model(&records).
+		Where("namespace = ? AND object_id = ? AND relation = ?", object.Namespace, object.ID, relation).
+		Order("subject_namespace", "subject_id", "subject_relation").
+		Scan(ctx), "ReadTuples").Err()
+	if err != nil {
+		return nil, err
+	}
+
+	tuples := make([]RelationTuple, len(records))
+	for i := range records {
+		tuples[i] = records[i].Tuple()
+	}
+	return tuples, nil
+}
+
+// Check reports whether a subject has a relation on an object, following the
+// namespace's rewrites and nested usersets up to MaxTupleDepth.
+//
+// Example:
+//
+//	ok, err := service.Check(ctx, rolekit.NewObjectRef("doc", "readme"), "viewer",
+//	    rolekit.NewSubject("user", "alice"))
+func (s *Service) Check(ctx context.Context, object ObjectRef, relation string, subject Subject) (bool, error) {
+	e := &tupleEvaluator{registry: s.registry, read: s.ReadTuples}
+	return e.check(ctx, object, relation, subject)
+}
+
+// Expand returns the userset tree of a relation on an object: the subjects
+// written for it and, for each rewrite, the usersets it pulls in.
+//
+// Example:
+//
+//	tree, err := service.Expand(ctx, rolekit.NewObjectRef("doc", "readme"), "viewer")
+//	fmt.Println(tree)
+func (s *Service) Expand(ctx context.Context, object ObjectRef, relation string) (*UsersetTree, error) {
+	e := &tupleEvaluator{registry: s.registry, read: s.ReadTuples}
+	return e.expand(ctx, object, relation, 0, make(map[string]bool))
+}
+
+// tupleRecords validates tuples and converts them to their stored form.
+func (s *Service) tupleRecords(tuples []RelationTuple) ([]TupleRecord, error) {
+	records := make([]TupleRecord, 0, len(tuples))
+	for _, t := range tuples {
+		if err := s.registry.ValidateTuple(t); err != nil {
+			return nil, err
+		}
+		records = append(records, TupleRecord{
+			Namespace:        t.Object.Namespace,
+			ObjectID:         t.Object.ID,
+			Relation:         t.Relation,
+			SubjectNamespace: t.Subject.Namespace,
+			SubjectID:        t.Subject.ID,
+			SubjectRelation:  t.Subject.Relation,
+		})
+	}
+	return records, nil
+}
+
+// ============================================================================
+// EVALUATION
+// ============================================================================
+
+// UsersetTree is a node of the tree returned by Expand. The root and every
+// node without a Rewrite is the union of a relation's rewrites on an object;
+// its children are one node per rewrite.
+type UsersetTree struct {
+	Object   ObjectRef
+	Relation string
+	Rewrite  UsersetRewriteKind // How the node was reached; empty for a relation node
+	Subjects []Subject          // Subjects written for the relation (This nodes only)
+	Children []*UsersetTree
+}
+
+// String renders the tree with one node per line.
+func (t *UsersetTree) String() string {
+	var b strings.Builder
+	t.write(&b, 0)
+	return b.String()
+}
+
+func (t *UsersetTree) write(b *strings.Builder, indent int) {
+	b.WriteString(strings.Repeat("  ", indent))
+	kind := string(t.Rewrite)
+	if kind == "" {
+		kind = "union"
+	}
+	fmt.Fprintf(b, "%s %s#%s", kind, t.Object, t.Relation)
+	for i, subject := range t.Subjects {
+		if i == 0 {
+			b.WriteString(":")
+		}
+		b.WriteString(" " + subject.String())
+	}
+	b.WriteString("\n")
+	for _, child := range t.Children {
+		child.write(b, indent+1)
+	}
+}
+
+// tupleEvaluator evaluates relations against stored tuples. read is
+// Service.ReadTuples outside of tests.
+type tupleEvaluator struct {
+	registry *Registry
+	read     func(ctx context.Context, object ObjectRef, relation string) ([]RelationTuple, error)
+	visited  map[string]bool
+}
+
+func (e *tupleEvaluator) check(ctx context.Context, object ObjectRef, relation string, subject Subject) (bool, error) {
+	e.visited = make(map[string]bool)
+	return e.checkAt(ctx, object, relation, subject, 0)
+}
+
+// checkAt evaluates one relation. Each object#relation pair is evaluated at
+// most once per check, which also stops cycles between usersets.
+func (e *tupleEvaluator) checkAt(ctx context.Context, object ObjectRef, relation string, subject Subject, depth int) (bool, error) {
+	if depth > MaxTupleDepth {
+		return false, NewError(ErrTupleDepthExceeded, fmt.Sprintf("%s#%s is nested more than %d levels deep", object, relation, MaxTupleDepth))
+	}
+	if err := e.registry.ValidateRelation(object.Namespace, relation); err != nil {
+		return false, err
+	}
+
+	key := object.String() + "#" + relation
+	if e.visited[key] {
+		return false, nil
+	}
+	e.visited[key] = true
+
+	// A userset contains itself
+	if subject.IsSet() && subject.Object() == object && subject.Relation == relation {
+		return true, nil
+	}
+
+	for _, rw := range e.registry.GetNamespace(object.Namespace).GetRewrites(relation) {
+		switch rw.Kind {
+		case RewriteThis:
+			tuples, err := e.read(ctx, object, relation)
+			if err != nil {
+				return false, err
+			}
+			for _, t := range tuples {
+				if t.Subject == subject {
+					return true, nil
+				}
+			}
+			for _, t := range tuples {
+				if !t.Subject.IsSet() {
+					continue
+				}
+				ok, err := e.checkAt(ctx, t.Subject.Object(), t.Subject.Relation, subject, depth+1)
+				if ok || err != nil {
+					return ok, err
+				}
+			}
+
+		case RewriteComputedUserset:
+			ok, err := e.checkAt(ctx, object, rw.Relation, subject, depth+1)
+			if ok || err != nil {
+				return ok, err
+			}
+
+		case RewriteTupleToUserset:
+			tuples, err := e.read(ctx, object, rw.TuplesetRelation)
+			if err != nil {
+				return false, err
+			}
+			for _, t := range tuples {
+				target := t.Subject.Object()
+				if e.registry.ValidateRelation(target.Namespace, rw.Relation) != nil {
+					continue
+				}
+				ok, err := e.checkAt(ctx, target, rw.Relation, subject, depth+1)
+				if ok || err != nil {
+					return ok, err
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// expand builds the userset tree of a relation. path holds the relations being
+// expanded above this node; a relation found again on its own path is
+// returned without children instead of recursing forever.
+func (e *tupleEvaluator) expand(ctx context.Context, object ObjectRef, relation string, depth int, path map[string]bool) (*UsersetTree, error) {
+	if depth > MaxTupleDepth {
+		return nil, NewError(ErrTupleDepthExceeded, fmt.Sprintf("%s#%s is nested more than %d levels deep", object, relation, MaxTupleDepth))
+	}
+	if err := e.registry.ValidateRelation(object.Namespace, relation); err != nil {
+		return nil, err
+	}
+
+	node := &UsersetTree{Object: object, Relation: relation}
+	key := object.String() + "#" + relation
+	if path[key] {
+		return node, nil
+	}
+	path[key] = true
+	defer delete(path, key)
+
+	for _, rw := range e.registry.GetNamespace(object.Namespace).GetRewrites(relation) {
+		switch rw.Kind {
+		case RewriteThis:
+			tuples, err := e.read(ctx, object, relation)
+			if err != nil {
+				return nil, err
+			}
+			child := &UsersetTree{Object: object, Relation: relation, Rewrite: RewriteThis}
+			for _, t := range tuples {
+				child.Subjects = append(child.Subjects, t.Subject)
+				if !t.Subject.IsSet() {
+					continue
+				}
+				sub, err := e.expand(ctx, t.Subject.Object(), t.Subject.Relation, depth+1, path)
+				if err != nil {
+					return nil, err
+				}
+				child.Children = append(child.Children, sub)
+			}
+			node.Children = append(node.Children, child)
+
+		case RewriteComputedUserset:
+			sub, err := e.expand(ctx, object, rw.Relation, depth+1, path)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, &UsersetTree{
+				Object: object, Relation: rw.Relation, Rewrite: RewriteComputedUserset, Children: []*UsersetTree{sub},
+			})
+
+		case RewriteTupleToUserset:
+			tuples, err := e.read(ctx, object, rw.TuplesetRelation)
+			if err != nil {
+				return nil, err
+			}
+			child := &UsersetTree{Object: object, Relation: rw.TuplesetRelation, Rewrite: RewriteTupleToUserset}
+			for _, t := range tuples {
+				target := t.Subject.Object()
+				if e.registry.ValidateRelation(target.Namespace, rw.Relation) != nil {
+					continue
+				}
+				sub, err := e.expand(ctx, target, rw.Relation, depth+1, path)
+				if err != nil {
+					return nil, err
+				}
+				child.Children = append(child.Children, sub)
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node, nil
+}
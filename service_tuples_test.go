@@ -0,0 +1,223 @@
+package rolekit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTupleTestRegistry defines a document-sharing model with groups and folders
+func newTupleTestRegistry() *Registry {
+	r := NewRegistry()
+	r.DefineNamespace("group").
+		Relation("member").
+		DefineNamespace("folder").
+		Relation("parent").
+		Relation("viewer", This(), TupleToUserset("parent", "viewer")).
+		DefineNamespace("doc").
+		Relation("parent").
+		Relation("owner").
+		Relation("editor", This(), ComputedUserset("owner")).
+		Relation("viewer", This(), ComputedUserset("editor"), TupleToUserset("parent", "viewer"))
+	return r
+}
+
+// newMemoryTupleEvaluator evaluates relations against in-memory tuples
+func newMemoryTupleEvaluator(registry *Registry, tuples ...string) *tupleEvaluator {
+	stored := make(map[string][]RelationTuple)
+	for _, s := range tuples {
+		t := MustParseTuple(s)
+		key := t.Object.String() + "#" + t.Relation
+		stored[key] = append(stored[key], t)
+	}
+	return &tupleEvaluator{
+		registry: registry,
+		read: func(_ context.Context, object ObjectRef, relation string) ([]RelationTuple, error) {
+			return stored[object.String()+"#"+relation], nil
+		},
+	}
+}
+
+// TestTupleEvaluatorCheck tests evaluating rewrites and nested usersets
+func TestTupleEvaluatorCheck(t *testing.T) {
+	ctx := context.Background()
+	e := newMemoryTupleEvaluator(newTupleTestRegistry(),
+		"group:eng#member@user:alice",
+		"group:eng#member@group:platform#member",
+		"group:platform#member@user:bob",
+		"doc:readme#viewer@group:eng#member",
+		"doc:readme#owner@user:carol",
+		"doc:readme#parent@folder:handbook",
+		"folder:handbook#viewer@user:dave",
+		"folder:handbook#parent@folder:root",
+		"folder:root#viewer@user:erin",
+	)
+	readme := NewObjectRef("doc", "readme")
+
+	tests := []struct {
+		name     string
+		relation string
+		subject  Subject
+		expected bool
+	}{
+		{"Direct group member", "viewer", NewSubject("user", "alice"), true},
+		{"Nested group member", "viewer", NewSubject("user", "bob"), true},
+		{"Owner is editor", "editor", NewSubject("user", "carol"), true},
+		{"Owner is viewer", "viewer", NewSubject("user", "carol"), true},
+		{"Viewer is not editor", "editor", NewSubject("user", "alice"), false},
+		{"Parent folder viewer", "viewer", NewSubject("user", "dave"), true},
+		{"Grandparent folder viewer", "viewer", NewSubject("user", "erin"), true},
+		{"Userset subject", "viewer", NewSubjectSet("group", "platform", "member"), true},
+		{"Unrelated user", "viewer", NewSubject("user", "mallory"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := e.check(ctx, readme, tt.relation, tt.subject)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+
+	t.Run("Undefined relation", func(t *testing.T) {
+		_, err := e.check(ctx, readme, "commenter", NewSubject("user", "alice"))
+		assert.True(t, IsInvalidTuple(err))
+	})
+}
+
+// TestTupleEvaluatorLimits tests cycles between usersets and the depth limit
+func TestTupleEvaluatorLimits(t *testing.T) {
+	ctx := context.Background()
+	registry := newTupleTestRegistry()
+
+	t.Run("Cycle", func(t *testing.T) {
+		e := newMemoryTupleEvaluator(registry,
+			"group:a#member@group:b#member",
+			"group:b#member@group:a#member",
+			"group:b#member@user:alice",
+		)
+		ok, err := e.check(ctx, NewObjectRef("group", "a"), "member", NewSubject("user", "alice"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = e.check(ctx, NewObjectRef("group", "a"), "member", NewSubject("user", "bob"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		tree, err := e.expand(ctx, NewObjectRef("group", "a"), "member", 0, make(map[string]bool))
+		require.NoError(t, err)
+		assert.NotEmpty(t, tree.String())
+	})
+
+	t.Run("Depth exceeded", func(t *testing.T) {
+		var tuples []string
+		for i := 0; i <= MaxTupleDepth; i++ {
+			tuples = append(tuples, fmt.Sprintf("group:g%d#member@group:g%d#member", i, i+1))
+		}
+		e := newMemoryTupleEvaluator(registry, tuples...)
+
+		_, err := e.check(ctx, NewObjectRef("group", "g0"), "member", NewSubject("user", "alice"))
+		assert.ErrorIs(t, err, ErrTupleDepthExceeded)
+
+		_, err = e.expand(ctx, NewObjectRef("group", "g0"), "member", 0, make(map[string]bool))
+		assert.ErrorIs(t, err, ErrTupleDepthExceeded)
+	})
+}
+
+// TestTupleEvaluatorExpand tests building the userset tree of a relation
+func TestTupleEvaluatorExpand(t *testing.T) {
+	e := newMemoryTupleEvaluator(newTupleTestRegistry(),
+		"group:eng#member@user:alice",
+		"doc:readme#viewer@group:eng#member",
+		"doc:readme#owner@user:carol",
+		"doc:readme#parent@folder:handbook",
+		"folder:handbook#viewer@user:dave",
+	)
+
+	tree, err := e.expand(context.Background(), NewObjectRef("doc", "readme"), "viewer", 0, make(map[string]bool))
+	require.NoError(t, err)
+	require.Len(t, tree.Children, 3)
+
+	this := tree.Children[0]
+	assert.Equal(t, RewriteThis, this.Rewrite)
+	assert.Equal(t, []Subject{NewSubjectSet("group", "eng", "member")}, this.Subjects)
+	require.Len(t, this.Children, 1)
+	assert.Equal(t, []Subject{NewSubject("user", "alice")}, this.Children[0].Children[0].Subjects)
+
+	computed := tree.Children[1]
+	assert.Equal(t, RewriteComputedUserset, computed.Rewrite)
+	assert.Equal(t, "editor", computed.Relation)
+
+	parent := tree.Children[2]
+	assert.Equal(t, RewriteTupleToUserset, parent.Rewrite)
+	require.Len(t, parent.Children, 1)
+	assert.Equal(t, NewObjectRef("folder", "handbook"), parent.Children[0].Object)
+
+	rendered := tree.String()
+	assert.Contains(t, rendered, "union doc:readme#viewer")
+	assert.Contains(t, rendered, "this group:eng#member: user:alice")
+	assert.Contains(t, rendered, "this doc:readme#owner: user:carol")
+}
+
+// TestServiceTupleValidation tests tuple validation before any database access
+func TestServiceTupleValidation(t *testing.T) {
+	service := &Service{registry: newTupleTestRegistry()}
+	ctx := context.Background()
+
+	err := service.WriteTuples(ctx, MustParseTuple("wiki:home#viewer@user:alice"))
+	assert.True(t, IsInvalidTuple(err))
+
+	err = service.WriteTuples(ctx, RelationTuple{Object: NewObjectRef("doc", "readme"), Relation: "viewer"})
+	assert.True(t, IsInvalidTuple(err))
+
+	_, err = service.Check(ctx, NewObjectRef("wiki", "home"), "viewer", NewSubject("user", "alice"))
+	assert.True(t, IsInvalidTuple(err))
+
+	_, err = service.Expand(ctx, NewObjectRef("doc", "readme"), "commenter")
+	assert.True(t, IsInvalidTuple(err))
+}
+
+// TestServiceTuplesDatabase tests writing, checking and deleting tuples with real database
+func TestServiceTuplesDatabase(t *testing.T) {
+	helper := NewTestDataHelper(t)
+	if helper == nil {
+		return
+	}
+	defer helper.CleanupTestData()
+
+	service := helper.GetService()
+	registry := service.Registry()
+	registry.DefineNamespace("group").
+		Relation("member").
+		DefineNamespace("doc").
+		Relation("viewer")
+
+	ctx := helper.GetContext()
+	groupID := helper.CreateTestUser("group")
+	docID := helper.CreateTestUser("doc")
+	aliceID := helper.CreateTestUser("alice")
+
+	membership := RelationTuple{Object: NewObjectRef("group", groupID), Relation: "member", Subject: NewSubject("user", aliceID)}
+	sharing := RelationTuple{Object: NewObjectRef("doc", docID), Relation: "viewer", Subject: NewSubjectSet("group", groupID, "member")}
+	require.NoError(t, service.WriteTuples(ctx, membership, sharing))
+	require.NoError(t, service.WriteTuples(ctx, membership))
+
+	tuples, err := service.ReadTuples(ctx, NewObjectRef("group", groupID), "member")
+	require.NoError(t, err)
+	assert.Equal(t, []RelationTuple{membership}, tuples)
+
+	ok, err := service.Check(ctx, NewObjectRef("doc", docID), "viewer", NewSubject("user", aliceID))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	tree, err := service.Expand(ctx, NewObjectRef("doc", docID), "viewer")
+	require.NoError(t, err)
+	assert.Contains(t, tree.String(), "user:"+aliceID)
+
+	require.NoError(t, service.DeleteTuples(ctx, membership))
+	ok, err = service.Check(ctx, NewObjectRef("doc", docID), "viewer", NewSubject("user", aliceID))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
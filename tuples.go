@@ -0,0 +1,284 @@
+package rolekit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObjectRef identifies an object in a relation-tuple namespace (e.g. "doc:readme").
+type ObjectRef struct {
+	Namespace string
+	ID        string
+}
+
+// NewObjectRef creates a new ObjectRef.
+func NewObjectRef(namespace, id string) ObjectRef {
+	return ObjectRef{Namespace: namespace, ID: id}
+}
+
+// String returns the "namespace:id" form of the object.
+func (o ObjectRef) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// Subject is the subject of a relation tuple: either a single subject such as
+// "user:alice", or a userset such as "group:eng#member" (every subject that
+// has the relation on the object).
+type Subject struct {
+	Namespace string
+	ID        string
+	Relation  string // Empty for a single subject
+}
+
+// NewSubject creates a subject referring to a single user or object.
+func NewSubject(namespace, id string) Subject {
+	return Subject{Namespace: namespace, ID: id}
+}
+
+// NewSubjectSet creates a subject referring to a userset.
+func NewSubjectSet(namespace, id, relation string) Subject {
+	return Subject{Namespace: namespace, ID: id, Relation: relation}
+}
+
+// IsSet reports whether the subject is a userset.
+func (s Subject) IsSet() bool {
+	return s.Relation != ""
+}
+
+// Object returns the object part of the subject.
+func (s Subject) Object() ObjectRef {
+	return ObjectRef{Namespace: s.Namespace, ID: s.ID}
+}
+
+// String returns the "namespace:id" or "namespace:id#relation" form of the subject.
+func (s Subject) String() string {
+	if s.Relation == "" {
+		return s.Namespace + ":" + s.ID
+	}
+	return s.Namespace + ":" + s.ID + "#" + s.Relation
+}
+
+// RelationTuple states that a subject has a relation on an object.
+type RelationTuple struct {
+	Object   ObjectRef
+	Relation string
+	Subject  Subject
+}
+
+// String returns the "namespace:id#relation@subject" form of the tuple.
+func (t RelationTuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseObjectRef parses an object in "namespace:id" form.
+func ParseObjectRef(s string) (ObjectRef, error) {
+	namespace, id, ok := strings.Cut(s, ":")
+	if !ok || namespace == "" || id == "" || strings.ContainsAny(s, "#@") {
+		return ObjectRef{}, fmt.Errorf("%w: object %q must be namespace:id", ErrInvalidTuple, s)
+	}
+	return ObjectRef{Namespace: namespace, ID: id}, nil
+}
+
+// ParseSubject parses a subject in "namespace:id" or "namespace:id#relation" form.
+func ParseSubject(s string) (Subject, error) {
+	object, relation, hasRelation := strings.Cut(s, "#")
+	if hasRelation && relation == "" {
+		return Subject{}, fmt.Errorf("%w: subject %q has an empty relation", ErrInvalidTuple, s)
+	}
+	ref, err := ParseObjectRef(object)
+	if err != nil {
+		return Subject{}, err
+	}
+	return Subject{Namespace: ref.Namespace, ID: ref.ID, Relation: relation}, nil
+}
+
+// ParseTuple parses a tuple in "namespace:id#relation@subject" form.
+//
+// Example:
+//
+//	tuple, err := rolekit.ParseTuple("doc:readme#viewer@group:eng#member")
+func ParseTuple(s string) (RelationTuple, error) {
+	left, right, ok := strings.Cut(s, "@")
+	if !ok {
+		return RelationTuple{}, fmt.Errorf("%w: tuple %q has no subject", ErrInvalidTuple, s)
+	}
+	object, relation, ok := strings.Cut(left, "#")
+	if !ok || relation == "" {
+		return RelationTuple{}, fmt.Errorf("%w: tuple %q has no relation", ErrInvalidTuple, s)
+	}
+
+	ref, err := ParseObjectRef(object)
+	if err != nil {
+		return RelationTuple{}, err
+	}
+	subject, err := ParseSubject(right)
+	if err != nil {
+		return RelationTuple{}, err
+	}
+	return RelationTuple{Object: ref, Relation: relation, Subject: subject}, nil
+}
+
+// MustParseTuple is like ParseTuple but panics on error. Intended for
+// tests and static tuples.
+func MustParseTuple(s string) RelationTuple {
+	t, err := ParseTuple(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ============================================================================
+// NAMESPACE CONFIGURATION
+// ============================================================================
+
+// UsersetRewriteKind identifies how a userset rewrite computes its subjects.
+type UsersetRewriteKind string
+
+const (
+	// RewriteThis takes the subjects of tuples stored for the relation itself.
+	RewriteThis UsersetRewriteKind = "this"
+
+	// RewriteComputedUserset takes the subjects of another relation on the same object.
+	RewriteComputedUserset UsersetRewriteKind = "computed_userset"
+
+	// RewriteTupleToUserset follows the tuples of one relation to other objects
+	// and takes the subjects of a relation on those objects.
+	RewriteTupleToUserset UsersetRewriteKind = "tuple_to_userset"
+)
+
+// UsersetRewrite is one source of subjects for a relation. A relation's
+// subjects are the union of its rewrites.
+type UsersetRewrite struct {
+	Kind             UsersetRewriteKind
+	Relation         string // Computed relation (ComputedUserset, TupleToUserset)
+	TuplesetRelation string // Relation followed to other objects (TupleToUserset)
+}
+
+// This includes the subjects written directly for the relation.
+func This() UsersetRewrite {
+	return UsersetRewrite{Kind: RewriteThis}
+}
+
+// ComputedUserset includes the subjects of another relation on the same
+// object, e.g. every editor of a document is also a viewer.
+func ComputedUserset(relation string) UsersetRewrite {
+	return UsersetRewrite{Kind: RewriteComputedUserset, Relation: relation}
+}
+
+// TupleToUserset includes, for every object related through tuplesetRelation,
+// the subjects of relation on that object, e.g. the viewers of a document's
+// parent folder are viewers of the document.
+func TupleToUserset(tuplesetRelation, relation string) UsersetRewrite {
+	return UsersetRewrite{Kind: RewriteTupleToUserset, Relation: relation, TuplesetRelation: tuplesetRelation}
+}
+
+// NamespaceDefinition defines the relations of a relation-tuple namespace.
+type NamespaceDefinition struct {
+	name      string
+	relations map[string][]UsersetRewrite
+	registry  *Registry
+}
+
+// DefineNamespace starts defining a relation-tuple namespace.
+// Returns a NamespaceDefinition builder for fluent configuration.
+//
+// Example:
+//
+//	registry.DefineNamespace("group").
+//	    Relation("member")
+//	registry.DefineNamespace("doc").
+//	    Relation("parent").
+//	    Relation("owner").
+//	    Relation("editor", rolekit.This(), rolekit.ComputedUserset("owner")).
+//	    Relation("viewer", rolekit.This(), rolekit.ComputedUserset("editor"),
+//	        rolekit.TupleToUserset("parent", "viewer"))
+func (r *Registry) DefineNamespace(name string) *NamespaceDefinition {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ns := &NamespaceDefinition{
+		name:      name,
+		relations: make(map[string][]UsersetRewrite),
+		registry:  r,
+	}
+	r.namespaces[name] = ns
+	return ns
+}
+
+// GetNamespace returns the namespace definition for a name.
+// Returns nil if the namespace is not defined.
+func (r *Registry) GetNamespace(name string) *NamespaceDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namespaces[name]
+}
+
+// Relation defines a relation of the namespace. Without rewrites the relation
+// only holds the subjects written for it (This).
+func (n *NamespaceDefinition) Relation(name string, rewrites ...UsersetRewrite) *NamespaceDefinition {
+	n.registry.mu.Lock()
+	defer n.registry.mu.Unlock()
+
+	if len(rewrites) == 0 {
+		rewrites = []UsersetRewrite{This()}
+	}
+	n.relations[name] = append([]UsersetRewrite{}, rewrites...)
+	return n
+}
+
+// DefineNamespace allows chaining to define another namespace.
+func (n *NamespaceDefinition) DefineNamespace(name string) *NamespaceDefinition {
+	return n.registry.DefineNamespace(name)
+}
+
+// Name returns the namespace name.
+func (n *NamespaceDefinition) Name() string {
+	return n.name
+}
+
+// GetRewrites returns the rewrites of a relation, or nil if it is not defined.
+func (n *NamespaceDefinition) GetRewrites(relation string) []UsersetRewrite {
+	n.registry.mu.RLock()
+	defer n.registry.mu.RUnlock()
+	return n.relations[relation]
+}
+
+// ValidateRelation checks that a relation is defined in a namespace.
+func (r *Registry) ValidateRelation(namespace, relation string) error {
+	ns := r.GetNamespace(namespace)
+	if ns == nil {
+		return fmt.Errorf("%w: namespace %q not defined", ErrInvalidTuple, namespace)
+	}
+	if ns.GetRewrites(relation) == nil {
+		return fmt.Errorf("%w: relation %q not defined in namespace %q", ErrInvalidTuple, relation, namespace)
+	}
+	return nil
+}
+
+// ValidateTuple checks that a tuple can be stored: its relation must be defined
+// and accept written subjects (This), and a userset subject must refer to a
+// defined relation.
+func (r *Registry) ValidateTuple(t RelationTuple) error {
+	if t.Object.Namespace == "" || t.Object.ID == "" || t.Subject.Namespace == "" || t.Subject.ID == "" {
+		return fmt.Errorf("%w: tuple %q is incomplete", ErrInvalidTuple, t.String())
+	}
+	if err := r.ValidateRelation(t.Object.Namespace, t.Relation); err != nil {
+		return err
+	}
+
+	direct := false
+	for _, rw := range r.GetNamespace(t.Object.Namespace).GetRewrites(t.Relation) {
+		if rw.Kind == RewriteThis {
+			direct = true
+		}
+	}
+	if !direct {
+		return fmt.Errorf("%w: relation %q of namespace %q is computed and cannot be written", ErrInvalidTuple, t.Relation, t.Object.Namespace)
+	}
+
+	if t.Subject.IsSet() {
+		return r.ValidateRelation(t.Subject.Namespace, t.Subject.Relation)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package rolekit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTuple tests parsing relation tuples from their string form
+func TestParseTuple(t *testing.T) {
+	t.Run("Userset subject", func(t *testing.T) {
+		tuple, err := ParseTuple("doc:readme#viewer@group:eng#member")
+		require.NoError(t, err)
+		assert.Equal(t, NewObjectRef("doc", "readme"), tuple.Object)
+		assert.Equal(t, "viewer", tuple.Relation)
+		assert.Equal(t, NewSubjectSet("group", "eng", "member"), tuple.Subject)
+		assert.True(t, tuple.Subject.IsSet())
+		assert.Equal(t, "doc:readme#viewer@group:eng#member", tuple.String())
+	})
+
+	t.Run("Single subject", func(t *testing.T) {
+		tuple, err := ParseTuple("group:eng#member@user:alice")
+		require.NoError(t, err)
+		assert.Equal(t, NewSubject("user", "alice"), tuple.Subject)
+		assert.False(t, tuple.Subject.IsSet())
+		assert.Equal(t, "group:eng#member@user:alice", tuple.String())
+	})
+
+	for _, s := range []string{
+		"",
+		"doc:readme#viewer",
+		"doc:readme@user:alice",
+		"doc:readme#@user:alice",
+		"readme#viewer@user:alice",
+		"doc:#viewer@user:alice",
+		"doc:readme#viewer@alice",
+		"doc:readme#viewer@group:eng#",
+	} {
+		_, err := ParseTuple(s)
+		assert.True(t, IsInvalidTuple(err), "expected %q to be rejected", s)
+	}
+
+	assert.Panics(t, func() { MustParseTuple("doc:readme") })
+}
+
+// TestRegistryDefineNamespace tests declaring namespaces and their rewrites
+func TestRegistryDefineNamespace(t *testing.T) {
+	r := NewRegistry()
+	r.DefineNamespace("group").
+		Relation("member").
+		DefineNamespace("doc").
+		Relation("parent").
+		Relation("viewer", This(), ComputedUserset("editor"), TupleToUserset("parent", "viewer")).
+		Relation("editor", ComputedUserset("owner")).
+		Relation("owner")
+
+	doc := r.GetNamespace("doc")
+	require.NotNil(t, doc)
+	assert.Equal(t, "doc", doc.Name())
+	assert.Equal(t, []UsersetRewrite{This()}, doc.GetRewrites("owner"))
+	assert.Equal(t, []UsersetRewrite{
+		{Kind: RewriteThis},
+		{Kind: RewriteComputedUserset, Relation: "editor"},
+		{Kind: RewriteTupleToUserset, Relation: "viewer", TuplesetRelation: "parent"},
+	}, doc.GetRewrites("viewer"))
+	assert.Nil(t, doc.GetRewrites("unknown"))
+	assert.Nil(t, r.GetNamespace("folder"))
+
+	assert.NoError(t, r.ValidateTuple(MustParseTuple("doc:readme#viewer@group:eng#member")))
+	assert.NoError(t, r.ValidateTuple(MustParseTuple("doc:readme#owner@user:alice")))
+
+	for _, s := range []string{
+		"folder:root#viewer@user:alice",       // Undefined namespace
+		"doc:readme#commenter@user:alice",     // Undefined relation
+		"doc:readme#editor@user:alice",        // Computed relation
+		"doc:readme#viewer@group:eng#manager", // Undefined subject relation
+	} {
+		assert.True(t, IsInvalidTuple(r.ValidateTuple(MustParseTuple(s))), "expected %q to be rejected", s)
+	}
+}